@@ -0,0 +1,360 @@
+package anonymization
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"sort"
+
+	h3utils "github.com/politic-in/core/h3-utils"
+)
+
+// ErrMixedResolutions is returned when hexagon counts are not all at one resolution
+var ErrMixedResolutions = errors.New("hexagons at mixed resolutions")
+
+// HexagonMergeConfig configures MergeHexagons
+type HexagonMergeConfig struct {
+	// KAnonymityThreshold is the respondents every published region needs
+	KAnonymityThreshold int
+
+	// CoarsestResolution is the largest parent sparse hexagons are merged
+	// into before falling back to neighbouring regions
+	CoarsestResolution int
+}
+
+// DefaultHexagonMergeConfig merges up to state-sized hexagons for the
+// standard threshold, so sparse hexagons are only suppressed when they have
+// no other respondents within a few tens of kilometres
+func DefaultHexagonMergeConfig() HexagonMergeConfig {
+	return HexagonMergeConfig{
+		KAnonymityThreshold: KAnonymityThreshold,
+		CoarsestResolution:  h3utils.StateResolution,
+	}
+}
+
+// HexagonRegion is a group of hexagons whose results are published together
+type HexagonRegion struct {
+	// ID names the region: its only hexagon, the parent its hexagons were
+	// merged into, or the region that absorbed it as a neighbour
+	ID    string   `json:"id"`
+	Cells []string `json:"cells"` // Sorted source hexagons
+	Count int      `json:"count"`
+}
+
+// HexagonRegions is the outcome of merging sparse hexagons
+type HexagonRegions struct {
+	Regions    []HexagonRegion   `json:"regions"`    // Sorted by ID, each meeting the threshold
+	RegionOf   map[string]string `json:"region_of"`  // Source hexagon to region ID
+	Suppressed []string          `json:"suppressed"` // Hexagons that could not reach the threshold
+}
+
+// MergeHexagons groups per-hexagon respondent counts into regions that each
+// meet the k-anonymity threshold, keeping hexagons that meet it on their own.
+// Sparse hexagons are first merged with their sparse siblings into their
+// parent, one resolution at a time up to CoarsestResolution. Regions still
+// sparse then join the neighbouring region with the fewest respondents,
+// smallest first, until they meet the threshold. Neighbours are looked for
+// among adjacent hexagons first, then among adjacent parents one resolution
+// at a time up to the region's own. A sparse region with no neighbours left
+// is suppressed. Hexagons with no respondents are ignored.
+func MergeHexagons(counts map[string]int, config HexagonMergeConfig) (*HexagonRegions, error) {
+	ids := make([]string, 0, len(counts))
+	for id, count := range counts {
+		if count > 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	resolution := -1
+	for _, id := range ids {
+		res, err := h3utils.GetResolution(id)
+		if err != nil {
+			return nil, err
+		}
+		if resolution >= 0 && res != resolution {
+			return nil, fmt.Errorf("%w: %d and %d", ErrMixedResolutions, resolution, res)
+		}
+		resolution = res
+	}
+
+	m := &hexagonMerger{
+		threshold:  config.KAnonymityThreshold,
+		resolution: resolution,
+		regions:    make(map[string]*HexagonRegion, len(ids)),
+		regionOf:   make(map[string]string, len(ids)),
+	}
+	for _, id := range ids {
+		m.regions[id] = &HexagonRegion{ID: id, Cells: []string{id}, Count: counts[id]}
+		m.regionOf[id] = id
+	}
+
+	for res := resolution - 1; res >= config.CoarsestResolution && res >= h3utils.MinResolution; res-- {
+		if !m.mergeIntoParents(res) {
+			break
+		}
+	}
+	suppressed := m.mergeIntoNeighbours()
+
+	result := &HexagonRegions{RegionOf: m.regionOf, Suppressed: suppressed}
+	for _, region := range m.regions {
+		sort.Strings(region.Cells)
+		result.Regions = append(result.Regions, *region)
+	}
+	sort.Slice(result.Regions, func(i, j int) bool { return result.Regions[i].ID < result.Regions[j].ID })
+	return result, nil
+}
+
+// MergeHexagons groups per-hexagon respondent counts into regions meeting the
+// aggregator's k-anonymity threshold, with the default merge configuration
+func (a *Aggregator) MergeHexagons(counts map[string]int) (*HexagonRegions, error) {
+	config := DefaultHexagonMergeConfig()
+	config.KAnonymityThreshold = a.config.KAnonymityThreshold
+	return MergeHexagons(counts, config)
+}
+
+// CountResponsesByHexagon counts responses per hexagon, as input to MergeHexagons
+func CountResponsesByHexagon(responses []AnonymizedResponse) map[string]int {
+	counts := make(map[string]int)
+	for _, resp := range responses {
+		counts[resp.HexagonID]++
+	}
+	return counts
+}
+
+// AggregateRegions aggregates responses per merged region, labelled with the
+// region ID. Responses from suppressed hexagons are left out.
+func (a *Aggregator) AggregateRegions(responses []AnonymizedResponse, pollID string, regions *HexagonRegions) ([]*AggregatedResult, error) {
+	byRegion := make(map[string][]AnonymizedResponse)
+	for _, resp := range responses {
+		if id, ok := regions.RegionOf[resp.HexagonID]; ok {
+			byRegion[id] = append(byRegion[id], resp)
+		}
+	}
+
+	results := make([]*AggregatedResult, 0, len(regions.Regions))
+	for _, region := range regions.Regions {
+		id := region.ID
+		result, err := a.AggregateResponses(byRegion[id], pollID, &id, nil)
+		if err != nil {
+			return nil, fmt.Errorf("region %s: %w", id, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// hexagonMerger holds the regions while MergeHexagons runs
+type hexagonMerger struct {
+	threshold  int
+	resolution int // Resolution of the source hexagons
+	regions    map[string]*HexagonRegion
+	regionOf   map[string]string
+	under      map[string][]string // Source hexagons under each of their ancestors, for neighbour lookups
+}
+
+// sparse returns the regions below the threshold, smallest first
+func (m *hexagonMerger) sparse() []*HexagonRegion {
+	var sparse []*HexagonRegion
+	for _, region := range m.regions {
+		if region.Count < m.threshold {
+			sparse = append(sparse, region)
+		}
+	}
+	sort.Slice(sparse, func(i, j int) bool {
+		if sparse[i].Count != sparse[j].Count {
+			return sparse[i].Count < sparse[j].Count
+		}
+		return sparse[i].ID < sparse[j].ID
+	})
+	return sparse
+}
+
+// mergeIntoParents merges the sparse regions under each parent at a
+// resolution into one region named by the parent. It reports whether any
+// sparse region is left.
+func (m *hexagonMerger) mergeIntoParents(resolution int) bool {
+	sparse := m.sparse()
+	byParent := make(map[string][]*HexagonRegion)
+	for _, region := range sparse {
+		// Every hexagon of a region merged so far shares its parents
+		parent, err := h3utils.GetParent(region.Cells[0], resolution)
+		if err != nil {
+			continue
+		}
+		byParent[parent] = append(byParent[parent], region)
+	}
+
+	for parent, group := range byParent {
+		merged := &HexagonRegion{ID: parent}
+		for _, region := range group {
+			merged.Cells = append(merged.Cells, region.Cells...)
+			merged.Count += region.Count
+			delete(m.regions, region.ID)
+		}
+		for _, cell := range merged.Cells {
+			m.regionOf[cell] = parent
+		}
+		m.regions[parent] = merged
+	}
+
+	return len(m.sparse()) > 0
+}
+
+// mergeIntoNeighbours merges each sparse region, smallest first, into the
+// nearest neighbouring region with the fewest respondents. It returns the
+// hexagons of sparse regions with no neighbours, which are removed.
+func (m *hexagonMerger) mergeIntoNeighbours() []string {
+	sparse := m.sparse()
+	if len(sparse) == 0 {
+		return nil
+	}
+	m.indexAncestors()
+
+	queue := make(regionQueue, 0, len(sparse))
+	for _, region := range sparse {
+		queue = append(queue, regionQueueEntry{id: region.ID, count: region.Count})
+	}
+	heap.Init(&queue)
+
+	var suppressed []string
+	for queue.Len() > 0 {
+		entry := heap.Pop(&queue).(regionQueueEntry)
+		region, ok := m.regions[entry.id]
+		if !ok || region.Count != entry.count {
+			continue // Merged away or grown since it was queued
+		}
+
+		var target *HexagonRegion
+		for _, neighbour := range m.nearestNeighbours(region) {
+			if target == nil || neighbour.Count < target.Count ||
+				(neighbour.Count == target.Count && neighbour.ID < target.ID) {
+				target = neighbour
+			}
+		}
+
+		delete(m.regions, region.ID)
+		if target == nil {
+			for _, cell := range region.Cells {
+				delete(m.regionOf, cell)
+			}
+			suppressed = append(suppressed, region.Cells...)
+			continue
+		}
+		target.Cells = append(target.Cells, region.Cells...)
+		target.Count += region.Count
+		for _, cell := range region.Cells {
+			m.regionOf[cell] = target.ID
+		}
+		if target.Count < m.threshold {
+			heap.Push(&queue, regionQueueEntry{id: target.ID, count: target.Count})
+		}
+	}
+
+	sort.Strings(suppressed)
+	return suppressed
+}
+
+// indexAncestors records the source hexagons under each of their ancestors,
+// up to the coarsest region
+func (m *hexagonMerger) indexAncestors() {
+	coarsest := m.resolution
+	for id := range m.regions {
+		if res, err := h3utils.GetResolution(id); err == nil && res < coarsest {
+			coarsest = res
+		}
+	}
+
+	m.under = make(map[string][]string)
+	for cell := range m.regionOf {
+		m.under[cell] = append(m.under[cell], cell)
+		for res := m.resolution - 1; res >= coarsest; res-- {
+			if parent, err := h3utils.GetParent(cell, res); err == nil {
+				m.under[parent] = append(m.under[parent], cell)
+			}
+		}
+	}
+}
+
+// nearestNeighbours returns the other regions adjacent to a region at the
+// finest resolution where it has any: regions with a hexagon next to one of
+// its own, else regions with a hexagon under the same or an adjacent parent
+// as one of its own, one resolution at a time up to the region's own
+func (m *hexagonMerger) nearestNeighbours(region *HexagonRegion) []*HexagonRegion {
+	own, err := h3utils.GetResolution(region.ID)
+	if err != nil {
+		return nil
+	}
+	for res := m.resolution; res >= own; res-- {
+		if neighbours := m.neighboursAt(region, res); len(neighbours) > 0 {
+			return neighbours
+		}
+	}
+	return nil
+}
+
+// neighboursAt returns the other regions with a hexagon whose ancestor at a
+// resolution is, or is adjacent to, the ancestor of one of the region's hexagons
+func (m *hexagonMerger) neighboursAt(region *HexagonRegion, resolution int) []*HexagonRegion {
+	areas := make(map[string]bool)
+	for _, cell := range region.Cells {
+		ancestor := cell
+		if resolution < m.resolution {
+			var err error
+			if ancestor, err = h3utils.GetParent(cell, resolution); err != nil {
+				continue
+			}
+		}
+		if areas[ancestor] {
+			continue
+		}
+		areas[ancestor] = true
+		adjacent, err := h3utils.GetNeighbors(ancestor)
+		if err != nil {
+			continue
+		}
+		for _, next := range adjacent {
+			areas[next] = true
+		}
+	}
+
+	seen := map[string]bool{region.ID: true}
+	var neighbours []*HexagonRegion
+	for area := range areas {
+		for _, cell := range m.under[area] {
+			id, ok := m.regionOf[cell]
+			if !ok || seen[id] {
+				continue
+			}
+			seen[id] = true
+			neighbours = append(neighbours, m.regions[id])
+		}
+	}
+	return neighbours
+}
+
+// regionQueueEntry is a sparse region waiting in a regionQueue, with its
+// count when queued
+type regionQueueEntry struct {
+	id    string
+	count int
+}
+
+// regionQueue is a min-heap of sparse regions, smallest first and then by ID
+type regionQueue []regionQueueEntry
+
+func (q regionQueue) Len() int { return len(q) }
+func (q regionQueue) Less(i, j int) bool {
+	if q[i].count != q[j].count {
+		return q[i].count < q[j].count
+	}
+	return q[i].id < q[j].id
+}
+func (q regionQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *regionQueue) Push(x any)   { *q = append(*q, x.(regionQueueEntry)) }
+func (q *regionQueue) Pop() any {
+	old := *q
+	entry := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return entry
+}
@@ -0,0 +1,181 @@
+package anonymization
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	h3utils "github.com/politic-in/core/h3-utils"
+)
+
+// testBorderPair returns two adjacent resolution 9 hexagons near Delhi with
+// different AC-level parents
+func testBorderPair(t *testing.T) (a, b string) {
+	t.Helper()
+	centre := h3utils.LatLngToCellAtResolution(28.6139, 77.2090, 9)
+	disk, _ := h3utils.GetCellsInRadius(centre, 30)
+	for _, cell := range disk {
+		parent, _ := h3utils.GetParent(cell, h3utils.ACResolution)
+		neighbours, _ := h3utils.GetNeighbors(cell)
+		for _, n := range neighbours {
+			if p, _ := h3utils.GetParent(n, h3utils.ACResolution); p != parent {
+				return cell, n
+			}
+		}
+	}
+	t.Fatal("no hexagons on an AC-level border")
+	return "", ""
+}
+
+func TestMergeHexagons(t *testing.T) {
+	dense, sparseNeighbour := testBorderPair(t)
+
+	// Sparse siblings elsewhere that meet the threshold together in their parent
+	siblingParent, _ := h3utils.GetParent(h3utils.LatLngToCellAtResolution(28.70, 77.10, 9), 8)
+	siblings, _ := h3utils.GetChildren(siblingParent, 9)
+
+	// A lone respondent far away, in Chennai
+	isolated := h3utils.LatLngToCellAtResolution(13.0827, 80.2707, 9)
+
+	counts := map[string]int{dense: 12, sparseNeighbour: 3, isolated: 2}
+	for i, cell := range siblings[:4] {
+		counts[cell] = 2 + i%2
+	}
+	counts[siblings[4]] = 0
+
+	regions, err := NewAggregator().MergeHexagons(counts)
+	if err != nil {
+		t.Fatalf("MergeHexagons() error: %v", err)
+	}
+
+	want := []HexagonRegion{
+		{ID: siblingParent, Cells: h3utils.SortCells(siblings[:4]), Count: 10},
+		{ID: dense, Cells: h3utils.SortCells([]string{dense, sparseNeighbour}), Count: 15},
+	}
+	if want[0].ID > want[1].ID {
+		want[0], want[1] = want[1], want[0]
+	}
+	if !reflect.DeepEqual(regions.Regions, want) {
+		t.Errorf("Regions = %+v, want %+v", regions.Regions, want)
+	}
+
+	if got := regions.RegionOf[sparseNeighbour]; got != dense {
+		t.Errorf("RegionOf[sparse neighbour] = %s, want the dense hexagon %s", got, dense)
+	}
+	if _, ok := regions.RegionOf[siblings[4]]; ok {
+		t.Error("a hexagon with no respondents was given a region")
+	}
+	if !reflect.DeepEqual(regions.Suppressed, []string{isolated}) {
+		t.Errorf("Suppressed = %v, want [%s]", regions.Suppressed, isolated)
+	}
+}
+
+func TestMergeHexagons_AllDense(t *testing.T) {
+	dense, other := testBorderPair(t)
+	regions, err := MergeHexagons(map[string]int{dense: 10, other: 25}, DefaultHexagonMergeConfig())
+	if err != nil {
+		t.Fatalf("MergeHexagons() error: %v", err)
+	}
+	if len(regions.Regions) != 2 || len(regions.Suppressed) != 0 {
+		t.Errorf("MergeHexagons() = %+v, want both hexagons kept", regions)
+	}
+}
+
+func TestMergeHexagons_AdjacentParents(t *testing.T) {
+	// Two sparse hexagons at the centres of adjacent AC-level cells: not
+	// adjacent themselves, but their merged parents are
+	parent := h3utils.LatLngToCellAtResolution(28.6139, 77.2090, h3utils.ACResolution)
+	neighbours, _ := h3utils.GetNeighbors(parent)
+	centre := func(cell string) string {
+		lat, lng, _ := h3utils.CellToLatLng(cell)
+		return h3utils.LatLngToCellAtResolution(lat, lng, 9)
+	}
+	a, b := centre(parent), centre(neighbours[0])
+
+	config := DefaultHexagonMergeConfig()
+	config.CoarsestResolution = h3utils.ACResolution
+	regions, err := MergeHexagons(map[string]int{a: 5, b: 5}, config)
+	if err != nil {
+		t.Fatalf("MergeHexagons() error: %v", err)
+	}
+	if len(regions.Regions) != 1 || regions.Regions[0].Count != 10 || len(regions.Suppressed) != 0 {
+		t.Errorf("MergeHexagons() = %+v, want both hexagons in one region", regions)
+	}
+}
+
+func TestMergeHexagons_ManySparseHexagons(t *testing.T) {
+	// Tens of thousands of sparse hexagons across a city all get published
+	centre := h3utils.LatLngToCellAtResolution(12.9716, 77.5946, 9)
+	disk, _ := h3utils.GetCellsInRadius(centre, 120)
+	counts := make(map[string]int, len(disk))
+	total := 0
+	for i, cell := range disk {
+		counts[cell] = 1 + i%3
+		total += counts[cell]
+	}
+
+	regions, err := MergeHexagons(counts, DefaultHexagonMergeConfig())
+	if err != nil {
+		t.Fatalf("MergeHexagons() error: %v", err)
+	}
+	published := 0
+	for _, region := range regions.Regions {
+		if region.Count < KAnonymityThreshold {
+			t.Fatalf("region %s has %d respondents", region.ID, region.Count)
+		}
+		published += region.Count
+	}
+	if published != total || len(regions.Suppressed) != 0 {
+		t.Errorf("published %d of %d respondents, suppressed %d hexagons", published, total, len(regions.Suppressed))
+	}
+}
+
+func TestMergeHexagons_Errors(t *testing.T) {
+	cell := h3utils.LatLngToCellAtResolution(28.6139, 77.2090, 9)
+	parent, _ := h3utils.GetParent(cell, 8)
+
+	if _, err := MergeHexagons(map[string]int{cell: 5, parent: 5}, DefaultHexagonMergeConfig()); !errors.Is(err, ErrMixedResolutions) {
+		t.Errorf("mixed resolutions error = %v, want ErrMixedResolutions", err)
+	}
+	if _, err := MergeHexagons(map[string]int{"invalid": 5}, DefaultHexagonMergeConfig()); !errors.Is(err, h3utils.ErrInvalidCellID) {
+		t.Errorf("invalid hexagon error = %v, want ErrInvalidCellID", err)
+	}
+}
+
+func TestAggregator_AggregateRegions(t *testing.T) {
+	dense, sparseNeighbour := testBorderPair(t)
+
+	var responses []AnonymizedResponse
+	for i := range 14 {
+		hexagon := dense
+		if i >= 11 {
+			hexagon = sparseNeighbour
+		}
+		responses = append(responses, AnonymizedResponse{
+			ID:        fmt.Sprintf("resp-%d", i),
+			PollID:    "poll-1",
+			HexagonID: hexagon,
+			Answers:   map[string]interface{}{"q1": "yes"},
+		})
+	}
+
+	aggregator := NewAggregatorWithConfig(AggregationConfig{KAnonymityThreshold: 10, MinAggregationSize: 10})
+	regions, err := aggregator.MergeHexagons(CountResponsesByHexagon(responses))
+	if err != nil {
+		t.Fatalf("MergeHexagons() error: %v", err)
+	}
+	results, err := aggregator.AggregateRegions(responses, "poll-1", regions)
+	if err != nil {
+		t.Fatalf("AggregateRegions() error: %v", err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	result := results[0]
+	if *result.HexagonID != dense || result.ResponseCount != 14 || !result.MeetsKAnon {
+		t.Errorf("result = %s with %d responses, meets k-anonymity %v; want %s with 14",
+			*result.HexagonID, result.ResponseCount, result.MeetsKAnon, dense)
+	}
+}
@@ -0,0 +1,393 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	h3utils "github.com/politic-in/core/h3-utils"
+)
+
+// ErrNoACPath is returned when two ACs are not connected by shared borders
+var ErrNoACPath = errors.New("no path between ACs")
+
+// adjacencyCellDegrees is the size of the grid used to find nearby borders
+const adjacencyCellDegrees = 0.01
+
+// ACNode identifies an AC across states
+type ACNode struct {
+	StateSlug string
+	ConsCode  int
+}
+
+// String returns the node as "state_slug:cons_code"
+func (n ACNode) String() string {
+	return fmt.Sprintf("%s:%d", n.StateSlug, n.ConsCode)
+}
+
+// acNodeLess orders nodes by state, then constituency code
+func acNodeLess(a, b ACNode) bool {
+	if a.StateSlug != b.StateSlug {
+		return a.StateSlug < b.StateSlug
+	}
+	return a.ConsCode < b.ConsCode
+}
+
+// ACNeighbour is an AC sharing a border with another
+type ACNeighbour struct {
+	ACNode
+	SharedBorderKm float64 // Approximate length of the shared border
+}
+
+// ACGraphConfig configures how AC adjacency is derived from boundaries
+type ACGraphConfig struct {
+	// ToleranceM is how far apart, in metres, two borders may run and still
+	// count as shared. Boundaries of neighbouring states come from separate
+	// files whose vertices rarely coincide.
+	ToleranceM float64
+
+	// MinSharedBorderM is the shortest shared border, in metres, that makes
+	// two ACs neighbours, so ACs meeting only at a corner are not
+	MinSharedBorderM float64
+}
+
+// DefaultACGraphConfig returns the default adjacency configuration
+func DefaultACGraphConfig() ACGraphConfig {
+	return ACGraphConfig{
+		ToleranceM:       100,
+		MinSharedBorderM: 100,
+	}
+}
+
+// ACGraph is the adjacency graph of ACs, with an edge between every two
+// ACs that share a border, within a state or across state lines
+type ACGraph struct {
+	config     ACGraphConfig
+	nodes      []ACNode                 // Sorted
+	neighbours map[ACNode][]ACNeighbour // Sorted by node
+}
+
+// BuildACGraph derives the adjacency graph of a set of AC boundaries, keyed
+// by state slug. Two ACs are neighbours when stretches of their borders run
+// within config.ToleranceM of each other for at least config.MinSharedBorderM.
+func BuildACGraph(boundariesByState map[string][]*ACBoundary, config ACGraphConfig) *ACGraph {
+	type ringRef struct {
+		node int
+		ring [][]float64
+	}
+	type segmentRef struct {
+		ring, index int32 // Segment from ring[index] to ring[index+1]
+	}
+
+	graph := &ACGraph{config: config, neighbours: make(map[ACNode][]ACNeighbour)}
+	nodeIndex := make(map[ACNode]int)
+	var rings []ringRef
+	for stateSlug, boundaries := range boundariesByState {
+		for _, boundary := range boundaries {
+			node := ACNode{StateSlug: stateSlug, ConsCode: boundary.ConsCode}
+			if _, ok := nodeIndex[node]; !ok {
+				nodeIndex[node] = len(graph.nodes)
+				graph.nodes = append(graph.nodes, node)
+			}
+			for _, polygon := range boundary.GetPolygons() {
+				for _, ring := range polygon {
+					if len(ring) > 1 {
+						rings = append(rings, ringRef{node: nodeIndex[node], ring: closedRing(ring)})
+					}
+				}
+			}
+		}
+	}
+
+	// Index every segment in the grid cells within tolerance of it
+	const metresPerDegree = 111320.0
+	toleranceLat := config.ToleranceM / metresPerDegree
+	grid := make(map[[2]int32][]segmentRef)
+	for r, ref := range rings {
+		for i := 1; i < len(ref.ring); i++ {
+			a, b := ref.ring[i-1], ref.ring[i]
+			toleranceLng := toleranceLat / math.Cos(math.Max(math.Abs(a[1]), math.Abs(b[1]))*math.Pi/180)
+			minCell := adjacencyCell(math.Min(a[0], b[0])-toleranceLng, math.Min(a[1], b[1])-toleranceLat)
+			maxCell := adjacencyCell(math.Max(a[0], b[0])+toleranceLng, math.Max(a[1], b[1])+toleranceLat)
+			for x := minCell[0]; x <= maxCell[0]; x++ {
+				for y := minCell[1]; y <= maxCell[1]; y++ {
+					cell := [2]int32{x, y}
+					grid[cell] = append(grid[cell], segmentRef{ring: int32(r), index: int32(i - 1)})
+				}
+			}
+		}
+	}
+
+	// For every vertex, find the other ACs with a border within tolerance.
+	// A segment whose ends are both within tolerance of an AC runs along it.
+	shared := make(map[[2]int]float64) // [from, to] node -> metres of from's border along to
+	var prevContacts, contacts []int
+	for _, ref := range rings {
+		prevContacts = prevContacts[:0]
+		for i, pt := range ref.ring {
+			contacts = contacts[:0]
+			for _, seg := range grid[adjacencyCell(pt[0], pt[1])] {
+				other := rings[seg.ring]
+				if other.node == ref.node || containsInt(contacts, other.node) {
+					continue
+				}
+				if segmentDistance(pt, other.ring[seg.index], other.ring[seg.index+1]) <= config.ToleranceM {
+					contacts = append(contacts, other.node)
+				}
+			}
+
+			if i > 0 {
+				var lengthM float64
+				for _, node := range contacts {
+					if !containsInt(prevContacts, node) {
+						continue
+					}
+					if lengthM == 0 {
+						prev := ref.ring[i-1]
+						lengthM = h3utils.HaversineDistance(prev[1], prev[0], pt[1], pt[0])
+					}
+					shared[[2]int{ref.node, node}] += lengthM
+				}
+			}
+			prevContacts, contacts = contacts, prevContacts
+		}
+	}
+
+	// Each side measures the border along its own vertices; take the longer
+	// so a side with sparse vertices near a gap does not drop the edge
+	lengths := make(map[[2]int]float64, len(shared)/2)
+	for pair, lengthM := range shared {
+		if pair[0] > pair[1] {
+			pair[0], pair[1] = pair[1], pair[0]
+		}
+		lengths[pair] = math.Max(lengths[pair], lengthM)
+	}
+	for pair, lengthM := range lengths {
+		if lengthM >= config.MinSharedBorderM {
+			graph.addEdge(graph.nodes[pair[0]], graph.nodes[pair[1]], lengthM/1000)
+		}
+	}
+
+	graph.sort()
+	return graph
+}
+
+// adjacencyCell returns the grid cell of a point
+func adjacencyCell(lng, lat float64) [2]int32 {
+	return [2]int32{int32(math.Floor(lng / adjacencyCellDegrees)), int32(math.Floor(lat / adjacencyCellDegrees))}
+}
+
+// closedRing returns a ring whose last point repeats its first
+func closedRing(ring [][]float64) [][]float64 {
+	if ringClosed(ring) {
+		return ring
+	}
+	closed := make([][]float64, len(ring), len(ring)+1)
+	copy(closed, ring)
+	return append(closed, ring[0])
+}
+
+// containsInt returns true if list contains v
+func containsInt(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// addEdge adds an undirected edge
+func (ag *ACGraph) addEdge(a, b ACNode, sharedBorderKm float64) {
+	ag.neighbours[a] = append(ag.neighbours[a], ACNeighbour{ACNode: b, SharedBorderKm: sharedBorderKm})
+	ag.neighbours[b] = append(ag.neighbours[b], ACNeighbour{ACNode: a, SharedBorderKm: sharedBorderKm})
+}
+
+// sort orders the nodes and every neighbour list
+func (ag *ACGraph) sort() {
+	sort.Slice(ag.nodes, func(i, j int) bool { return acNodeLess(ag.nodes[i], ag.nodes[j]) })
+	for _, neighbours := range ag.neighbours {
+		sort.Slice(neighbours, func(i, j int) bool { return acNodeLess(neighbours[i].ACNode, neighbours[j].ACNode) })
+	}
+}
+
+// Config returns the configuration the graph was built with
+func (ag *ACGraph) Config() ACGraphConfig {
+	return ag.config
+}
+
+// Nodes returns every AC in the graph, ordered by state and constituency code
+func (ag *ACGraph) Nodes() []ACNode {
+	return ag.nodes
+}
+
+// EdgeCount returns the number of pairs of neighbouring ACs
+func (ag *ACGraph) EdgeCount() int {
+	count := 0
+	for _, neighbours := range ag.neighbours {
+		count += len(neighbours)
+	}
+	return count / 2
+}
+
+// Has returns true if the AC is in the graph
+func (ag *ACGraph) Has(node ACNode) bool {
+	i := sort.Search(len(ag.nodes), func(i int) bool { return !acNodeLess(ag.nodes[i], node) })
+	return i < len(ag.nodes) && ag.nodes[i] == node
+}
+
+// Neighbours returns the ACs sharing a border with an AC
+func (ag *ACGraph) Neighbours(node ACNode) []ACNeighbour {
+	return ag.neighbours[node]
+}
+
+// ShortestPath returns the ACs on a path from one AC to another crossing the
+// fewest borders, both ends included
+func (ag *ACGraph) ShortestPath(from, to ACNode) ([]ACNode, error) {
+	for _, node := range []ACNode{from, to} {
+		if !ag.Has(node) {
+			return nil, fmt.Errorf("%w: %s", ErrBoundaryNotFound, node)
+		}
+	}
+
+	previous := map[ACNode]ACNode{from: from}
+	queue := []ACNode{from}
+	for len(queue) > 0 && queue[0] != to {
+		node := queue[0]
+		queue = queue[1:]
+		for _, neighbour := range ag.neighbours[node] {
+			if _, seen := previous[neighbour.ACNode]; !seen {
+				previous[neighbour.ACNode] = node
+				queue = append(queue, neighbour.ACNode)
+			}
+		}
+	}
+	if _, ok := previous[to]; !ok {
+		return nil, fmt.Errorf("%w: %s to %s", ErrNoACPath, from, to)
+	}
+
+	path := []ACNode{to}
+	for node := to; node != from; {
+		node = previous[node]
+		path = append(path, node)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, nil
+}
+
+// WithinHops returns the ACs at most hops borders away from an AC, nearest
+// first and excluding the AC itself, such as the rings to widen an audience by
+func (ag *ACGraph) WithinHops(node ACNode, hops int) []ACNode {
+	seen := map[ACNode]bool{node: true}
+	var result []ACNode
+	frontier := []ACNode{node}
+	for hop := 0; hop < hops && len(frontier) > 0; hop++ {
+		var next []ACNode
+		for _, current := range frontier {
+			for _, neighbour := range ag.neighbours[current] {
+				if !seen[neighbour.ACNode] {
+					seen[neighbour.ACNode] = true
+					next = append(next, neighbour.ACNode)
+				}
+			}
+		}
+		sort.Slice(next, func(i, j int) bool { return acNodeLess(next[i], next[j]) })
+		result = append(result, next...)
+		frontier = next
+	}
+	return result
+}
+
+// ACGraph returns the adjacency graph of every AC with a boundary file,
+// across all states. It loads every state's boundaries and is built on first
+// use, then kept until a reload; LoadAllWithSnapshot stores it in the snapshot.
+func (g *GeoIndex) ACGraph() (*ACGraph, error) {
+	g.mu.RLock()
+	graph, generation := g.acGraph, g.generation
+	g.mu.RUnlock()
+	if graph != nil {
+		return graph, nil
+	}
+
+	states, err := g.availableBoundaryStates()
+	if err != nil {
+		return nil, err
+	}
+	boundariesByState := make(map[string][]*ACBoundary, len(states))
+	for _, stateName := range states {
+		stateSlug := ToSlug(stateName)
+		boundaries, err := g.GetBoundariesForState(stateSlug)
+		if err != nil {
+			return nil, fmt.Errorf("loading boundaries for %s: %w", stateSlug, err)
+		}
+		boundariesByState[stateSlug] = boundaries
+	}
+
+	graph = BuildACGraph(boundariesByState, g.config.ACGraph)
+
+	// Keep the graph unless the index was reloaded in the meantime
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.acGraph != nil {
+		return g.acGraph, nil
+	}
+	if g.generation == generation {
+		g.acGraph = graph
+	}
+	return graph, nil
+}
+
+// GetNeighbouringACs returns the ACs sharing a border with an AC, in its own
+// state or a neighbouring one
+func (g *GeoIndex) GetNeighbouringACs(stateSlug string, consCode int) ([]ACNeighbour, error) {
+	graph, err := g.ACGraph()
+	if err != nil {
+		return nil, err
+	}
+
+	node := ACNode{StateSlug: stateSlug, ConsCode: consCode}
+	if !graph.Has(node) {
+		return nil, fmt.Errorf("%w: %s", ErrBoundaryNotFound, node)
+	}
+	return graph.Neighbours(node), nil
+}
+
+// ShortestACPath returns the ACs on a path between two ACs crossing the
+// fewest borders, both ends included
+func (g *GeoIndex) ShortestACPath(from, to ACNode) ([]ACNode, error) {
+	graph, err := g.ACGraph()
+	if err != nil {
+		return nil, err
+	}
+	return graph.ShortestPath(from, to)
+}
+
+// snapshot returns the graph as an edge list
+func (ag *ACGraph) snapshot() *snapshotACGraph {
+	data := &snapshotACGraph{Config: ag.config, Nodes: ag.nodes}
+	index := make(map[ACNode]int, len(ag.nodes))
+	for i, node := range ag.nodes {
+		index[node] = i
+	}
+	for i, node := range ag.nodes {
+		for _, neighbour := range ag.neighbours[node] {
+			if j := index[neighbour.ACNode]; i < j {
+				data.Edges = append(data.Edges, snapshotACEdge{From: i, To: j, SharedBorderKm: neighbour.SharedBorderKm})
+			}
+		}
+	}
+	return data
+}
+
+// graph restores a graph from its edge list
+func (data *snapshotACGraph) graph() *ACGraph {
+	graph := &ACGraph{config: data.Config, nodes: data.Nodes, neighbours: make(map[ACNode][]ACNeighbour)}
+	for _, edge := range data.Edges {
+		graph.addEdge(data.Nodes[edge.From], data.Nodes[edge.To], edge.SharedBorderKm)
+	}
+	graph.sort()
+	return graph
+}
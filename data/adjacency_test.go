@@ -0,0 +1,137 @@
+package data
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newAdjacencyTestIndex returns the test index with a second state: Delta
+// runs along Gamma's eastern border about 30 m away, as borders from
+// separate state files do, and Epsilon touches Alpha only at a corner
+func newAdjacencyTestIndex(t *testing.T) *GeoIndex {
+	t.Helper()
+	files := testDataFiles()
+	files[filepath.Join(BoundariesDir, "otherland.geojson")] = `{"type": "FeatureCollection", "state_name": "Otherland", "features": [` +
+		testSquareFeature(1, "Delta", 80.0003, 12) + `, ` +
+		testSquareFeature(2, "Epsilon", 76, 13) + `]}`
+
+	index := NewGeoIndex(writeTestData(t, files))
+	if err := index.LoadAll(); err != nil {
+		t.Fatalf("LoadAll() error: %v", err)
+	}
+	return index
+}
+
+func TestGetNeighbouringACs(t *testing.T) {
+	index := newAdjacencyTestIndex(t)
+
+	tests := []struct {
+		state    string
+		consCode int
+		want     []ACNode
+	}{
+		{"testland", 1, []ACNode{{"testland", 2}}},
+		{"testland", 2, []ACNode{{"testland", 1}, {"testland", 3}}},
+		{"testland", 3, []ACNode{{"otherland", 1}, {"testland", 2}}},
+		{"otherland", 1, []ACNode{{"testland", 3}}},
+		{"otherland", 2, nil},
+	}
+	for _, tt := range tests {
+		neighbours, err := index.GetNeighbouringACs(tt.state, tt.consCode)
+		if err != nil {
+			t.Fatalf("GetNeighbouringACs(%s, %d) error: %v", tt.state, tt.consCode, err)
+		}
+		var got []ACNode
+		for _, neighbour := range neighbours {
+			got = append(got, neighbour.ACNode)
+			if math.Abs(neighbour.SharedBorderKm-111.2) > 0.5 {
+				t.Errorf("%s:%d - %s SharedBorderKm = %.2f, want about 111.2", tt.state, tt.consCode, neighbour.ACNode, neighbour.SharedBorderKm)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetNeighbouringACs(%s, %d) = %v, want %v", tt.state, tt.consCode, got, tt.want)
+		}
+	}
+
+	if _, err := index.GetNeighbouringACs("testland", 99); !errors.Is(err, ErrBoundaryNotFound) {
+		t.Errorf("GetNeighbouringACs() for unknown AC error = %v, want ErrBoundaryNotFound", err)
+	}
+}
+
+func TestShortestACPath(t *testing.T) {
+	index := newAdjacencyTestIndex(t)
+	alpha := ACNode{"testland", 1}
+
+	path, err := index.ShortestACPath(alpha, ACNode{"otherland", 1})
+	if err != nil {
+		t.Fatalf("ShortestACPath() error: %v", err)
+	}
+	want := []ACNode{alpha, {"testland", 2}, {"testland", 3}, {"otherland", 1}}
+	if !reflect.DeepEqual(path, want) {
+		t.Errorf("ShortestACPath() = %v, want %v", path, want)
+	}
+
+	if path, err := index.ShortestACPath(alpha, alpha); err != nil || len(path) != 1 {
+		t.Errorf("ShortestACPath() to itself = %v, %v, want just the AC", path, err)
+	}
+	if _, err := index.ShortestACPath(alpha, ACNode{"otherland", 2}); !errors.Is(err, ErrNoACPath) {
+		t.Errorf("ShortestACPath() to a corner neighbour error = %v, want ErrNoACPath", err)
+	}
+	if _, err := index.ShortestACPath(alpha, ACNode{"nowhere", 1}); !errors.Is(err, ErrBoundaryNotFound) {
+		t.Errorf("ShortestACPath() to unknown AC error = %v, want ErrBoundaryNotFound", err)
+	}
+
+	graph, err := index.ACGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := graph.WithinHops(alpha, 2), []ACNode{{"testland", 2}, {"testland", 3}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("WithinHops(alpha, 2) = %v, want %v", got, want)
+	}
+	if got := graph.EdgeCount(); got != 3 {
+		t.Errorf("EdgeCount() = %d, want 3", got)
+	}
+}
+
+func TestACGraphSnapshot(t *testing.T) {
+	dataDir := writeTestData(t, testDataFiles())
+	path := filepath.Join(t.TempDir(), "geoindex.snapshot")
+
+	built := NewGeoIndex(dataDir)
+	if err := built.LoadAllWithSnapshot(path); err != nil {
+		t.Fatalf("LoadAllWithSnapshot() error: %v", err)
+	}
+
+	loaded := NewGeoIndex(dataDir)
+	if err := loaded.LoadSnapshot(path); err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	if loaded.acGraph == nil {
+		t.Fatal("LoadSnapshot() did not restore the AC graph")
+	}
+	want, _ := built.ACGraph()
+	if got := loaded.acGraph; !reflect.DeepEqual(got, want) {
+		t.Errorf("restored graph = %+v, want %+v", got, want)
+	}
+
+	// A graph built with other settings is rebuilt instead
+	config := DefaultGeoIndexConfig()
+	config.ACGraph.ToleranceM = 10
+	other := NewGeoIndexWithConfig(dataDir, config)
+	if err := other.LoadSnapshot(path); err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	if other.acGraph != nil {
+		t.Error("LoadSnapshot() restored a graph built with another configuration")
+	}
+
+	if err := loaded.ReloadState("testland"); err != nil {
+		t.Fatalf("ReloadState() error: %v", err)
+	}
+	if loaded.acGraph != nil {
+		t.Error("ReloadState() kept the AC graph")
+	}
+}
@@ -6,7 +6,8 @@ import (
 	boothmatching "github.com/politic-in/core/booth-matching"
 )
 
-// BoothMatcherForAC creates a booth matcher for a specific AC using indexed data
+// BoothMatcherForAC creates a booth matcher for a specific AC using indexed data.
+// The matcher copies the booths, so rebuild it when the index reloads (see OnChange).
 func (g *GeoIndex) BoothMatcherForAC(stateSlug string, acNumber int) (*boothmatching.Matcher, error) {
 	booths, err := g.GetBoothsForAC(stateSlug, acNumber)
 	if err != nil {
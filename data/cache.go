@@ -0,0 +1,223 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Estimated resident sizes used for the memory budget
+const (
+	boothOverheadBytes    = 320 // Booth struct, coordinates and index entries
+	boundaryOverheadBytes = 256 // Boundary struct, spatial index entry and map entries
+	pointBytes            = 40  // One [lng, lat] point: slice header plus two float64s
+)
+
+// Kinds of per-state data tracked against the memory budget
+const (
+	residentBooths     = "booths"
+	residentBoundaries = "boundaries"
+	residentDistricts  = "districts"
+)
+
+// maxLoadAttempts is how many times a read loads data that concurrent loads
+// keep evicting before giving up
+const maxLoadAttempts = 8
+
+// ErrEvictedWhileReading is returned when a state's data is evicted each time
+// it is loaded, because the memory budget is too small for the data in use
+var ErrEvictedWhileReading = errors.New("state data evicted before it could be read")
+
+// residentEntry is a state's booths, boundaries or district boundaries in the LRU list
+type residentEntry struct {
+	kind      string
+	stateSlug string
+	bytes     int64
+}
+
+// readBooths runs fn under the read lock with the state's booths loaded.
+// If they are evicted between loading and locking they are loaded again.
+func (g *GeoIndex) readBooths(stateSlug string, fn func()) error {
+	return g.readResident(residentBooths, stateSlug, g.LoadBoothsForState, func() bool { return g.loadedStates[stateSlug] }, fn)
+}
+
+// readBoundaries runs fn under the read lock with the state's boundaries loaded.
+// If they are evicted between loading and locking they are loaded again.
+func (g *GeoIndex) readBoundaries(stateSlug string, fn func()) error {
+	return g.readResident(residentBoundaries, stateSlug, g.LoadBoundariesForState, func() bool { return g.loadedBounds[stateSlug] }, fn)
+}
+
+// readDistrictBoundaries runs fn under the read lock with the state's
+// district boundaries loaded, loading them again if they are evicted between
+// loading and locking
+func (g *GeoIndex) readDistrictBoundaries(stateSlug string, fn func()) error {
+	return g.readResident(residentDistricts, stateSlug, g.LoadDistrictBoundariesForState, func() bool { return g.loadedDistricts[stateSlug] }, fn)
+}
+
+// readResident loads a state's data and runs fn under the read lock while it
+// is still loaded, retrying up to maxLoadAttempts times. loaded reports
+// whether the data is loaded and is called under the lock.
+func (g *GeoIndex) readResident(kind, stateSlug string, load func(string) error, loaded func() bool, fn func()) error {
+	for attempt := 0; attempt < maxLoadAttempts; attempt++ {
+		if err := load(stateSlug); err != nil {
+			return err
+		}
+		g.mu.RLock()
+		if loaded() {
+			fn()
+			g.mu.RUnlock()
+			return nil
+		}
+		g.mu.RUnlock()
+	}
+	return fmt.Errorf("%w: %s %s after %d attempts", ErrEvictedWhileReading, stateSlug, kind, maxLoadAttempts)
+}
+
+// touchResidentLocked records a cache hit and marks the data as recently used (must hold lock)
+func (g *GeoIndex) touchResidentLocked(kind, stateSlug string) {
+	g.cacheHits++
+	if elem, ok := g.residentByKey[residentKey(kind, stateSlug)]; ok {
+		g.residentLRU.MoveToFront(elem)
+	}
+}
+
+// trackResidentLocked adds freshly indexed data to the LRU list (must hold lock)
+func (g *GeoIndex) trackResidentLocked(kind, stateSlug string, bytes int64) {
+	key := residentKey(kind, stateSlug)
+	if elem, ok := g.residentByKey[key]; ok {
+		g.residentBytes -= elem.Value.(*residentEntry).bytes
+		g.residentLRU.Remove(elem)
+	}
+	g.residentByKey[key] = g.residentLRU.PushFront(&residentEntry{kind: kind, stateSlug: stateSlug, bytes: bytes})
+	g.residentBytes += bytes
+}
+
+// growResidentLocked adds data derived from a resident entry, such as
+// simplified boundaries, to its size (must hold lock)
+func (g *GeoIndex) growResidentLocked(kind, stateSlug string, bytes int64) {
+	if elem, ok := g.residentByKey[residentKey(kind, stateSlug)]; ok {
+		elem.Value.(*residentEntry).bytes += bytes
+		g.residentBytes += bytes
+	}
+}
+
+// evictLocked evicts least recently used data until the index fits its
+// memory budget. The most recently used entry is always kept, so a single
+// state larger than the budget still works. (must hold lock)
+func (g *GeoIndex) evictLocked() {
+	if g.config.MemoryBudgetBytes <= 0 {
+		return
+	}
+
+	for g.residentBytes > g.config.MemoryBudgetBytes && g.residentLRU.Len() > 1 {
+		entry := g.residentLRU.Back().Value.(*residentEntry)
+		switch entry.kind {
+		case residentBooths:
+			g.unindexBoothsLocked(entry.stateSlug)
+		case residentBoundaries:
+			g.unindexBoundariesLocked(entry.stateSlug)
+		case residentDistricts:
+			g.unindexDistrictBoundariesLocked(entry.stateSlug)
+		}
+		g.cacheEvictions++
+	}
+}
+
+// untrackResidentLocked removes data from the LRU list (must hold lock)
+func (g *GeoIndex) untrackResidentLocked(kind, stateSlug string) {
+	key := residentKey(kind, stateSlug)
+	if elem, ok := g.residentByKey[key]; ok {
+		g.residentBytes -= elem.Value.(*residentEntry).bytes
+		g.residentLRU.Remove(elem)
+		delete(g.residentByKey, key)
+	}
+}
+
+// unindexBoothsLocked removes a state's booths from every booth index (must hold lock)
+func (g *GeoIndex) unindexBoothsLocked(stateSlug string) {
+	booths := g.boothsByState[stateSlug]
+	evicted := make(map[*PollingBooth]bool, len(booths))
+	for _, booth := range booths {
+		evicted[booth] = true
+		delete(g.boothsByAC, fmt.Sprintf("%s:%d", stateSlug, booth.ACNumber))
+		delete(g.boothsByDistrict, fmt.Sprintf("%s:%s", stateSlug, ToSlug(booth.DistrictName)))
+		delete(g.boothByPartID, fmt.Sprintf("%s:%d:%d", stateSlug, booth.ACNumber, booth.PartID))
+	}
+
+	// Cells along state borders can hold booths of several states. Build new
+	// slices since earlier callers may still hold the old ones.
+	for _, cellID := range g.boothCellsByState[stateSlug] {
+		var kept []*PollingBooth
+		for _, booth := range g.boothsByCell[cellID] {
+			if !evicted[booth] {
+				kept = append(kept, booth)
+			}
+		}
+		if len(kept) == 0 {
+			delete(g.boothsByCell, cellID)
+		} else {
+			g.boothsByCell[cellID] = kept
+		}
+	}
+
+	delete(g.boothsByState, stateSlug)
+	delete(g.boothCellsByState, stateSlug)
+	delete(g.loadedStates, stateSlug)
+	g.untrackResidentLocked(residentBooths, stateSlug)
+}
+
+// unindexBoundariesLocked removes a state's AC boundaries from every boundary index (must hold lock)
+func (g *GeoIndex) unindexBoundariesLocked(stateSlug string) {
+	for _, boundary := range g.boundariesByState[stateSlug] {
+		delete(g.boundaryByAC, fmt.Sprintf("%s:%d", stateSlug, boundary.ConsCode))
+	}
+
+	for level := 1; level <= len(g.config.LODTolerancesM); level++ {
+		delete(g.boundaryLODs, fmt.Sprintf("%s:%d", stateSlug, level))
+	}
+
+	delete(g.boundariesByState, stateSlug)
+	delete(g.boundaryIndex, stateSlug)
+	delete(g.loadedBounds, stateSlug)
+	g.untrackResidentLocked(residentBoundaries, stateSlug)
+}
+
+// estimateBoothBytes estimates the memory held by a state's indexed booths
+func estimateBoothBytes(booths []PollingBooth) int64 {
+	var total int64
+	for i := range booths {
+		booth := &booths[i]
+		total += boothOverheadBytes + int64(len(booth.StateCode)+len(booth.StateName)+
+			len(booth.DistrictCode)+len(booth.DistrictName)+len(booth.ACName)+len(booth.PartName))
+	}
+	return total
+}
+
+// estimateBoundaryBytes estimates the memory held by a state's indexed AC boundaries
+func estimateBoundaryBytes(boundaries []ACBoundary) int64 {
+	var total int64
+	for i := range boundaries {
+		boundary := &boundaries[i]
+		total += boundaryOverheadBytes + int64(len(boundary.UID)+len(boundary.StateUT)+len(boundary.ConsName))
+		total += int64(countVertices(boundary.GetPolygons())) * pointBytes
+		if boundary.coarse != nil {
+			total += int64(countVertices(boundary.coarse.polygons)) * pointBytes
+		}
+	}
+	return total
+}
+
+// estimateDistrictBoundaryBytes estimates the memory held by a state's indexed district boundaries
+func estimateDistrictBoundaryBytes(boundaries []DistrictBoundary) int64 {
+	var total int64
+	for i := range boundaries {
+		boundary := &boundaries[i]
+		total += boundaryOverheadBytes + int64(len(boundary.DistName)+len(boundary.StateUT))
+		total += int64(countVertices(boundary.Polygons)) * pointBytes
+	}
+	return total
+}
+
+// residentKey returns the LRU key for a state's resident data
+func residentKey(kind, stateSlug string) string {
+	return fmt.Sprintf("%s:%s", kind, stateSlug)
+}
@@ -0,0 +1,105 @@
+package data
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMemoryBudgetEviction(t *testing.T) {
+	config := DefaultGeoIndexConfig()
+	config.MemoryBudgetBytes = 1 // Only the most recently used data stays resident
+	index := NewGeoIndexWithConfig(writeTestData(t, testDataFiles()), config)
+	if err := index.LoadAll(); err != nil {
+		t.Fatalf("LoadAll() error: %v", err)
+	}
+
+	if booths, err := index.GetBoothsForAC("testland", 1); err != nil || len(booths) != 1 {
+		t.Fatalf("GetBoothsForAC(1) = %d booths, %v, want 1", len(booths), err)
+	}
+	if _, err := index.FindACAtPoint("testland", 12.5, 77.5); err != nil {
+		t.Fatalf("FindACAtPoint() error: %v", err)
+	}
+
+	// Loading boundaries evicted the booths, including their cell index
+	stats := index.GetStats()
+	if stats.StatesWithBooths != 0 || stats.StatesWithBounds != 1 || stats.CacheEvictions != 1 {
+		t.Errorf("GetStats() after boundary load = %+v, want booths evicted", stats)
+	}
+	index.mu.RLock()
+	cells, byAC := len(index.boothsByCell), len(index.boothsByAC)
+	index.mu.RUnlock()
+	if cells != 0 || byAC != 0 {
+		t.Errorf("booth indices after eviction: %d cells, %d ACs, want none", cells, byAC)
+	}
+
+	// Evicted data is reloaded transparently
+	booth, err := index.GetBooth("testland", 2, 2)
+	if err != nil || booth.ACName != "Beta" {
+		t.Errorf("GetBooth() after eviction = %v, %v, want Beta booth", booth, err)
+	}
+
+	stats = index.GetStats()
+	if stats.CacheMisses != 3 || stats.CacheEvictions != 2 || stats.BoundariesLoaded != 0 {
+		t.Errorf("GetStats() after reload = %+v, want 3 misses and 2 evictions", stats)
+	}
+	if stats.ResidentBytes <= 0 {
+		t.Errorf("ResidentBytes = %d, want > 0", stats.ResidentBytes)
+	}
+}
+
+func TestUnlimitedMemoryBudget(t *testing.T) {
+	index := newTestIndex(t)
+
+	for range 3 {
+		if _, err := index.GetBoothsForState("testland"); err != nil {
+			t.Fatalf("GetBoothsForState() error: %v", err)
+		}
+	}
+
+	stats := index.GetStats()
+	if stats.CacheMisses != 1 || stats.CacheHits != 2 || stats.CacheEvictions != 0 {
+		t.Errorf("GetStats() = %+v, want 1 miss, 2 hits, no evictions", stats)
+	}
+}
+
+func TestMemoryBudgetEvictsDistrictBoundaries(t *testing.T) {
+	config := DefaultGeoIndexConfig()
+	config.MemoryBudgetBytes = 1
+	index := NewGeoIndexWithConfig(writeTestData(t, testDataFiles()), config)
+	if err := index.LoadAll(); err != nil {
+		t.Fatalf("LoadAll() error: %v", err)
+	}
+
+	if _, err := index.FindDistrictAtPoint("testland", 12.5, 77.5); err != nil {
+		t.Fatalf("FindDistrictAtPoint() error: %v", err)
+	}
+	if _, err := index.FindACAtPoint("testland", 12.5, 77.5); err != nil {
+		t.Fatalf("FindACAtPoint() error: %v", err)
+	}
+
+	// Loading AC boundaries evicted the district boundaries
+	index.mu.RLock()
+	districtsLoaded, districts := index.loadedDistricts["testland"], len(index.districtBoundariesByState)
+	index.mu.RUnlock()
+	if districtsLoaded || districts != 0 {
+		t.Errorf("district boundaries resident after eviction: loaded %v, %d states", districtsLoaded, districts)
+	}
+
+	if district, err := index.FindDistrictAtPoint("testland", 12.5, 77.5); err != nil || district == nil {
+		t.Errorf("FindDistrictAtPoint() after eviction = %v, %v", district, err)
+	}
+}
+
+func TestReadResidentGivesUp(t *testing.T) {
+	index := newTestIndex(t)
+	loads := 0
+	load := func(string) error {
+		loads++
+		return nil
+	}
+	called := false
+	err := index.readResident(residentBooths, "testland", load, func() bool { return false }, func() { called = true })
+	if !errors.Is(err, ErrEvictedWhileReading) || called || loads != maxLoadAttempts {
+		t.Errorf("readResident() = %v after %d loads, fn called %v; want ErrEvictedWhileReading after %d", err, loads, called, maxLoadAttempts)
+	}
+}
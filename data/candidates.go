@@ -0,0 +1,121 @@
+package data
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ACCandidate is an AC that may contain a point given GPS error
+type ACCandidate struct {
+	Boundary   *ACBoundary
+	StateSlug  string                // State of the AC, which may differ from the state searched near its border
+	AC         *AssemblyConstituency // nil if the boundary lookup has no entry for the AC
+	Contains   bool                  // The reported point lies inside the AC
+	DistanceM  float64               // Distance from the point to the AC's nearest edge
+	Likelihood float64               // Probability the true location is in this AC; sums to 1 across candidates
+}
+
+// ACCandidateConfig configures AC candidate search
+type ACCandidateConfig struct {
+	// MarginM is how close, in metres, an AC border must be to a point for the
+	// AC to be a candidate
+	MarginM float64
+
+	// GPSErrorM is the standard deviation of the GPS error, in metres, used to
+	// weigh candidates. 0 gives the containing AC all the likelihood.
+	GPSErrorM float64
+}
+
+// DefaultACCandidateConfig returns the default candidate configuration
+func DefaultACCandidateConfig() ACCandidateConfig {
+	return ACCandidateConfig{
+		MarginM:   100,
+		GPSErrorM: 30,
+	}
+}
+
+// DistanceToACBoundary returns the distance in metres from a point to the
+// nearest edge of an AC, whether the point is inside or outside it
+func (g *GeoIndex) DistanceToACBoundary(stateSlug string, consCode int, lat, lng float64) (float64, error) {
+	boundary, err := g.GetBoundaryForAC(stateSlug, consCode)
+	if err != nil {
+		return 0, err
+	}
+	return boundary.DistanceToBoundary(lat, lng), nil
+}
+
+// FindACCandidates returns every AC that contains a point or whose border is
+// within the margin of it, most likely first. ACs of neighbouring states are
+// included when their border is within the margin. More than one candidate
+// means the point is too close to a border to pick an AC without asking the user.
+func (g *GeoIndex) FindACCandidates(stateSlug string, lat, lng float64) ([]ACCandidate, error) {
+	return g.FindACCandidatesWithConfig(stateSlug, lat, lng, DefaultACCandidateConfig())
+}
+
+// FindACCandidatesWithConfig finds AC candidates with custom configuration
+func (g *GeoIndex) FindACCandidatesWithConfig(stateSlug string, lat, lng float64, config ACCandidateConfig) ([]ACCandidate, error) {
+	boundaries, err := g.boundariesNearAllStates(stateSlug, lat, lng, config.MarginM)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []ACCandidate
+	for _, near := range boundaries {
+		boundary := near.boundary
+		contains := boundary.ContainsPoint(lat, lng)
+		distanceM := boundary.DistanceToBoundary(lat, lng)
+		if !contains && distanceM > config.MarginM {
+			continue
+		}
+
+		candidate := ACCandidate{Boundary: boundary, StateSlug: near.stateSlug, Contains: contains, DistanceM: distanceM}
+		candidate.AC, _ = g.GetACByConsCode(near.stateSlug, boundary.ConsCode)
+		candidates = append(candidates, candidate)
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: no AC within %.0f m of (%.6f, %.6f)", ErrACNotFound, config.MarginM, lat, lng)
+	}
+
+	weighACCandidates(candidates, config.GPSErrorM)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Likelihood != candidates[j].Likelihood {
+			return candidates[i].Likelihood > candidates[j].Likelihood
+		}
+		return candidates[i].DistanceM < candidates[j].DistanceM
+	})
+
+	return candidates, nil
+}
+
+// weighACCandidates sets each candidate's likelihood. With normally
+// distributed GPS error, the chance that the true location lies across a
+// straight border d metres away is Φ(-d/σ); the containing AC gets the
+// complement Φ(d/σ). The weights are then normalised to sum to 1.
+func weighACCandidates(candidates []ACCandidate, gpsErrorM float64) {
+	var total float64
+	for i := range candidates {
+		candidate := &candidates[i]
+		switch {
+		case gpsErrorM > 0:
+			z := candidate.DistanceM / gpsErrorM
+			if !candidate.Contains {
+				z = -z
+			}
+			candidate.Likelihood = 0.5 * math.Erfc(-z/math.Sqrt2)
+		case candidate.Contains:
+			candidate.Likelihood = 1
+		}
+		total += candidate.Likelihood
+	}
+
+	for i := range candidates {
+		if total > 0 {
+			candidates[i].Likelihood /= total
+		} else {
+			// No containing AC and no GPS error: every candidate is equally likely
+			candidates[i].Likelihood = 1 / float64(len(candidates))
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package data
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestFindACCandidatesNearBorder(t *testing.T) {
+	index := newTestIndex(t)
+
+	// About 54 m west of the Alpha-Beta border at longitude 78
+	candidates, err := index.FindACCandidates("testland", 12.5, 77.9995)
+	if err != nil {
+		t.Fatalf("FindACCandidates() error: %v", err)
+	}
+	if len(candidates) != 2 {
+		t.Fatalf("got %d candidates, want 2: %+v", len(candidates), candidates)
+	}
+
+	alpha, beta := candidates[0], candidates[1]
+	if alpha.Boundary.ConsCode != 1 || !alpha.Contains || alpha.AC == nil || alpha.AC.Name != "Alpha" {
+		t.Errorf("first candidate = %+v, want containing Alpha", alpha)
+	}
+	if beta.Boundary.ConsCode != 2 || beta.Contains || math.Abs(beta.DistanceM-alpha.DistanceM) > 1 {
+		t.Errorf("second candidate = %+v, want Beta at the same distance", beta)
+	}
+
+	// 54 m at 30 m GPS error: Φ(1.81) ≈ 0.965
+	if math.Abs(alpha.Likelihood-0.965) > 0.01 || math.Abs(alpha.Likelihood+beta.Likelihood-1) > 1e-9 {
+		t.Errorf("likelihoods = %.3f, %.3f", alpha.Likelihood, beta.Likelihood)
+	}
+}
+
+func TestFindACCandidatesConfig(t *testing.T) {
+	index := newTestIndex(t)
+
+	config := DefaultACCandidateConfig()
+	config.GPSErrorM = 0
+	candidates, err := index.FindACCandidatesWithConfig("testland", 12.5, 77.9995, config)
+	if err != nil {
+		t.Fatalf("FindACCandidatesWithConfig() error: %v", err)
+	}
+	if len(candidates) != 2 || candidates[0].Likelihood != 1 || candidates[1].Likelihood != 0 {
+		t.Errorf("without GPS error = %+v, want containing AC certain", candidates)
+	}
+
+	config = DefaultACCandidateConfig()
+	config.MarginM = 20
+	candidates, err = index.FindACCandidatesWithConfig("testland", 12.5, 77.9995, config)
+	if err != nil {
+		t.Fatalf("FindACCandidatesWithConfig() error: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].Likelihood != 1 {
+		t.Errorf("with 20 m margin = %+v, want Alpha only", candidates)
+	}
+}
+
+func TestFindACCandidatesOutsideACs(t *testing.T) {
+	index := newTestIndex(t)
+
+	// Just east of Gamma, outside every AC
+	candidates, err := index.FindACCandidates("testland", 12.5, 80.0005)
+	if err != nil {
+		t.Fatalf("FindACCandidates() error: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].Boundary.ConsCode != 3 || candidates[0].Contains || candidates[0].Likelihood != 1 {
+		t.Errorf("candidates = %+v, want Gamma from outside", candidates)
+	}
+
+	if _, err := index.FindACCandidates("testland", 12.5, 81); !errors.Is(err, ErrACNotFound) {
+		t.Errorf("far outside error = %v, want ErrACNotFound", err)
+	}
+}
+
+func TestFindACCandidatesAcrossStates(t *testing.T) {
+	index := newAdjacencyTestIndex(t)
+
+	// Between Gamma and Delta, in the gap left by the two state files
+	candidates, err := index.FindACCandidates("testland", 12.5, 80.00015)
+	if err != nil {
+		t.Fatalf("FindACCandidates() error: %v", err)
+	}
+	var got []ACNode
+	for _, candidate := range candidates {
+		got = append(got, ACNode{candidate.StateSlug, candidate.Boundary.ConsCode})
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i].StateSlug > got[j].StateSlug })
+	if want := []ACNode{{"testland", 3}, {"otherland", 1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("candidates = %v, want %v", got, want)
+	}
+
+	// Inside Delta, searched from Testland
+	candidates, err = index.FindACCandidates("testland", 12.5, 80.01)
+	if err != nil || len(candidates) != 1 || candidates[0].StateSlug != "otherland" || !candidates[0].Contains {
+		t.Errorf("candidates inside Delta = %+v, %v, want Delta", candidates, err)
+	}
+}
+
+func TestDistanceToACBoundary(t *testing.T) {
+	index := newTestIndex(t)
+
+	distanceM, err := index.DistanceToACBoundary("testland", 2, 12.5, 77.9995)
+	if err != nil {
+		t.Fatalf("DistanceToACBoundary() error: %v", err)
+	}
+	if distanceM < 50 || distanceM > 60 {
+		t.Errorf("DistanceToACBoundary() = %.1f, want about 54", distanceM)
+	}
+
+	if _, err := index.DistanceToACBoundary("testland", 9, 12.5, 77.5); !errors.Is(err, ErrBoundaryNotFound) {
+		t.Errorf("unknown AC error = %v, want ErrBoundaryNotFound", err)
+	}
+}
@@ -0,0 +1,260 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ConsistencyReport cross-references AC metadata, boundaries, the
+// constituency boundary lookup and booth files for one state
+type ConsistencyReport struct {
+	StateSlug      string
+	MissingSources []string // Sources with no data for the state: "boundaries", "booths"
+
+	ACsWithoutBoundary  []ACRef
+	BoundariesWithoutAC []ACRef
+	NameMismatches      []NameMismatch
+	LookupIssues        []LookupIssue
+	BoothACsMissing     []int // Booth AC numbers with no AC in the metadata
+	BoothsOutsideAC     []BoothLocationIssue
+}
+
+// ACRef identifies an AC in one of the data sources
+type ACRef struct {
+	ACID     string // Metadata ID ("ac_106"), empty for boundaries
+	ConsCode int    // Boundary/booth AC number, 0 if unknown
+	Name     string
+}
+
+// NameMismatch is an AC whose name differs between two sources
+type NameMismatch struct {
+	ACID      string
+	ConsCode  int
+	Source    string // "boundaries" or "booths"
+	ACName    string // Name in the AC metadata
+	OtherName string // Name in Source
+}
+
+// LookupIssue is a lookup entry that does not agree with the other sources
+type LookupIssue struct {
+	Entry  ConstituencyBoundaryLookup
+	Reason string
+}
+
+// BoothLocationIssue is a booth whose coordinates fall outside its declared AC
+type BoothLocationIssue struct {
+	PartID      int
+	PartNumber  int
+	ACNumber    int
+	Lat         float64
+	Lon         float64
+	FoundACCode int // AC that contains the coordinates, 0 if none
+}
+
+// IsConsistent returns true if the report found no problems
+func (r *ConsistencyReport) IsConsistent() bool {
+	return len(r.ACsWithoutBoundary) == 0 &&
+		len(r.BoundariesWithoutAC) == 0 &&
+		len(r.NameMismatches) == 0 &&
+		len(r.LookupIssues) == 0 &&
+		len(r.BoothACsMissing) == 0 &&
+		len(r.BoothsOutsideAC) == 0
+}
+
+// CheckConsistency cross-references AC metadata, boundaries, the constituency
+// boundary lookup and booth files for a state. Missing boundary or booth data
+// is recorded in MissingSources and the checks that need it are skipped.
+func (g *GeoIndex) CheckConsistency(stateSlug string) (*ConsistencyReport, error) {
+	acs := g.GetACsForState(stateSlug)
+	if len(acs) == 0 {
+		return nil, fmt.Errorf("%w: no AC metadata for %s", ErrStateNotFound, stateSlug)
+	}
+
+	report := &ConsistencyReport{StateSlug: stateSlug}
+
+	boundaries, err := g.GetBoundariesForState(stateSlug)
+	if err != nil {
+		if !errors.Is(err, ErrStateNotFound) {
+			return nil, err
+		}
+		report.MissingSources = append(report.MissingSources, "boundaries")
+	}
+
+	booths, err := g.GetBoothsForState(stateSlug)
+	if err != nil {
+		if !errors.Is(err, ErrStateNotFound) {
+			return nil, err
+		}
+		report.MissingSources = append(report.MissingSources, "booths")
+	}
+
+	boundaryByCode := make(map[int]*ACBoundary, len(boundaries))
+	for _, boundary := range boundaries {
+		boundaryByCode[boundary.ConsCode] = boundary
+	}
+
+	lookupByACID := make(map[string]ConstituencyBoundaryLookup)
+	for _, entry := range g.GetConstituencyLookup(stateSlug) {
+		lookupByACID[entry.ACID] = entry
+	}
+
+	// Link each metadata AC to a cons code
+	acByCode := make(map[int]*AssemblyConstituency, len(acs))
+	acIDs := make(map[string]bool, len(acs))
+	for _, ac := range acs {
+		acIDs[ac.ID] = true
+
+		consCode := 0
+		if entry, ok := lookupByACID[ac.ID]; ok {
+			consCode = entry.ACCode
+		} else if boundary := g.findBoundaryByACName(stateSlug, ac, boundaries); boundary != nil {
+			consCode = boundary.ConsCode
+		}
+
+		if consCode == 0 {
+			report.ACsWithoutBoundary = append(report.ACsWithoutBoundary, ACRef{ACID: ac.ID, Name: ac.Name})
+			continue
+		}
+		acByCode[consCode] = ac
+
+		if boundaries == nil {
+			continue
+		}
+		boundary, ok := boundaryByCode[consCode]
+		if !ok {
+			report.ACsWithoutBoundary = append(report.ACsWithoutBoundary, ACRef{ACID: ac.ID, ConsCode: consCode, Name: ac.Name})
+			continue
+		}
+		if !g.acNamesMatch(stateSlug, ac, boundary.ConsName) {
+			report.NameMismatches = append(report.NameMismatches, NameMismatch{
+				ACID: ac.ID, ConsCode: consCode, Source: "boundaries", ACName: ac.Name, OtherName: boundary.ConsName,
+			})
+		}
+	}
+
+	for _, boundary := range boundaries {
+		if _, ok := acByCode[boundary.ConsCode]; !ok {
+			report.BoundariesWithoutAC = append(report.BoundariesWithoutAC, ACRef{ConsCode: boundary.ConsCode, Name: boundary.ConsName})
+		}
+	}
+
+	// Lookup entries must point at metadata ACs and at the boundary feature they name
+	for _, entry := range g.GetConstituencyLookup(stateSlug) {
+		if !acIDs[entry.ACID] {
+			report.LookupIssues = append(report.LookupIssues, LookupIssue{Entry: entry, Reason: "AC ID not in metadata"})
+			continue
+		}
+		if boundaries == nil {
+			continue
+		}
+		if entry.FeatureIndex < 0 || entry.FeatureIndex >= len(boundaries) {
+			report.LookupIssues = append(report.LookupIssues, LookupIssue{Entry: entry, Reason: "feature index out of range"})
+			continue
+		}
+		feature := boundaries[entry.FeatureIndex]
+		if feature.ConsCode != entry.ACCode || feature.ConsName != entry.ACName {
+			report.LookupIssues = append(report.LookupIssues, LookupIssue{
+				Entry:  entry,
+				Reason: fmt.Sprintf("feature %d is %d %s", entry.FeatureIndex, feature.ConsCode, feature.ConsName),
+			})
+		}
+	}
+
+	g.checkBooths(report, booths, acByCode, boundaryByCode, boundaries != nil)
+
+	return report, nil
+}
+
+// CheckAllConsistency runs CheckConsistency for every state with AC metadata
+func (g *GeoIndex) CheckAllConsistency() ([]*ConsistencyReport, error) {
+	g.mu.RLock()
+	stateSlugs := make([]string, 0, len(g.acsByState))
+	for stateSlug := range g.acsByState {
+		stateSlugs = append(stateSlugs, stateSlug)
+	}
+	g.mu.RUnlock()
+	sort.Strings(stateSlugs)
+
+	reports := make([]*ConsistencyReport, 0, len(stateSlugs))
+	for _, stateSlug := range stateSlugs {
+		report, err := g.CheckConsistency(stateSlug)
+		if err != nil {
+			return nil, fmt.Errorf("checking %s: %w", stateSlug, err)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// checkBooths checks booth AC numbers, names and coordinates against the other sources
+func (g *GeoIndex) checkBooths(report *ConsistencyReport, booths []*PollingBooth, acByCode map[int]*AssemblyConstituency, boundaryByCode map[int]*ACBoundary, haveBoundaries bool) {
+	missing := make(map[int]bool)
+	namesChecked := make(map[int]bool)
+
+	for _, booth := range booths {
+		ac, ok := acByCode[booth.ACNumber]
+		if !ok {
+			if !missing[booth.ACNumber] {
+				missing[booth.ACNumber] = true
+				report.BoothACsMissing = append(report.BoothACsMissing, booth.ACNumber)
+			}
+			continue
+		}
+
+		if !namesChecked[booth.ACNumber] {
+			namesChecked[booth.ACNumber] = true
+			if !g.acNamesMatch(report.StateSlug, ac, booth.ACName) {
+				report.NameMismatches = append(report.NameMismatches, NameMismatch{
+					ACID: ac.ID, ConsCode: booth.ACNumber, Source: "booths", ACName: ac.Name, OtherName: booth.ACName,
+				})
+			}
+		}
+
+		if !haveBoundaries || booth.Lat == nil || booth.Lon == nil {
+			continue
+		}
+		boundary, ok := boundaryByCode[booth.ACNumber]
+		if !ok || boundary.ContainsPoint(*booth.Lat, *booth.Lon) {
+			continue
+		}
+
+		issue := BoothLocationIssue{
+			PartID:     booth.PartID,
+			PartNumber: booth.PartNumber,
+			ACNumber:   booth.ACNumber,
+			Lat:        *booth.Lat,
+			Lon:        *booth.Lon,
+		}
+		if found, err := g.FindACAtPoint(report.StateSlug, *booth.Lat, *booth.Lon); err == nil {
+			issue.FoundACCode = found.ConsCode
+		}
+		report.BoothsOutsideAC = append(report.BoothsOutsideAC, issue)
+	}
+
+	sort.Ints(report.BoothACsMissing)
+}
+
+// findBoundaryByACName finds the boundary whose name matches an AC's name or alias
+func (g *GeoIndex) findBoundaryByACName(stateSlug string, ac *AssemblyConstituency, boundaries []*ACBoundary) *ACBoundary {
+	for _, boundary := range boundaries {
+		if g.acNamesMatch(stateSlug, ac, boundary.ConsName) {
+			return boundary
+		}
+	}
+	return nil
+}
+
+// acNamesMatch checks if a name from another source refers to the AC,
+// either directly or through the alias table
+func (g *GeoIndex) acNamesMatch(stateSlug string, ac *AssemblyConstituency, name string) bool {
+	if normalizeACName(ac.Name) == normalizeACName(name) {
+		return true
+	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	aliased, ok := g.acsByAlias[fmt.Sprintf("%s:%s", stateSlug, ToSlug(name))]
+	return ok && aliased == ac
+}
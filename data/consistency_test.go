@@ -0,0 +1,108 @@
+package data
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckConsistencyClean(t *testing.T) {
+	index := newTestIndex(t)
+
+	report, err := index.CheckConsistency("testland")
+	if err != nil {
+		t.Fatalf("CheckConsistency() error: %v", err)
+	}
+	if !report.IsConsistent() {
+		t.Errorf("CheckConsistency() = %+v, want consistent", report)
+	}
+	if len(report.MissingSources) != 0 {
+		t.Errorf("MissingSources = %v, want none", report.MissingSources)
+	}
+
+	if ac, ok := index.GetACByConsCode("testland", 2); !ok || ac.Name != "Beta" {
+		t.Errorf("GetACByConsCode(2) = %v, %v, want Beta", ac, ok)
+	}
+}
+
+func TestCheckConsistencyMismatches(t *testing.T) {
+	files := testDataFiles()
+
+	// Drop Gamma's lookup entry and boundary, and add a boundary no AC refers to
+	files[ConstituencyBoundaryLookupFile] = `{
+		"ac_1": {"state_file": "testland.geojson", "state_feature_index": 0, "cons_code": 1, "cons_name": "Alpha"},
+		"ac_2": {"state_file": "testland.geojson", "state_feature_index": 0, "cons_code": 2, "cons_name": "Beta"},
+		"ac_9": {"state_file": "testland.geojson", "state_feature_index": 2, "cons_code": 4, "cons_name": "Delta"}}`
+	files[filepath.Join(BoundariesDir, "testland.geojson")] = `{"type": "FeatureCollection", "features": [` +
+		testSquareFeature(1, "Alpha", 77, 12) + `, ` +
+		testSquareFeature(2, "Betta", 78, 12) + `, ` +
+		testSquareFeature(4, "Delta", 80, 12) + `]}`
+
+	// Alpha's second booth sits inside Beta, and AC 7 has no metadata
+	files[filepath.Join(BoothsDir, "testland", "north.json")] = `[
+		{"partId": 1, "acNumber": 1, "acName": "Alpha", "partNumber": 1, "lat": 12.5, "lon": 77.5},
+		{"partId": 2, "acNumber": 1, "acName": "Alpha", "partNumber": 2, "lat": 12.5, "lon": 78.5},
+		{"partId": 3, "acNumber": 2, "acName": "Beta", "partNumber": 1, "lat": 12.5, "lon": 78.8},
+		{"partId": 4, "acNumber": 7, "acName": "Omega", "partNumber": 1}]`
+
+	index := NewGeoIndex(writeTestData(t, files))
+	if err := index.LoadAll(); err != nil {
+		t.Fatalf("LoadAll() error: %v", err)
+	}
+
+	report, err := index.CheckConsistency("testland")
+	if err != nil {
+		t.Fatalf("CheckConsistency() error: %v", err)
+	}
+	if report.IsConsistent() {
+		t.Fatal("CheckConsistency() reported consistent data")
+	}
+
+	if len(report.ACsWithoutBoundary) != 1 || report.ACsWithoutBoundary[0].ACID != "ac_3" {
+		t.Errorf("ACsWithoutBoundary = %+v, want ac_3", report.ACsWithoutBoundary)
+	}
+	if len(report.BoundariesWithoutAC) != 1 || report.BoundariesWithoutAC[0].ConsCode != 4 {
+		t.Errorf("BoundariesWithoutAC = %+v, want cons code 4", report.BoundariesWithoutAC)
+	}
+	if len(report.NameMismatches) != 1 || report.NameMismatches[0].OtherName != "Betta" {
+		t.Errorf("NameMismatches = %+v, want Beta/Betta", report.NameMismatches)
+	}
+
+	reasons := make(map[string]string)
+	for _, issue := range report.LookupIssues {
+		reasons[issue.Entry.ACID] = issue.Reason
+	}
+	if len(reasons) != 2 || !strings.Contains(reasons["ac_2"], "feature 0") || reasons["ac_9"] == "" {
+		t.Errorf("LookupIssues = %+v, want ac_2 and ac_9", report.LookupIssues)
+	}
+
+	if len(report.BoothACsMissing) != 1 || report.BoothACsMissing[0] != 7 {
+		t.Errorf("BoothACsMissing = %v, want [7]", report.BoothACsMissing)
+	}
+	if len(report.BoothsOutsideAC) != 1 || report.BoothsOutsideAC[0].PartID != 2 || report.BoothsOutsideAC[0].FoundACCode != 2 {
+		t.Errorf("BoothsOutsideAC = %+v, want part 2 found in AC 2", report.BoothsOutsideAC)
+	}
+}
+
+func TestCheckConsistencyMissingSources(t *testing.T) {
+	files := testDataFiles()
+	delete(files, filepath.Join(BoundariesDir, "testland.geojson"))
+	delete(files, filepath.Join(BoothsDir, "testland", "north.json"))
+
+	index := NewGeoIndex(writeTestData(t, files))
+	if err := index.LoadAll(); err != nil {
+		t.Fatalf("LoadAll() error: %v", err)
+	}
+
+	report, err := index.CheckConsistency("testland")
+	if err != nil {
+		t.Fatalf("CheckConsistency() error: %v", err)
+	}
+	if len(report.MissingSources) != 2 {
+		t.Errorf("MissingSources = %v, want boundaries and booths", report.MissingSources)
+	}
+
+	if _, err := index.CheckConsistency("nowhere"); err == nil {
+		t.Error("CheckConsistency() for unknown state should fail")
+	}
+}
@@ -0,0 +1,196 @@
+package data
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+	"strings"
+
+	h3utils "github.com/politic-in/core/h3-utils"
+)
+
+// BoothChangeType is the kind of change to a booth between two revisions
+type BoothChangeType string
+
+// Booth change types
+const (
+	BoothAdded      BoothChangeType = "added"
+	BoothRemoved    BoothChangeType = "removed"
+	BoothRenumbered BoothChangeType = "renumbered" // PartNumber changed
+	BoothRenamed    BoothChangeType = "renamed"    // PartName changed
+	BoothMoved      BoothChangeType = "moved"      // Coordinates shifted, added or dropped
+	BoothReassigned BoothChangeType = "reassigned" // ACNumber changed
+)
+
+// BoothChange is one change to a booth, identified by its PartID.
+// A booth with several edits appears once per change type.
+type BoothChange struct {
+	Type      BoothChangeType
+	PartID    int
+	Old       *PollingBooth // nil for added booths
+	New       *PollingBooth // nil for removed booths
+	DistanceM float64       // Distance moved; 0 if the booth gained or lost coordinates
+}
+
+// BoothChangeset lists the changes between two revisions of a state's booths,
+// each sorted by PartID
+type BoothChangeset struct {
+	Added      []BoothChange
+	Removed    []BoothChange
+	Renumbered []BoothChange
+	Renamed    []BoothChange
+	Moved      []BoothChange
+	Reassigned []BoothChange
+	Unchanged  int // Booths present in both revisions with no changes
+}
+
+// IsEmpty returns true if the revisions have the same booths
+func (c *BoothChangeset) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Renumbered) == 0 &&
+		len(c.Renamed) == 0 && len(c.Moved) == 0 && len(c.Reassigned) == 0
+}
+
+// BoothDiffConfig configures booth diffing
+type BoothDiffConfig struct {
+	// MoveThresholdM is the minimum coordinate shift, in metres, reported as a move
+	MoveThresholdM float64
+}
+
+// DefaultBoothDiffConfig returns the default diff configuration
+func DefaultBoothDiffConfig() BoothDiffConfig {
+	return BoothDiffConfig{
+		MoveThresholdM: 10,
+	}
+}
+
+// DiffBooths compares two revisions of a state's booths. Booths are matched
+// by PartID, which is unique within a state.
+func DiffBooths(oldBooths, newBooths []PollingBooth) *BoothChangeset {
+	return DiffBoothsWithConfig(oldBooths, newBooths, DefaultBoothDiffConfig())
+}
+
+// DiffBoothsWithConfig compares two revisions of a state's booths with custom configuration
+func DiffBoothsWithConfig(oldBooths, newBooths []PollingBooth, config BoothDiffConfig) *BoothChangeset {
+	oldByID := make(map[int]*PollingBooth, len(oldBooths))
+	for i := range oldBooths {
+		oldByID[oldBooths[i].PartID] = &oldBooths[i]
+	}
+	newByID := make(map[int]*PollingBooth, len(newBooths))
+	for i := range newBooths {
+		newByID[newBooths[i].PartID] = &newBooths[i]
+	}
+
+	changeset := &BoothChangeset{}
+	for partID, oldBooth := range oldByID {
+		if _, ok := newByID[partID]; !ok {
+			changeset.Removed = append(changeset.Removed, BoothChange{Type: BoothRemoved, PartID: partID, Old: oldBooth})
+		}
+	}
+
+	for partID, newBooth := range newByID {
+		oldBooth, ok := oldByID[partID]
+		if !ok {
+			changeset.Added = append(changeset.Added, BoothChange{Type: BoothAdded, PartID: partID, New: newBooth})
+			continue
+		}
+
+		change := BoothChange{PartID: partID, Old: oldBooth, New: newBooth}
+		changed := false
+		add := func(list *[]BoothChange, changeType BoothChangeType) {
+			change.Type = changeType
+			*list = append(*list, change)
+			changed = true
+		}
+
+		if oldBooth.PartNumber != newBooth.PartNumber {
+			add(&changeset.Renumbered, BoothRenumbered)
+		}
+		if strings.TrimSpace(oldBooth.PartName) != strings.TrimSpace(newBooth.PartName) {
+			add(&changeset.Renamed, BoothRenamed)
+		}
+		if oldBooth.ACNumber != newBooth.ACNumber {
+			add(&changeset.Reassigned, BoothReassigned)
+		}
+		if distanceM, moved := boothMoved(oldBooth, newBooth, config.MoveThresholdM); moved {
+			change.DistanceM = distanceM
+			add(&changeset.Moved, BoothMoved)
+		}
+
+		if !changed {
+			changeset.Unchanged++
+		}
+	}
+
+	for _, list := range [][]BoothChange{
+		changeset.Added, changeset.Removed, changeset.Renumbered,
+		changeset.Renamed, changeset.Moved, changeset.Reassigned,
+	} {
+		sort.Slice(list, func(i, j int) bool { return list[i].PartID < list[j].PartID })
+	}
+
+	return changeset
+}
+
+// DiffBoothDirs compares the booth files of two data directories.
+// See DiffBoothsFS.
+func DiffBoothDirs(oldDir, newDir string) (map[string]*BoothChangeset, error) {
+	return DiffBoothsFS(dirFS(oldDir), dirFS(newDir))
+}
+
+// DiffBoothsFS compares the booth files of two data sources state by state.
+// It returns a changeset for each state with changes, keyed by state slug.
+// States present in only one source have all their booths added or removed.
+func DiffBoothsFS(oldFS, newFS fs.FS) (map[string]*BoothChangeset, error) {
+	stateSlugs := make(map[string]bool)
+	for _, fsys := range []fs.FS{oldFS, newFS} {
+		dirNames, err := ListAvailableStatesFS(fsys)
+		if err != nil && !errors.Is(err, ErrDataDirNotFound) {
+			return nil, err
+		}
+		for _, dirName := range dirNames {
+			stateSlugs[NormalizeBoothDirToStateSlug(dirName)] = true
+		}
+	}
+
+	result := make(map[string]*BoothChangeset)
+	for stateSlug := range stateSlugs {
+		oldBooths, err := loadBoothsForDiff(oldFS, stateSlug)
+		if err != nil {
+			return nil, err
+		}
+		newBooths, err := loadBoothsForDiff(newFS, stateSlug)
+		if err != nil {
+			return nil, err
+		}
+
+		if changeset := DiffBooths(oldBooths, newBooths); !changeset.IsEmpty() {
+			result[stateSlug] = changeset
+		}
+	}
+
+	return result, nil
+}
+
+// loadBoothsForDiff loads a state's booths, treating a missing state as having none
+func loadBoothsForDiff(fsys fs.FS, stateSlug string) ([]PollingBooth, error) {
+	booths, err := LoadBoothsForStateFS(fsys, stateSlug)
+	if errors.Is(err, ErrStateNotFound) {
+		return nil, nil
+	}
+	return booths, err
+}
+
+// boothMoved reports whether a booth's coordinates changed by more than thresholdM
+func boothMoved(oldBooth, newBooth *PollingBooth, thresholdM float64) (float64, bool) {
+	oldLocated := oldBooth.Lat != nil && oldBooth.Lon != nil
+	newLocated := newBooth.Lat != nil && newBooth.Lon != nil
+	if oldLocated != newLocated {
+		return 0, true
+	}
+	if !oldLocated {
+		return 0, false
+	}
+
+	distanceM := h3utils.HaversineDistance(*oldBooth.Lat, *oldBooth.Lon, *newBooth.Lat, *newBooth.Lon)
+	return distanceM, distanceM > thresholdM
+}
@@ -0,0 +1,88 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffBooths(t *testing.T) {
+	lat, lon := 12.5, 77.5
+	nearLon, farLon := 77.50005, 77.51
+
+	oldBooths := []PollingBooth{
+		{PartID: 1, ACNumber: 1, PartNumber: 1, PartName: "Govt School", Lat: &lat, Lon: &lon},
+		{PartID: 2, ACNumber: 1, PartNumber: 2, PartName: "Panchayat Office", Lat: &lat, Lon: &lon},
+		{PartID: 3, ACNumber: 1, PartNumber: 3, PartName: "Community Hall"},
+		{PartID: 4, ACNumber: 1, PartNumber: 4, PartName: "Library", Lat: &lat, Lon: &lon},
+	}
+	newBooths := []PollingBooth{
+		{PartID: 1, ACNumber: 1, PartNumber: 1, PartName: "Govt School ", Lat: &lat, Lon: &nearLon},
+		{PartID: 2, ACNumber: 2, PartNumber: 5, PartName: "Gram Panchayat Office", Lat: &lat, Lon: &farLon},
+		{PartID: 3, ACNumber: 1, PartNumber: 3, PartName: "Community Hall", Lat: &lat, Lon: &lon},
+		{PartID: 5, ACNumber: 1, PartNumber: 4, PartName: "New Library", Lat: &lat, Lon: &lon},
+	}
+
+	changeset := DiffBooths(oldBooths, newBooths)
+
+	if len(changeset.Added) != 1 || changeset.Added[0].PartID != 5 || changeset.Added[0].Old != nil {
+		t.Errorf("Added = %+v, want part 5", changeset.Added)
+	}
+	if len(changeset.Removed) != 1 || changeset.Removed[0].PartID != 4 || changeset.Removed[0].New != nil {
+		t.Errorf("Removed = %+v, want part 4", changeset.Removed)
+	}
+	if len(changeset.Renumbered) != 1 || changeset.Renumbered[0].New.PartNumber != 5 {
+		t.Errorf("Renumbered = %+v, want part 2 renumbered to 5", changeset.Renumbered)
+	}
+	if len(changeset.Renamed) != 1 || changeset.Renamed[0].PartID != 2 {
+		t.Errorf("Renamed = %+v, want only part 2", changeset.Renamed)
+	}
+	if len(changeset.Reassigned) != 1 || changeset.Reassigned[0].New.ACNumber != 2 {
+		t.Errorf("Reassigned = %+v, want part 2 in AC 2", changeset.Reassigned)
+	}
+
+	// Part 1 moved ~5m (below the threshold), part 2 ~1km, part 3 gained coordinates
+	if len(changeset.Moved) != 2 || changeset.Moved[0].PartID != 2 || changeset.Moved[1].PartID != 3 {
+		t.Fatalf("Moved = %+v, want parts 2 and 3", changeset.Moved)
+	}
+	if d := changeset.Moved[0].DistanceM; d < 1000 || d > 1100 {
+		t.Errorf("Moved[0].DistanceM = %.0f, want ~1085", d)
+	}
+	if changeset.Unchanged != 1 {
+		t.Errorf("Unchanged = %d, want 1", changeset.Unchanged)
+	}
+
+	if !DiffBooths(oldBooths, oldBooths).IsEmpty() {
+		t.Error("DiffBooths() of identical revisions is not empty")
+	}
+}
+
+func TestDiffBoothDirs(t *testing.T) {
+	oldDir := writeTestData(t, testDataFiles())
+	newDir := writeTestData(t, testDataFiles())
+
+	writeTestBooths(t, newDir, "v2", 1)
+	otherland := filepath.Join(newDir, BoothsDir, "otherland")
+	if err := os.MkdirAll(otherland, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(otherland, "east.json"), []byte(`[{"partId": 9, "acNumber": 1, "partNumber": 1}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	changesets, err := DiffBoothDirs(oldDir, newDir)
+	if err != nil {
+		t.Fatalf("DiffBoothDirs() error: %v", err)
+	}
+	if len(changesets) != 2 {
+		t.Fatalf("DiffBoothDirs() = %d states, want 2", len(changesets))
+	}
+
+	testland := changesets["testland"]
+	if len(testland.Removed) != 2 || len(testland.Renamed) != 1 || testland.Unchanged != 0 {
+		t.Errorf("testland changeset = %+v, want parts 2 and 3 removed and part 1 renamed", testland)
+	}
+	if added := changesets["otherland"].Added; len(added) != 1 || added[0].PartID != 9 {
+		t.Errorf("otherland Added = %+v, want part 9", added)
+	}
+}
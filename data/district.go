@@ -0,0 +1,256 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+
+	h3utils "github.com/politic-in/core/h3-utils"
+)
+
+// overlapResolution is the H3 resolution used to measure AC/district overlap (~0.7 km² cells)
+const overlapResolution = 8
+
+// LoadDistrictBoundariesForState lazily loads district boundaries for a state
+func (g *GeoIndex) LoadDistrictBoundariesForState(stateSlug string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.loadedDistricts[stateSlug] {
+		g.touchResidentLocked(residentDistricts, stateSlug)
+		return nil
+	}
+	g.cacheMisses++
+
+	boundaries, err := LoadDistrictBoundariesForStateFS(g.fsys, stateSlug)
+	if err != nil {
+		return err
+	}
+	g.indexDistrictBoundariesLocked(stateSlug, boundaries)
+	g.evictLocked()
+	return nil
+}
+
+// indexDistrictBoundariesLocked indexes a state's district boundaries (must hold lock)
+func (g *GeoIndex) indexDistrictBoundariesLocked(stateSlug string, boundaries []DistrictBoundary) {
+	boxes := make([][4]float64, len(boundaries))
+	for i := range boundaries {
+		boundary := &boundaries[i]
+		g.districtBoundariesByState[stateSlug] = append(g.districtBoundariesByState[stateSlug], boundary)
+
+		key := fmt.Sprintf("%s:%s", stateSlug, boundary.Slug())
+		g.districtBoundaryByName[key] = boundary
+
+		boxes[i] = boundary.BoundingBox()
+	}
+	g.districtBoundaryIndex[stateSlug] = newSpatialIndex(boxes)
+
+	g.loadedDistricts[stateSlug] = true
+	g.trackResidentLocked(residentDistricts, stateSlug, estimateDistrictBoundaryBytes(boundaries))
+}
+
+// GetDistrictBoundariesForState returns all district boundaries for a state (loads if needed)
+func (g *GeoIndex) GetDistrictBoundariesForState(stateSlug string) ([]*DistrictBoundary, error) {
+	var boundaries []*DistrictBoundary
+	err := g.readDistrictBoundaries(stateSlug, func() {
+		boundaries = g.districtBoundariesByState[stateSlug]
+	})
+	return boundaries, err
+}
+
+// GetDistrictBoundary returns the boundary for a district by state and district slug
+func (g *GeoIndex) GetDistrictBoundary(stateSlug, districtSlug string) (*DistrictBoundary, error) {
+	var boundary *DistrictBoundary
+	var ok bool
+	err := g.readDistrictBoundaries(stateSlug, func() {
+		key := fmt.Sprintf("%s:%s", stateSlug, districtSlug)
+		boundary, ok = g.districtBoundaryByName[key]
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s/%s", ErrBoundaryNotFound, stateSlug, districtSlug)
+	}
+	return boundary, nil
+}
+
+// FindDistrictAtPoint finds the district that contains the given point
+func (g *GeoIndex) FindDistrictAtPoint(stateSlug string, lat, lng float64) (*DistrictBoundary, error) {
+	var found *DistrictBoundary
+	err := g.readDistrictBoundaries(stateSlug, func() {
+		boundaries := g.districtBoundariesByState[stateSlug]
+		for _, i := range g.districtBoundaryIndex[stateSlug].SearchPoint(lat, lng) {
+			if boundaries[i].ContainsPoint(lat, lng) {
+				found = boundaries[i]
+				return
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found != nil {
+		return found, nil
+	}
+
+	return nil, fmt.Errorf("%w: no district found at (%.6f, %.6f)", ErrDistrictNotFound, lat, lng)
+}
+
+// GetH3CellsForDistrict returns H3 cells that cover a district boundary
+func (g *GeoIndex) GetH3CellsForDistrict(stateSlug, districtSlug string, resolution int) ([]string, error) {
+	boundary, err := g.GetDistrictBoundary(stateSlug, districtSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(boundary.Polygons) == 0 {
+		return nil, fmt.Errorf("%w: empty polygon for %s/%s", ErrBoundaryNotFound, stateSlug, districtSlug)
+	}
+
+	return polygonsToCells(boundary.Polygons, resolution, h3utils.ContainmentCentroid)
+}
+
+// GetBoothsInDistrictBoundary returns the booths of a state whose coordinates
+// fall inside a district boundary. Unlike GetBoothsForDistrict it does not
+// depend on the district name recorded in the booth files.
+func (g *GeoIndex) GetBoothsInDistrictBoundary(stateSlug, districtSlug string) ([]*PollingBooth, error) {
+	boundary, err := g.GetDistrictBoundary(stateSlug, districtSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	booths, err := g.GetBoothsForState(stateSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*PollingBooth
+	for _, booth := range booths {
+		if booth.Lat != nil && booth.Lon != nil && boundary.ContainsPoint(*booth.Lat, *booth.Lon) {
+			result = append(result, booth)
+		}
+	}
+
+	return result, nil
+}
+
+// ACDistrictOverlap describes how an AC and a district overlap
+type ACDistrictOverlap struct {
+	StateSlug        string
+	ACCode           int
+	ACName           string
+	DistrictName     string
+	DistrictSlug     string
+	ACFraction       float64 // Share of the AC's area inside the district
+	DistrictFraction float64 // Share of the district's area inside the AC
+}
+
+// GetDistrictsForAC returns the districts an AC overlaps, largest overlap first
+func (g *GeoIndex) GetDistrictsForAC(stateSlug string, consCode int) ([]ACDistrictOverlap, error) {
+	ac, err := g.GetBoundaryForAC(stateSlug, consCode)
+	if err != nil {
+		return nil, err
+	}
+
+	acCells, err := polygonsToCells(ac.GetPolygons(), overlapResolution, h3utils.ContainmentCentroid)
+	if err != nil {
+		return nil, err
+	}
+
+	var districts []*DistrictBoundary
+	err = g.readDistrictBoundaries(stateSlug, func() {
+		all := g.districtBoundariesByState[stateSlug]
+		for _, i := range g.districtBoundaryIndex[stateSlug].SearchBBox(ac.BoundingBox()) {
+			districts = append(districts, all[i])
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var overlaps []ACDistrictOverlap
+	for _, district := range districts {
+		districtCells, err := polygonsToCells(district.Polygons, overlapResolution, h3utils.ContainmentCentroid)
+		if err != nil {
+			return nil, err
+		}
+		if overlap, ok := newACDistrictOverlap(stateSlug, ac, district, acCells, districtCells); ok {
+			overlaps = append(overlaps, overlap)
+		}
+	}
+
+	sort.SliceStable(overlaps, func(i, j int) bool {
+		return overlaps[i].ACFraction > overlaps[j].ACFraction
+	})
+
+	return overlaps, nil
+}
+
+// GetACsForDistrict returns the ACs a district overlaps, largest overlap first
+func (g *GeoIndex) GetACsForDistrict(stateSlug, districtSlug string) ([]ACDistrictOverlap, error) {
+	district, err := g.GetDistrictBoundary(stateSlug, districtSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	districtCells, err := polygonsToCells(district.Polygons, overlapResolution, h3utils.ContainmentCentroid)
+	if err != nil {
+		return nil, err
+	}
+
+	var acs []*ACBoundary
+	err = g.readBoundaries(stateSlug, func() {
+		all := g.boundariesByState[stateSlug]
+		for _, i := range g.boundaryIndex[stateSlug].SearchBBox(district.BoundingBox()) {
+			acs = append(acs, all[i])
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var overlaps []ACDistrictOverlap
+	for _, ac := range acs {
+		acCells, err := polygonsToCells(ac.GetPolygons(), overlapResolution, h3utils.ContainmentCentroid)
+		if err != nil {
+			return nil, err
+		}
+		if overlap, ok := newACDistrictOverlap(stateSlug, ac, district, acCells, districtCells); ok {
+			overlaps = append(overlaps, overlap)
+		}
+	}
+
+	sort.SliceStable(overlaps, func(i, j int) bool {
+		return overlaps[i].DistrictFraction > overlaps[j].DistrictFraction
+	})
+
+	return overlaps, nil
+}
+
+// newACDistrictOverlap measures the overlap of an AC and a district by their shared H3 cells
+func newACDistrictOverlap(stateSlug string, ac *ACBoundary, district *DistrictBoundary, acCells, districtCells []string) (ACDistrictOverlap, bool) {
+	districtSet := make(map[string]bool, len(districtCells))
+	for _, cellID := range districtCells {
+		districtSet[cellID] = true
+	}
+
+	shared := 0
+	for _, cellID := range acCells {
+		if districtSet[cellID] {
+			shared++
+		}
+	}
+	if shared == 0 {
+		return ACDistrictOverlap{}, false
+	}
+
+	return ACDistrictOverlap{
+		StateSlug:        stateSlug,
+		ACCode:           ac.ConsCode,
+		ACName:           ac.ConsName,
+		DistrictName:     district.DistName,
+		DistrictSlug:     district.Slug(),
+		ACFraction:       float64(shared) / float64(len(acCells)),
+		DistrictFraction: float64(shared) / float64(len(districtCells)),
+	}, true
+}
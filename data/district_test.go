@@ -0,0 +1,79 @@
+package data
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestFindDistrictAtPoint(t *testing.T) {
+	index := newTestIndex(t)
+
+	tests := []struct {
+		name     string
+		lat, lng float64
+		want     string
+	}{
+		{"north", 12.5, 77.2, "North"},
+		{"south", 12.5, 79.8, "South"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			district, err := index.FindDistrictAtPoint("testland", tt.lat, tt.lng)
+			if err != nil {
+				t.Fatalf("FindDistrictAtPoint() error: %v", err)
+			}
+			if district.DistName != tt.want {
+				t.Errorf("FindDistrictAtPoint() = %s, want %s", district.DistName, tt.want)
+			}
+		})
+	}
+
+	if _, err := index.FindDistrictAtPoint("testland", 20.0, 77.5); !errors.Is(err, ErrDistrictNotFound) {
+		t.Errorf("FindDistrictAtPoint() outside error = %v, want ErrDistrictNotFound", err)
+	}
+}
+
+func TestACDistrictOverlap(t *testing.T) {
+	index := newTestIndex(t)
+
+	// Beta (78-79) is split evenly between North (77-78.5) and South (78.5-80)
+	overlaps, err := index.GetDistrictsForAC("testland", 2)
+	if err != nil {
+		t.Fatalf("GetDistrictsForAC() error: %v", err)
+	}
+	if len(overlaps) != 2 {
+		t.Fatalf("GetDistrictsForAC() len = %d, want 2", len(overlaps))
+	}
+	for _, overlap := range overlaps {
+		if math.Abs(overlap.ACFraction-0.5) > 0.05 {
+			t.Errorf("AC fraction in %s = %.3f, want ~0.5", overlap.DistrictName, overlap.ACFraction)
+		}
+	}
+
+	// Alpha lies entirely in North
+	overlaps, err = index.GetACsForDistrict("testland", "north")
+	if err != nil {
+		t.Fatalf("GetACsForDistrict() error: %v", err)
+	}
+	if len(overlaps) != 2 || overlaps[0].ACCode != 1 {
+		t.Fatalf("GetACsForDistrict(north) = %+v, want Alpha first then Beta", overlaps)
+	}
+	if overlaps[0].ACFraction < 0.99 {
+		t.Errorf("Alpha fraction in North = %.3f, want 1", overlaps[0].ACFraction)
+	}
+}
+
+func TestGetBoothsInDistrictBoundary(t *testing.T) {
+	index := newTestIndex(t)
+
+	booths, err := index.GetBoothsInDistrictBoundary("testland", "south")
+	if err != nil {
+		t.Fatalf("GetBoothsInDistrictBoundary() error: %v", err)
+	}
+	// Beta's booth lies in South; Gamma's booth has no coordinates
+	if len(booths) != 1 || booths[0].PartID != 2 {
+		t.Errorf("GetBoothsInDistrictBoundary(south) = %d booths, want Beta's booth only", len(booths))
+	}
+}
@@ -0,0 +1,247 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EditionDateLayout is the date format of editions.json
+const EditionDateLayout = "2006-01-02"
+
+// DefaultEditionName names the single edition of a data directory without editions.json
+const DefaultEditionName = "current"
+
+// Edition errors
+var (
+	ErrEditionNotFound = errors.New("edition not found")
+	ErrInvalidEditions = errors.New("invalid editions")
+)
+
+// Edition is one vintage of the electoral geography, such as the AC map of a
+// delimitation order or a revision of the electoral rolls. An edition is in
+// force from EffectiveFrom (inclusive) until EffectiveTo (exclusive).
+type Edition struct {
+	Name          string
+	Dir           string    // Data directory of the edition, relative to editions.json
+	EffectiveFrom time.Time // Zero if in force since before any other edition
+	EffectiveTo   time.Time // Zero while still in force
+}
+
+// Contains returns true if the edition is in force at t
+func (e Edition) Contains(t time.Time) bool {
+	if !e.EffectiveFrom.IsZero() && t.Before(e.EffectiveFrom) {
+		return false
+	}
+	return e.EffectiveTo.IsZero() || t.Before(e.EffectiveTo)
+}
+
+// Edition returns the edition the index was configured with
+func (g *GeoIndex) Edition() Edition {
+	return g.config.Edition
+}
+
+// editionsFile is the JSON structure for editions.json
+type editionsFile struct {
+	Editions []struct {
+		Name          string `json:"name"`
+		Dir           string `json:"dir"`
+		EffectiveFrom string `json:"effectiveFrom"` // EditionDateLayout, empty for no start
+		EffectiveTo   string `json:"effectiveTo"`   // EditionDateLayout, empty while in force
+	} `json:"editions"`
+}
+
+// LoadEditions loads the editions listed in editions.json, sorted by EffectiveFrom
+func LoadEditions(dataDir string) ([]Edition, error) {
+	return LoadEditionsFS(dirFS(dataDir))
+}
+
+// LoadEditionsFS is like LoadEditions but reads from fsys
+func LoadEditionsFS(fsys fs.FS) ([]Edition, error) {
+	filePath := EditionsFile
+
+	data, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrFileNotFound, filePath)
+		}
+		return nil, err
+	}
+
+	var file editionsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidJSON, filePath, err)
+	}
+
+	editions := make([]Edition, 0, len(file.Editions))
+	for _, entry := range file.Editions {
+		edition := Edition{Name: entry.Name, Dir: path.Clean(entry.Dir)}
+		if entry.EffectiveFrom != "" {
+			if edition.EffectiveFrom, err = time.Parse(EditionDateLayout, entry.EffectiveFrom); err != nil {
+				return nil, fmt.Errorf("%w: edition %q effectiveFrom: %v", ErrInvalidEditions, entry.Name, err)
+			}
+		}
+		if entry.EffectiveTo != "" {
+			if edition.EffectiveTo, err = time.Parse(EditionDateLayout, entry.EffectiveTo); err != nil {
+				return nil, fmt.Errorf("%w: edition %q effectiveTo: %v", ErrInvalidEditions, entry.Name, err)
+			}
+		}
+		editions = append(editions, edition)
+	}
+
+	if err := validateEditions(editions); err != nil {
+		return nil, err
+	}
+	return editions, nil
+}
+
+// validateEditions sorts editions by EffectiveFrom and checks that names are
+// unique and periods do not overlap
+func validateEditions(editions []Edition) error {
+	if len(editions) == 0 {
+		return fmt.Errorf("%w: no editions listed", ErrInvalidEditions)
+	}
+
+	sort.Slice(editions, func(i, j int) bool {
+		return editions[i].EffectiveFrom.Before(editions[j].EffectiveFrom)
+	})
+
+	names := make(map[string]bool, len(editions))
+	for i, edition := range editions {
+		if edition.Name == "" {
+			return fmt.Errorf("%w: edition %d has no name", ErrInvalidEditions, i)
+		}
+		if names[edition.Name] {
+			return fmt.Errorf("%w: duplicate edition %q", ErrInvalidEditions, edition.Name)
+		}
+		names[edition.Name] = true
+
+		if !edition.EffectiveTo.IsZero() && !edition.EffectiveFrom.Before(edition.EffectiveTo) {
+			return fmt.Errorf("%w: edition %q ends before it starts", ErrInvalidEditions, edition.Name)
+		}
+		if i > 0 {
+			previous := editions[i-1]
+			if previous.EffectiveTo.IsZero() || previous.EffectiveTo.After(edition.EffectiveFrom) {
+				return fmt.Errorf("%w: editions %q and %q overlap", ErrInvalidEditions, previous.Name, edition.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// EditionIndex holds a GeoIndex per edition of the data, so that historical
+// records such as poll results resolve against the constituencies and booths
+// in force at the time. Each edition lives in its own data directory, listed
+// in editions.json at the root:
+//
+//	{"editions": [
+//	  {"name": "delimitation_2008", "dir": "editions/2008", "effectiveFrom": "2008-02-19", "effectiveTo": "2026-04-01"},
+//	  {"name": "delimitation_2026", "dir": "editions/2026", "effectiveFrom": "2026-04-01"}]}
+//
+// Without editions.json the root is a single edition in force at all times.
+type EditionIndex struct {
+	fsys   fs.FS
+	config GeoIndexConfig
+
+	editions []Edition            // sorted by EffectiveFrom
+	indexes  map[string]*GeoIndex // edition name -> index
+
+	mu sync.RWMutex
+}
+
+// NewEditionIndex creates an edition index from the given data directory
+func NewEditionIndex(dataDir string) *EditionIndex {
+	return NewEditionIndexFSWithConfig(dirFS(dataDir), DefaultGeoIndexConfig())
+}
+
+// NewEditionIndexFS creates an edition index that reads from a file system
+func NewEditionIndexFS(fsys fs.FS) *EditionIndex {
+	return NewEditionIndexFSWithConfig(fsys, DefaultGeoIndexConfig())
+}
+
+// NewEditionIndexFSWithConfig creates an edition index whose per-edition
+// indexes use config. config.Edition is set per edition.
+func NewEditionIndexFSWithConfig(fsys fs.FS, config GeoIndexConfig) *EditionIndex {
+	return &EditionIndex{
+		fsys:    fsys,
+		config:  config,
+		indexes: make(map[string]*GeoIndex),
+	}
+}
+
+// LoadAll reads editions.json and loads every edition's index
+func (e *EditionIndex) LoadAll() error {
+	editions, err := LoadEditionsFS(e.fsys)
+	if errors.Is(err, ErrFileNotFound) {
+		editions = []Edition{{Name: DefaultEditionName, Dir: "."}}
+	} else if err != nil {
+		return err
+	}
+
+	indexes := make(map[string]*GeoIndex, len(editions))
+	for _, edition := range editions {
+		fsys, err := fs.Sub(e.fsys, edition.Dir)
+		if err != nil {
+			return fmt.Errorf("%w: edition %q: %v", ErrInvalidEditions, edition.Name, err)
+		}
+
+		config := e.config
+		config.Edition = edition
+		index := NewGeoIndexFSWithConfig(fsys, config)
+		if err := index.LoadAll(); err != nil {
+			return fmt.Errorf("loading edition %s: %w", edition.Name, err)
+		}
+		indexes[edition.Name] = index
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.editions = editions
+	e.indexes = indexes
+	return nil
+}
+
+// Editions returns the loaded editions sorted by EffectiveFrom
+func (e *EditionIndex) Editions() []Edition {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return append([]Edition(nil), e.editions...)
+}
+
+// Get returns the index of an edition by name
+func (e *EditionIndex) Get(name string) (*GeoIndex, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	index, ok := e.indexes[name]
+	return index, ok
+}
+
+// AsOf returns the index of the edition in force at t
+func (e *EditionIndex) AsOf(t time.Time) (*GeoIndex, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, edition := range e.editions {
+		if edition.Contains(t) {
+			return e.indexes[edition.Name], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no edition in force on %s", ErrEditionNotFound, t.Format(EditionDateLayout))
+}
+
+// Latest returns the index of the most recent edition
+func (e *EditionIndex) Latest() (*GeoIndex, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if len(e.editions) == 0 {
+		return nil, fmt.Errorf("%w: no editions loaded", ErrEditionNotFound)
+	}
+	return e.indexes[e.editions[len(e.editions)-1].Name], nil
+}
@@ -0,0 +1,169 @@
+package data
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testEditionFiles returns a data directory with two editions of the test
+// data. In the later edition Alpha is renamed Alpha Nagar and its booth renumbered.
+func testEditionFiles() map[string]string {
+	files := map[string]string{
+		EditionsFile: `{"editions": [
+			{"name": "delimitation_2026", "dir": "editions/2026", "effectiveFrom": "2026-04-01"},
+			{"name": "delimitation_2008", "dir": "editions/2008", "effectiveFrom": "2008-02-19", "effectiveTo": "2026-04-01"}]}`,
+	}
+	for name, content := range testDataFiles() {
+		files[filepath.Join("editions", "2008", name)] = content
+
+		content = strings.ReplaceAll(content, `"Alpha"`, `"Alpha Nagar"`)
+		content = strings.Replace(content, `"partNumber": 1, "partName": "Govt School, Alpha"`,
+			`"partNumber": 7, "partName": "Govt School, Alpha"`, 1)
+		files[filepath.Join("editions", "2026", name)] = content
+	}
+	return files
+}
+
+// testDate parses a date in EditionDateLayout
+func testDate(s string) time.Time {
+	t, err := time.Parse(EditionDateLayout, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestEditionContains(t *testing.T) {
+	edition := Edition{Name: "e", EffectiveFrom: testDate("2008-02-19"), EffectiveTo: testDate("2026-04-01")}
+
+	tests := []struct {
+		at   string
+		want bool
+	}{
+		{"2008-02-18", false},
+		{"2008-02-19", true},
+		{"2026-03-31", true},
+		{"2026-04-01", false},
+	}
+	for _, tt := range tests {
+		if got := edition.Contains(testDate(tt.at)); got != tt.want {
+			t.Errorf("Contains(%s) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+
+	if !(Edition{}).Contains(testDate("1950-01-26")) {
+		t.Error("unbounded edition should contain every date")
+	}
+}
+
+func TestLoadEditions(t *testing.T) {
+	editions, err := LoadEditions(writeTestData(t, testEditionFiles()))
+	if err != nil {
+		t.Fatalf("LoadEditions() error: %v", err)
+	}
+
+	if len(editions) != 2 || editions[0].Name != "delimitation_2008" || editions[1].Name != "delimitation_2026" {
+		t.Fatalf("editions = %+v, want 2008 then 2026", editions)
+	}
+	if editions[0].Dir != "editions/2008" || !editions[1].EffectiveTo.IsZero() {
+		t.Errorf("editions = %+v", editions)
+	}
+}
+
+func TestLoadEditionsInvalid(t *testing.T) {
+	tests := map[string]string{
+		"overlap": `{"editions": [
+			{"name": "a", "dir": "a", "effectiveFrom": "2008-01-01", "effectiveTo": "2020-01-01"},
+			{"name": "b", "dir": "b", "effectiveFrom": "2019-01-01"}]}`,
+		"open ended before another": `{"editions": [
+			{"name": "a", "dir": "a", "effectiveFrom": "2008-01-01"},
+			{"name": "b", "dir": "b", "effectiveFrom": "2019-01-01"}]}`,
+		"duplicate": `{"editions": [{"name": "a", "dir": "a", "effectiveTo": "2008-01-01"}, {"name": "a", "dir": "b", "effectiveFrom": "2008-01-01"}]}`,
+		"bad date":  `{"editions": [{"name": "a", "dir": "a", "effectiveFrom": "19/02/2008"}]}`,
+		"reversed":  `{"editions": [{"name": "a", "dir": "a", "effectiveFrom": "2020-01-01", "effectiveTo": "2008-01-01"}]}`,
+		"empty":     `{"editions": []}`,
+		"unnamed":   `{"editions": [{"dir": "a"}]}`,
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadEditions(writeTestData(t, map[string]string{EditionsFile: content}))
+			if !errors.Is(err, ErrInvalidEditions) {
+				t.Errorf("LoadEditions() error = %v, want ErrInvalidEditions", err)
+			}
+		})
+	}
+}
+
+func TestEditionIndexAsOf(t *testing.T) {
+	editions := NewEditionIndex(writeTestData(t, testEditionFiles()))
+	if err := editions.LoadAll(); err != nil {
+		t.Fatalf("LoadAll() error: %v", err)
+	}
+
+	tests := []struct {
+		at          string
+		wantEdition string
+		wantName    string
+		wantPart    int
+	}{
+		{"2019-05-23", "delimitation_2008", "Alpha", 1},
+		{"2026-03-31", "delimitation_2008", "Alpha", 1},
+		{"2026-04-01", "delimitation_2026", "Alpha Nagar", 7},
+	}
+
+	for _, tt := range tests {
+		index, err := editions.AsOf(testDate(tt.at))
+		if err != nil {
+			t.Fatalf("AsOf(%s) error: %v", tt.at, err)
+		}
+		if index.Edition().Name != tt.wantEdition {
+			t.Errorf("AsOf(%s) edition = %s, want %s", tt.at, index.Edition().Name, tt.wantEdition)
+		}
+
+		ac, ok := index.GetACByNumber("testland", 1)
+		if !ok || ac.Name != tt.wantName || ac.Edition != tt.wantEdition {
+			t.Errorf("AsOf(%s) AC 1 = %+v, want %s from %s", tt.at, ac, tt.wantName, tt.wantEdition)
+		}
+
+		boundary, err := index.FindACAtPoint("testland", 12.5, 77.5)
+		if err != nil || boundary.ConsName != tt.wantName || boundary.Edition != tt.wantEdition {
+			t.Errorf("AsOf(%s) FindACAtPoint() = %+v, %v", tt.at, boundary, err)
+		}
+
+		booth, err := index.GetBooth("testland", 1, 1)
+		if err != nil || booth.PartNumber != tt.wantPart || booth.Edition != tt.wantEdition {
+			t.Errorf("AsOf(%s) GetBooth() = %+v, %v", tt.at, booth, err)
+		}
+	}
+
+	if _, err := editions.AsOf(testDate("2000-01-01")); !errors.Is(err, ErrEditionNotFound) {
+		t.Errorf("AsOf() before first edition error = %v, want ErrEditionNotFound", err)
+	}
+
+	latest, err := editions.Latest()
+	if err != nil || latest.Edition().Name != "delimitation_2026" {
+		t.Errorf("Latest() = %v, %v", latest, err)
+	}
+	if _, ok := editions.Get("delimitation_2008"); !ok {
+		t.Error("Get(delimitation_2008) not found")
+	}
+}
+
+func TestEditionIndexWithoutEditionsFile(t *testing.T) {
+	editions := NewEditionIndex(writeTestData(t, testDataFiles()))
+	if err := editions.LoadAll(); err != nil {
+		t.Fatalf("LoadAll() error: %v", err)
+	}
+
+	index, err := editions.AsOf(testDate("1952-01-25"))
+	if err != nil {
+		t.Fatalf("AsOf() error: %v", err)
+	}
+	if ac, ok := index.GetACByNumber("testland", 1); !ok || ac.Edition != DefaultEditionName {
+		t.Errorf("AC 1 = %+v, want edition %s", ac, DefaultEditionName)
+	}
+}
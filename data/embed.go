@@ -0,0 +1,18 @@
+//go:build embeddata
+
+package data
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// embeddedData holds the data directory, compiled in with -tags embeddata
+//
+//go:embed *.json boundaries booths
+var embeddedData embed.FS
+
+// EmbeddedData returns the data files compiled into the binary, for use with NewGeoIndexFS
+func EmbeddedData() (fs.FS, error) {
+	return embeddedData, nil
+}
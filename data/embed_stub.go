@@ -0,0 +1,15 @@
+//go:build !embeddata
+
+package data
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// EmbeddedData returns the data files compiled into the binary, for use with
+// NewGeoIndexFS. The data (~350 MB) is only embedded when building with
+// -tags embeddata; without the tag it returns ErrDataDirNotFound.
+func EmbeddedData() (fs.FS, error) {
+	return nil, fmt.Errorf("%w: built without the embeddata tag", ErrDataDirNotFound)
+}
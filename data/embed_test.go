@@ -0,0 +1,20 @@
+//go:build embeddata
+
+package data
+
+import "testing"
+
+func TestEmbeddedData(t *testing.T) {
+	fsys, err := EmbeddedData()
+	if err != nil {
+		t.Fatalf("EmbeddedData() error: %v", err)
+	}
+
+	index := NewGeoIndexFS(fsys)
+	if err := index.LoadAll(); err != nil {
+		t.Fatalf("LoadAll() error: %v", err)
+	}
+	if _, err := index.FindACAtPoint("goa", 15.4909, 73.8278); err != nil {
+		t.Errorf("FindACAtPoint() error: %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package data
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// emptyExtent is the extent of a state with no located data. It intersects
+// no box, so the state is always ruled out.
+var emptyExtent = [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+
+// extendExtent grows a [minLng, minLat, maxLng, maxLat] extent to include a point
+func extendExtent(extent *[4]float64, lat, lng float64) {
+	extent[0] = math.Min(extent[0], lng)
+	extent[1] = math.Min(extent[1], lat)
+	extent[2] = math.Max(extent[2], lng)
+	extent[3] = math.Max(extent[3], lat)
+}
+
+// boundaryExtent returns the bounding box of a state's AC boundaries. It is
+// recorded when the boundaries are indexed and kept when they are evicted;
+// before that it is scanned from the boundary file without decoding the
+// geometries, so states can be ruled out without loading them.
+func (g *GeoIndex) boundaryExtent(stateSlug string) ([4]float64, error) {
+	return g.extent(func() map[string][4]float64 { return g.boundaryExtents }, stateSlug, scanBoundaryExtentFS)
+}
+
+// boothExtent returns the bounding box of a state's located booths, kept and
+// scanned like boundaryExtent
+func (g *GeoIndex) boothExtent(stateSlug string) ([4]float64, error) {
+	return g.extent(func() map[string][4]float64 { return g.boothExtents }, stateSlug, scanBoothExtentFS)
+}
+
+// extent returns a state's entry in extents, scanning it from the data files
+// if it is not known yet
+func (g *GeoIndex) extent(extents func() map[string][4]float64, stateSlug string, scan func(fs.FS, string) ([4]float64, error)) ([4]float64, error) {
+	g.mu.RLock()
+	extent, ok := extents()[stateSlug]
+	g.mu.RUnlock()
+	if ok {
+		return extent, nil
+	}
+
+	extent, err := scan(g.fsys, stateSlug)
+	if err != nil {
+		return emptyExtent, err
+	}
+
+	g.mu.Lock()
+	extents()[stateSlug] = extent
+	g.mu.Unlock()
+	return extent, nil
+}
+
+// boothsExtent returns the bounding box of the booths that have a location
+func boothsExtent(booths []PollingBooth) [4]float64 {
+	extent := emptyExtent
+	for i := range booths {
+		if booths[i].Lat != nil && booths[i].Lon != nil {
+			extendExtent(&extent, *booths[i].Lat, *booths[i].Lon)
+		}
+	}
+	return extent
+}
+
+// scanBoundaryExtentFS returns the bounding box of every position in a
+// state's boundary file
+func scanBoundaryExtentFS(fsys fs.FS, stateSlug string) ([4]float64, error) {
+	data, err := fs.ReadFile(fsys, path.Join(BoundariesDir, FromSlug(stateSlug)+".geojson"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return emptyExtent, fmt.Errorf("%w: %s", ErrStateNotFound, stateSlug)
+		}
+		return emptyExtent, err
+	}
+	return scanCoordinatesExtent(data), nil
+}
+
+// scanCoordinatesExtent returns the bounding box of the positions in every
+// "coordinates" array of a GeoJSON document. It only tracks array nesting
+// and the index of each number within its innermost array, [lng, lat, ...],
+// which is an order of magnitude faster than decoding the geometries.
+func scanCoordinatesExtent(data []byte) [4]float64 {
+	extent := emptyExtent
+	key := []byte(`"coordinates"`)
+	for {
+		i := bytes.Index(data, key)
+		if i < 0 {
+			return extent
+		}
+		data = data[i+len(key):]
+
+		// Skip to the opening bracket; anything else, such as null, has no positions
+		j := 0
+		for j < len(data) && (data[j] == ':' || isJSONSpace(data[j])) {
+			j++
+		}
+		if j == len(data) || data[j] != '[' {
+			continue
+		}
+
+		depth, index := 0, 0
+		var lng float64
+	scan:
+		for ; j < len(data); j++ {
+			switch c := data[j]; {
+			case c == '[':
+				depth++
+				index = 0
+			case c == ']':
+				depth--
+				if depth == 0 {
+					break scan
+				}
+			case c == ',':
+				index++
+			case c == '-' || (c >= '0' && c <= '9'):
+				end := j + 1
+				for end < len(data) && isJSONNumberByte(data[end]) {
+					end++
+				}
+				value, err := strconv.ParseFloat(string(data[j:end]), 64)
+				j = end - 1
+				if err != nil {
+					continue
+				}
+				switch index {
+				case 0:
+					lng = value
+				case 1:
+					extendExtent(&extent, value, lng)
+				}
+			}
+		}
+		data = data[j:]
+	}
+}
+
+// scanBoothExtentFS returns the bounding box of the booth coordinates in a
+// state's booth files
+func scanBoothExtentFS(fsys fs.FS, stateSlug string) ([4]float64, error) {
+	boothsDir := path.Join(BoothsDir, boothDirForStateSlug(stateSlug))
+	entries, err := fs.ReadDir(fsys, boothsDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return emptyExtent, fmt.Errorf("%w: %s", ErrStateNotFound, stateSlug)
+		}
+		return emptyExtent, err
+	}
+
+	// Latitudes and longitudes are scanned separately, so a booth with only
+	// one coordinate widens the extent, which is harmless
+	extent := emptyExtent
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(boothsDir, entry.Name()))
+		if err != nil {
+			return emptyExtent, err
+		}
+		scanJSONNumbers(data, `"lat"`, func(lat float64) {
+			extent[1] = math.Min(extent[1], lat)
+			extent[3] = math.Max(extent[3], lat)
+		})
+		scanJSONNumbers(data, `"lon"`, func(lng float64) {
+			extent[0] = math.Min(extent[0], lng)
+			extent[2] = math.Max(extent[2], lng)
+		})
+	}
+
+	// Without both a latitude and a longitude no booth is located
+	if extent[0] > extent[2] || extent[1] > extent[3] {
+		return emptyExtent, nil
+	}
+	return extent, nil
+}
+
+// scanJSONNumbers calls fn with the number value of every occurrence of key
+func scanJSONNumbers(data []byte, key string, fn func(float64)) {
+	for {
+		i := bytes.Index(data, []byte(key))
+		if i < 0 {
+			return
+		}
+		data = data[i+len(key):]
+
+		j := 0
+		for j < len(data) && (data[j] == ':' || isJSONSpace(data[j])) {
+			j++
+		}
+		end := j
+		for end < len(data) && isJSONNumberByte(data[end]) {
+			end++
+		}
+		if value, err := strconv.ParseFloat(string(data[j:end]), 64); err == nil {
+			fn(value)
+		}
+		data = data[end:]
+	}
+}
+
+// isJSONSpace reports whether c is JSON whitespace
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// isJSONNumberByte reports whether c can continue a JSON number
+func isJSONNumberByte(c byte) bool {
+	return (c >= '0' && c <= '9') || c == '.' || c == 'e' || c == 'E' || c == '+' || c == '-'
+}
+
+// extentNear reports whether an extent comes within radiusM metres of a point
+func extentNear(extent [4]float64, lat, lng, radiusM float64) bool {
+	return bboxIntersects(extent, radiusBBox(lat, lng, radiusM))
+}
@@ -0,0 +1,30 @@
+package data
+
+import (
+	"testing"
+)
+
+func TestScanCoordinatesExtent(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want [4]float64
+	}{
+		{"polygon", `{"geometry": {"type": "Polygon", "coordinates": [[[77, 12], [78.5, 12], [78.5, 13.25], [77, 12]]]}}`,
+			[4]float64{77, 12, 78.5, 13.25}},
+		{"multipolygon with elevation", `{"coordinates" : [[[[-1.5e1, 2, 100], [3, -4, 100]]], [[[5, 6]]]]}`,
+			[4]float64{-15, -4, 5, 6}},
+		{"several features", `[{"coordinates": [[[1, 2]]]}, {"coordinates": null}, {"coordinates": [[[3, 4]]]}]`,
+			[4]float64{1, 2, 3, 4}},
+		{"no coordinates", `{"type": "FeatureCollection", "features": []}`, emptyExtent},
+	}
+	for _, tt := range tests {
+		if got := scanCoordinatesExtent([]byte(tt.data)); got != tt.want {
+			t.Errorf("%s: scanCoordinatesExtent() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if extentNear(emptyExtent, 0, 0, 1e7) {
+		t.Error("empty extent is near a point")
+	}
+}
@@ -0,0 +1,329 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	h3utils "github.com/politic-in/core/h3-utils"
+)
+
+// ErrGeoJSONWriterClosed is returned when writing to a closed GeoJSONWriter
+var ErrGeoJSONWriterClosed = errors.New("GeoJSON writer closed")
+
+// GeoJSONConfig configures GeoJSON output
+type GeoJSONConfig struct {
+	// BoundaryProperties and BoothProperties return the properties of each
+	// feature. nil uses DefaultBoundaryProperties and DefaultBoothProperties.
+	BoundaryProperties func(*ACBoundary) map[string]any
+	BoothProperties    func(*PollingBooth) map[string]any
+
+	// Precision is the number of decimal places kept in coordinates, with
+	// trailing zeros dropped. 6 places is about 0.1 m. 0 keeps full precision.
+	Precision int
+}
+
+// DefaultGeoJSONConfig returns the default GeoJSON configuration
+func DefaultGeoJSONConfig() GeoJSONConfig {
+	return GeoJSONConfig{
+		Precision: 6,
+	}
+}
+
+// DefaultBoundaryProperties returns the properties of the source boundary
+// files, so exported boundaries can be loaded again
+func DefaultBoundaryProperties(boundary *ACBoundary) map[string]any {
+	return map[string]any{
+		"objectid":  boundary.ObjectID,
+		"uid":       boundary.UID,
+		"state_ut":  boundary.StateUT,
+		"cons_code": boundary.ConsCode,
+		"cons_name": boundary.ConsName,
+	}
+}
+
+// DefaultBoothProperties returns the fields of the source booth files
+func DefaultBoothProperties(booth *PollingBooth) map[string]any {
+	return map[string]any{
+		"partId":       booth.PartID,
+		"stateCode":    booth.StateCode,
+		"stateName":    booth.StateName,
+		"districtCode": booth.DistrictCode,
+		"districtName": booth.DistrictName,
+		"acNumber":     booth.ACNumber,
+		"acName":       booth.ACName,
+		"partNumber":   booth.PartNumber,
+		"partName":     booth.PartName,
+	}
+}
+
+// GeoJSONWriter streams features into an RFC 7946 FeatureCollection, so a
+// whole state can be exported without building the document in memory.
+// Close must be called to finish the collection.
+type GeoJSONWriter struct {
+	w      *bufio.Writer
+	config GeoJSONConfig
+	buf    []byte // Feature being encoded
+
+	count  int
+	closed bool
+	err    error // First write error, returned by every later call
+}
+
+// NewGeoJSONWriter creates a writer that streams a FeatureCollection to w
+func NewGeoJSONWriter(w io.Writer, config GeoJSONConfig) *GeoJSONWriter {
+	if config.BoundaryProperties == nil {
+		config.BoundaryProperties = DefaultBoundaryProperties
+	}
+	if config.BoothProperties == nil {
+		config.BoothProperties = DefaultBoothProperties
+	}
+
+	return &GeoJSONWriter{
+		w:      bufio.NewWriter(w),
+		config: config,
+	}
+}
+
+// Count returns the number of features written
+func (gw *GeoJSONWriter) Count() int {
+	return gw.count
+}
+
+// WriteBoundary writes an AC boundary as a Polygon, or a MultiPolygon if it has several parts
+func (gw *GeoJSONWriter) WriteBoundary(boundary *ACBoundary) error {
+	polygons := boundary.GetPolygons()
+	if len(polygons) == 1 {
+		return gw.writeFeature("Polygon", func(buf []byte) []byte {
+			return gw.appendPolygon(buf, polygons[0])
+		}, gw.config.BoundaryProperties(boundary))
+	}
+
+	return gw.writeFeature("MultiPolygon", func(buf []byte) []byte {
+		return gw.appendMultiPolygon(buf, polygons)
+	}, gw.config.BoundaryProperties(boundary))
+}
+
+// WriteBooth writes a booth as a Point. Booths without coordinates are
+// written with a null geometry.
+func (gw *GeoJSONWriter) WriteBooth(booth *PollingBooth) error {
+	if booth.Lat == nil || booth.Lon == nil {
+		return gw.writeFeature("", nil, gw.config.BoothProperties(booth))
+	}
+
+	return gw.writeFeature("Point", func(buf []byte) []byte {
+		return gw.appendPosition(buf, *booth.Lon, *booth.Lat)
+	}, gw.config.BoothProperties(booth))
+}
+
+// WriteCells writes a set of H3 cells as one MultiPolygon outlining their
+// union, with holes where the cells surround an area they leave out
+func (gw *GeoJSONWriter) WriteCells(cellIDs []string, properties map[string]any) error {
+	polygons, err := h3utils.CellsToPolygons(cellIDs)
+	if err != nil {
+		return err
+	}
+
+	return gw.writeFeature("MultiPolygon", func(buf []byte) []byte {
+		return gw.appendMultiPolygon(buf, polygons)
+	}, properties)
+}
+
+// Close finishes the FeatureCollection and flushes it. It does not close the
+// underlying writer.
+func (gw *GeoJSONWriter) Close() error {
+	if gw.closed {
+		return gw.err
+	}
+	gw.closed = true
+
+	if gw.err != nil {
+		return gw.err
+	}
+	if gw.count == 0 {
+		gw.writeString(`{"type":"FeatureCollection","features":[`)
+	}
+	gw.writeString("]}\n")
+	if gw.err == nil {
+		gw.err = gw.w.Flush()
+	}
+	return gw.err
+}
+
+// writeFeature writes one feature. appendCoordinates appends the geometry's
+// coordinates; an empty geometryType writes a null geometry.
+func (gw *GeoJSONWriter) writeFeature(geometryType string, appendCoordinates func([]byte) []byte, properties map[string]any) error {
+	if gw.closed {
+		return ErrGeoJSONWriterClosed
+	}
+	if gw.err != nil {
+		return gw.err
+	}
+
+	props := []byte("null")
+	if properties != nil {
+		var err error
+		if props, err = json.Marshal(properties); err != nil {
+			return fmt.Errorf("encoding GeoJSON properties: %w", err)
+		}
+	}
+
+	buf := gw.buf[:0]
+	if gw.count == 0 {
+		buf = append(buf, `{"type":"FeatureCollection","features":[`...)
+	} else {
+		buf = append(buf, ',')
+	}
+	buf = append(buf, "\n"+`{"type":"Feature","geometry":`...)
+	if geometryType == "" {
+		buf = append(buf, "null"...)
+	} else {
+		buf = append(buf, `{"type":`...)
+		buf = strconv.AppendQuote(buf, geometryType)
+		buf = append(buf, `,"coordinates":`...)
+		buf = appendCoordinates(buf)
+		buf = append(buf, '}')
+	}
+	buf = append(buf, `,"properties":`...)
+	buf = append(buf, props...)
+	buf = append(buf, '}')
+	gw.buf = buf
+
+	if _, err := gw.w.Write(buf); err != nil {
+		gw.err = err
+		return err
+	}
+	gw.count++
+	return nil
+}
+
+// writeString writes s, recording the first error
+func (gw *GeoJSONWriter) writeString(s string) {
+	if gw.err == nil {
+		_, gw.err = gw.w.WriteString(s)
+	}
+}
+
+// appendMultiPolygon appends MultiPolygon coordinates
+func (gw *GeoJSONWriter) appendMultiPolygon(buf []byte, polygons [][][][]float64) []byte {
+	buf = append(buf, '[')
+	for i, polygon := range polygons {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = gw.appendPolygon(buf, polygon)
+	}
+	return append(buf, ']')
+}
+
+// appendPolygon appends Polygon coordinates, closing rings that are left open
+func (gw *GeoJSONWriter) appendPolygon(buf []byte, polygon [][][]float64) []byte {
+	buf = append(buf, '[')
+	for i, ring := range polygon {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, '[')
+		for j, pt := range ring {
+			if j > 0 {
+				buf = append(buf, ',')
+			}
+			buf = gw.appendPosition(buf, pt[0], pt[1])
+		}
+		if len(ring) > 0 && !ringClosed(ring) {
+			buf = append(buf, ',')
+			buf = gw.appendPosition(buf, ring[0][0], ring[0][1])
+		}
+		buf = append(buf, ']')
+	}
+	return append(buf, ']')
+}
+
+// appendPosition appends a [lng, lat] position
+func (gw *GeoJSONWriter) appendPosition(buf []byte, lng, lat float64) []byte {
+	buf = append(buf, '[')
+	buf = gw.appendCoordinate(buf, lng)
+	buf = append(buf, ',')
+	buf = gw.appendCoordinate(buf, lat)
+	return append(buf, ']')
+}
+
+// appendCoordinate appends a coordinate rounded to the configured precision
+func (gw *GeoJSONWriter) appendCoordinate(buf []byte, v float64) []byte {
+	if gw.config.Precision <= 0 {
+		return strconv.AppendFloat(buf, v, 'f', -1, 64)
+	}
+
+	start := len(buf)
+	buf = strconv.AppendFloat(buf, v, 'f', gw.config.Precision, 64)
+	if bytes.IndexByte(buf[start:], '.') >= 0 {
+		buf = bytes.TrimRight(buf, "0")
+		buf = bytes.TrimSuffix(buf, []byte("."))
+	}
+	if string(buf[start:]) == "-0" {
+		buf = append(buf[:start], '0')
+	}
+	return buf
+}
+
+// ringClosed returns true if a ring's last point repeats its first
+func ringClosed(ring [][]float64) bool {
+	first, last := ring[0], ring[len(ring)-1]
+	return first[0] == last[0] && first[1] == last[1]
+}
+
+// WriteBoundariesGeoJSON writes every AC boundary of a state to gw
+func (g *GeoIndex) WriteBoundariesGeoJSON(gw *GeoJSONWriter, stateSlug string) error {
+	boundaries, err := g.GetBoundariesForState(stateSlug)
+	if err != nil {
+		return err
+	}
+
+	for _, boundary := range boundaries {
+		if err := gw.WriteBoundary(boundary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteBoothsGeoJSON writes every booth of a state to gw
+func (g *GeoIndex) WriteBoothsGeoJSON(gw *GeoJSONWriter, stateSlug string) error {
+	booths, err := g.GetBoothsForState(stateSlug)
+	if err != nil {
+		return err
+	}
+
+	for _, booth := range booths {
+		if err := gw.WriteBooth(booth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteACCellsGeoJSON writes the H3 cells covering an AC, as returned by
+// GetH3CellsForAC, to gw as one feature
+func (g *GeoIndex) WriteACCellsGeoJSON(gw *GeoJSONWriter, stateSlug string, consCode, resolution int) error {
+	cells, err := g.GetH3CellsForAC(stateSlug, consCode, resolution)
+	if err != nil {
+		return err
+	}
+
+	boundary, err := g.GetBoundaryForAC(stateSlug, consCode)
+	if err != nil {
+		return err
+	}
+
+	return gw.WriteCells(cells, map[string]any{
+		"state":      stateSlug,
+		"cons_code":  consCode,
+		"cons_name":  boundary.ConsName,
+		"resolution": resolution,
+		"cell_count": len(cells),
+	})
+}
@@ -0,0 +1,215 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	h3utils "github.com/politic-in/core/h3-utils"
+)
+
+// testFeatureCollection is a decoded GeoJSON FeatureCollection
+type testFeatureCollection struct {
+	Type     string `json:"type"`
+	Features []struct {
+		Type     string `json:"type"`
+		Geometry *struct {
+			Type        string          `json:"type"`
+			Coordinates json.RawMessage `json:"coordinates"`
+		} `json:"geometry"`
+		Properties map[string]any `json:"properties"`
+	} `json:"features"`
+}
+
+// decodeTestCollection decodes a FeatureCollection written by a GeoJSONWriter
+func decodeTestCollection(t *testing.T, data []byte) testFeatureCollection {
+	t.Helper()
+	var collection testFeatureCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	if collection.Type != "FeatureCollection" {
+		t.Fatalf("type = %q, want FeatureCollection", collection.Type)
+	}
+	return collection
+}
+
+func TestWriteBoundariesGeoJSONRoundTrip(t *testing.T) {
+	index := newTestIndex(t)
+
+	var buf bytes.Buffer
+	gw := NewGeoJSONWriter(&buf, DefaultGeoJSONConfig())
+	if err := index.WriteBoundariesGeoJSON(gw, "testland"); err != nil {
+		t.Fatalf("WriteBoundariesGeoJSON() error: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if gw.Count() != 3 {
+		t.Errorf("Count() = %d, want 3", gw.Count())
+	}
+
+	// The default properties match the source files, so the output loads as boundaries
+	fsys := fstest.MapFS{path.Join(BoundariesDir, "testland.geojson"): &fstest.MapFile{Data: buf.Bytes()}}
+	boundaries, err := LoadBoundariesForStateFS(fsys, "testland")
+	if err != nil {
+		t.Fatalf("loading exported boundaries: %v", err)
+	}
+	if len(boundaries) != 3 || boundaries[1].ConsCode != 2 || boundaries[1].ConsName != "Beta" {
+		t.Fatalf("exported boundaries = %+v", boundaries)
+	}
+	if !boundaries[1].ContainsPoint(12.5, 78.5) || boundaries[1].ContainsPoint(12.5, 77.5) {
+		t.Error("exported Beta polygon does not match the source")
+	}
+}
+
+func TestWriteBoothsGeoJSON(t *testing.T) {
+	index := newTestIndex(t)
+
+	var buf bytes.Buffer
+	config := DefaultGeoJSONConfig()
+	config.BoothProperties = func(booth *PollingBooth) map[string]any {
+		return map[string]any{"name": booth.FullName()}
+	}
+	gw := NewGeoJSONWriter(&buf, config)
+	if err := index.WriteBoothsGeoJSON(gw, "testland"); err != nil {
+		t.Fatalf("WriteBoothsGeoJSON() error: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	collection := decodeTestCollection(t, buf.Bytes())
+	if len(collection.Features) != 3 {
+		t.Fatalf("got %d features, want 3", len(collection.Features))
+	}
+
+	located := 0
+	for _, feature := range collection.Features {
+		if feature.Type != "Feature" || len(feature.Properties) != 1 || feature.Properties["name"] == "" {
+			t.Errorf("feature = %+v, want custom properties only", feature)
+		}
+		if feature.Geometry == nil {
+			if feature.Properties["name"] != "1 - Community Hall, Gamma" {
+				t.Errorf("null geometry for %v, want only the unlocated booth", feature.Properties["name"])
+			}
+			continue
+		}
+		located++
+		if feature.Geometry.Type != "Point" {
+			t.Errorf("geometry type = %s, want Point", feature.Geometry.Type)
+		}
+	}
+	if located != 2 {
+		t.Errorf("located booths = %d, want 2", located)
+	}
+	if !strings.Contains(buf.String(), `"coordinates":[77.5,12.5]`) {
+		t.Errorf("coordinates not written as [lng,lat] with trailing zeros dropped:\n%s", buf.String())
+	}
+}
+
+func TestWriteACCellsGeoJSON(t *testing.T) {
+	index := newTestIndex(t)
+
+	var buf bytes.Buffer
+	gw := NewGeoJSONWriter(&buf, DefaultGeoJSONConfig())
+	if err := index.WriteACCellsGeoJSON(gw, "testland", 1, 5); err != nil {
+		t.Fatalf("WriteACCellsGeoJSON() error: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	collection := decodeTestCollection(t, buf.Bytes())
+	if len(collection.Features) != 1 {
+		t.Fatalf("got %d features, want 1", len(collection.Features))
+	}
+	feature := collection.Features[0]
+	if feature.Geometry.Type != "MultiPolygon" || feature.Properties["cons_name"] != "Alpha" || feature.Properties["cell_count"].(float64) == 0 {
+		t.Errorf("feature = %+v", feature)
+	}
+
+	var polygons [][][][]float64
+	if err := json.Unmarshal(feature.Geometry.Coordinates, &polygons); err != nil || len(polygons) == 0 {
+		t.Fatalf("coordinates = %s, %v", feature.Geometry.Coordinates, err)
+	}
+	for _, polygon := range polygons {
+		for _, ring := range polygon {
+			if len(ring) < 4 || !ringClosed(ring) {
+				t.Errorf("ring of %d points is not closed", len(ring))
+			}
+		}
+	}
+}
+
+func TestWriteCellsKeepsHoles(t *testing.T) {
+	// A ring of cells around a centre cell left out, like a lake
+	centre := h3utils.LatLngToCellAtResolution(12.5, 77.5, 7)
+	cells, err := h3utils.GetRing(centre, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	gw := NewGeoJSONWriter(&buf, DefaultGeoJSONConfig())
+	if err := gw.WriteCells(cells, nil); err != nil {
+		t.Fatalf("WriteCells() error: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	var polygons [][][][]float64
+	if err := json.Unmarshal(decodeTestCollection(t, buf.Bytes()).Features[0].Geometry.Coordinates, &polygons); err != nil {
+		t.Fatal(err)
+	}
+	if len(polygons) != 1 || len(polygons[0]) != 2 {
+		t.Fatalf("got %d polygons, want one with an outer ring and a hole", len(polygons))
+	}
+	if lat, lng, _ := h3utils.CellToLatLng(centre); multiPolygonContains(polygons, lat, lng) {
+		t.Error("outline covers the centre cell left out of the cells")
+	}
+	if lat, lng, _ := h3utils.CellToLatLng(cells[0]); !multiPolygonContains(polygons, lat, lng) {
+		t.Error("outline does not cover a cell of the ring")
+	}
+}
+
+func TestGeoJSONWriterEmptyAndClosed(t *testing.T) {
+	var buf bytes.Buffer
+	gw := NewGeoJSONWriter(&buf, DefaultGeoJSONConfig())
+	if err := gw.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if collection := decodeTestCollection(t, buf.Bytes()); len(collection.Features) != 0 {
+		t.Errorf("empty collection has %d features", len(collection.Features))
+	}
+
+	if err := gw.WriteBooth(&PollingBooth{}); !errors.Is(err, ErrGeoJSONWriterClosed) {
+		t.Errorf("WriteBooth() after Close error = %v, want ErrGeoJSONWriterClosed", err)
+	}
+}
+
+func TestGeoJSONWriterPrecision(t *testing.T) {
+	tests := []struct {
+		precision int
+		value     float64
+		want      string
+	}{
+		{6, 77.1234567, "77.123457"},
+		{6, 78, "78"},
+		{6, 12.5, "12.5"},
+		{6, -0.0000001, "0"},
+		{0, 77.1234567, "77.1234567"},
+	}
+
+	for _, tt := range tests {
+		gw := NewGeoJSONWriter(nil, GeoJSONConfig{Precision: tt.precision})
+		if got := string(gw.appendCoordinate(nil, tt.value)); got != tt.want {
+			t.Errorf("precision %d: appendCoordinate(%v) = %s, want %s", tt.precision, tt.value, got, tt.want)
+		}
+	}
+}
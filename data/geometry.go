@@ -0,0 +1,317 @@
+package data
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+
+	h3utils "github.com/politic-in/core/h3-utils"
+)
+
+// earthRadiusM is the mean Earth radius used for areas
+const earthRadiusM = 6371008.8
+
+// labelPrecisionM is the precision, in metres, of LabelPoint
+const labelPrecisionM = 10
+
+// AreaKm2 returns the area of the boundary on the sphere in km², with holes
+// subtracted
+func (b ACBoundary) AreaKm2() float64 {
+	var total float64
+	for _, polygon := range b.GetPolygons() {
+		total += polygonAreaM2(polygon)
+	}
+	return total / 1e6
+}
+
+// PerimeterKm returns the great-circle length of every ring of the boundary,
+// holes included, in km
+func (b ACBoundary) PerimeterKm() float64 {
+	var total float64
+	for _, polygon := range b.GetPolygons() {
+		for _, ring := range polygon {
+			for i := 1; i < len(ring); i++ {
+				total += h3utils.HaversineDistance(ring[i-1][1], ring[i-1][0], ring[i][1], ring[i][0])
+			}
+			if len(ring) > 1 && !ringClosed(ring) {
+				last := ring[len(ring)-1]
+				total += h3utils.HaversineDistance(last[1], last[0], ring[0][1], ring[0][0])
+			}
+		}
+	}
+	return total / 1000
+}
+
+// Centroid returns the area-weighted centroid of the boundary. It may lie
+// outside a concave or multi-part boundary; use LabelPoint for a point that
+// is always inside.
+func (b ACBoundary) Centroid() (lat, lng float64) {
+	polygons := b.GetPolygons()
+	if len(polygons) == 0 {
+		return 0, 0
+	}
+
+	proj := newLocalProjection(b.BoundingBox())
+	var sumArea, sumX, sumY float64
+	for _, polygon := range polygons {
+		for i, ring := range polygon {
+			area, cx, cy := ringCentroid(proj.ring(ring))
+			area = math.Abs(area)
+			if i > 0 {
+				area = -area // Holes
+			}
+			sumArea += area
+			sumX += area * cx
+			sumY += area * cy
+		}
+	}
+
+	if sumArea == 0 {
+		bbox := b.BoundingBox()
+		return (bbox[1] + bbox[3]) / 2, (bbox[0] + bbox[2]) / 2
+	}
+	return proj.unproject(sumX/sumArea, sumY/sumArea)
+}
+
+// LabelPoint returns the pole of inaccessibility of the boundary's largest
+// part: the interior point farthest from any edge, found to within 10 m with
+// the polylabel algorithm. Unlike Centroid it lies inside the boundary, which
+// makes it the place to put a map label or marker. If the largest part gives
+// no point inside, the other parts are tried largest first.
+func (b ACBoundary) LabelPoint() (lat, lng float64) {
+	polygons := b.GetPolygons()
+	if len(polygons) == 0 {
+		return 0, 0
+	}
+
+	areas := make([]float64, len(polygons))
+	order := make([]int, len(polygons))
+	for i, polygon := range polygons {
+		areas[i], order[i] = polygonAreaM2(polygon), i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return areas[order[i]] > areas[order[j]] })
+
+	for _, i := range order {
+		if lat, lng, ok := polygonLabelPoint(polygons[i]); ok {
+			return lat, lng
+		}
+	}
+
+	// Only degenerate parts: fall back to a vertex of the largest
+	if largest := polygons[order[0]]; len(largest) > 0 && len(largest[0]) > 0 {
+		return largest[0][0][1], largest[0][0][0]
+	}
+	return 0, 0
+}
+
+// polygonLabelPoint returns the pole of inaccessibility of a polygon, and
+// false for ok if it does not lie inside the polygon
+func polygonLabelPoint(polygon [][][]float64) (lat, lng float64, ok bool) {
+	if len(polygon) == 0 {
+		return 0, 0, false
+	}
+
+	proj := newLocalProjection(multiPolygonBoundingBox([][][][]float64{polygon}))
+	rings := make([][][2]float64, len(polygon))
+	for i, ring := range polygon {
+		rings[i] = proj.ring(ring)
+	}
+
+	x, y := polylabel(rings, labelPrecisionM)
+	lat, lng = proj.unproject(x, y)
+	return lat, lng, polygonContains(polygon, lat, lng)
+}
+
+// polygonAreaM2 returns the spherical area of a polygon in m², with holes
+// subtracted. Each ring uses the Chamberlain–Duquette formula, which is exact
+// for rings whose edges run along meridians and parallels.
+func polygonAreaM2(polygon [][][]float64) float64 {
+	var total float64
+	for i, ring := range polygon {
+		area := math.Abs(ringAreaM2(ring))
+		if i > 0 {
+			area = -area
+		}
+		total += area
+	}
+	return math.Max(total, 0)
+}
+
+// ringAreaM2 returns the signed spherical area of a ring in m²
+func ringAreaM2(ring [][]float64) float64 {
+	points := ring
+	if len(points) > 1 && ringClosed(points) {
+		points = points[:len(points)-1]
+	}
+	n := len(points)
+	if n < 3 {
+		return 0
+	}
+
+	var sum float64
+	for i := range points {
+		prev, current, next := points[(i+n-1)%n], points[i], points[(i+1)%n]
+		sum += (next[0] - prev[0]) * math.Pi / 180 * math.Sin(current[1]*math.Pi/180)
+	}
+	return sum * earthRadiusM * earthRadiusM / 2
+}
+
+// localProjection maps [lng, lat] to metres on a plane tangent at the
+// centre of a bounding box. Errors stay well below 0.1% across an AC.
+type localProjection struct {
+	lat0, lng0 float64
+	kx, ky     float64 // metres per degree
+}
+
+// newLocalProjection returns a projection centred on a bounding box
+func newLocalProjection(bbox [4]float64) localProjection {
+	lat0 := (bbox[1] + bbox[3]) / 2
+	ky := earthRadiusM * math.Pi / 180
+	return localProjection{
+		lat0: lat0,
+		lng0: (bbox[0] + bbox[2]) / 2,
+		kx:   ky * math.Cos(lat0*math.Pi/180),
+		ky:   ky,
+	}
+}
+
+// ring projects a ring of [lng, lat] points
+func (p localProjection) ring(ring [][]float64) [][2]float64 {
+	projected := make([][2]float64, len(ring))
+	for i, pt := range ring {
+		projected[i] = [2]float64{(pt[0] - p.lng0) * p.kx, (pt[1] - p.lat0) * p.ky}
+	}
+	return projected
+}
+
+// unproject maps a projected point back to latitude and longitude
+func (p localProjection) unproject(x, y float64) (lat, lng float64) {
+	return p.lat0 + y/p.ky, p.lng0 + x/p.kx
+}
+
+// ringCentroid returns the signed area and centroid of a projected ring
+func ringCentroid(ring [][2]float64) (area, cx, cy float64) {
+	n := len(ring)
+	for i := range ring {
+		a, b := ring[i], ring[(i+1)%n]
+		cross := a[0]*b[1] - b[0]*a[1]
+		area += cross
+		cx += (a[0] + b[0]) * cross
+		cy += (a[1] + b[1]) * cross
+	}
+	if area == 0 {
+		return 0, 0, 0
+	}
+	return area / 2, cx / (3 * area), cy / (3 * area)
+}
+
+// polylabelCell is a square cell searched by polylabel
+type polylabelCell struct {
+	x, y     float64 // Centre
+	half     float64 // Half the cell size
+	distance float64 // Signed distance from the centre to the polygon, positive inside
+	max      float64 // Upper bound of the distance within the cell
+}
+
+// newPolylabelCell evaluates a cell of a projected polygon
+func newPolylabelCell(x, y, half float64, rings [][][2]float64) polylabelCell {
+	distance := signedRingsDistance(x, y, rings)
+	return polylabelCell{x: x, y: y, half: half, distance: distance, max: distance + half*math.Sqrt2}
+}
+
+// polylabelQueue is a max-heap of cells by their distance bound
+type polylabelQueue []polylabelCell
+
+func (q polylabelQueue) Len() int           { return len(q) }
+func (q polylabelQueue) Less(i, j int) bool { return q[i].max > q[j].max }
+func (q polylabelQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *polylabelQueue) Push(x any)        { *q = append(*q, x.(polylabelCell)) }
+func (q *polylabelQueue) Pop() any {
+	old := *q
+	cell := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return cell
+}
+
+// polylabel finds the pole of inaccessibility of a projected polygon to
+// within precision, following Mapbox's polylabel: cells covering the polygon
+// are split best-first until no cell can beat the best point found.
+func polylabel(rings [][][2]float64, precision float64) (x, y float64) {
+	if len(rings) == 0 || len(rings[0]) == 0 {
+		return 0, 0
+	}
+
+	minX, minY, maxX, maxY := rings[0][0][0], rings[0][0][1], rings[0][0][0], rings[0][0][1]
+	for _, pt := range rings[0] {
+		minX, maxX = math.Min(minX, pt[0]), math.Max(maxX, pt[0])
+		minY, maxY = math.Min(minY, pt[1]), math.Max(maxY, pt[1])
+	}
+	size := math.Min(maxX-minX, maxY-minY)
+	if size == 0 {
+		return minX, minY
+	}
+
+	// Seed with the centroid and the bounding box centre
+	_, cx, cy := ringCentroid(rings[0])
+	best := newPolylabelCell(cx, cy, 0, rings)
+	if boxCentre := newPolylabelCell((minX+maxX)/2, (minY+maxY)/2, 0, rings); boxCentre.distance > best.distance {
+		best = boxCentre
+	}
+
+	queue := &polylabelQueue{}
+	half := size / 2
+	for cellX := minX; cellX < maxX; cellX += size {
+		for cellY := minY; cellY < maxY; cellY += size {
+			heap.Push(queue, newPolylabelCell(cellX+half, cellY+half, half, rings))
+		}
+	}
+
+	for queue.Len() > 0 {
+		cell := heap.Pop(queue).(polylabelCell)
+		if cell.distance > best.distance {
+			best = cell
+		}
+		if cell.max-best.distance <= precision {
+			continue
+		}
+
+		half = cell.half / 2
+		heap.Push(queue, newPolylabelCell(cell.x-half, cell.y-half, half, rings))
+		heap.Push(queue, newPolylabelCell(cell.x+half, cell.y-half, half, rings))
+		heap.Push(queue, newPolylabelCell(cell.x-half, cell.y+half, half, rings))
+		heap.Push(queue, newPolylabelCell(cell.x+half, cell.y+half, half, rings))
+	}
+
+	return best.x, best.y
+}
+
+// signedRingsDistance returns the distance from a point to the nearest edge
+// of a projected polygon, positive inside and negative outside. Like
+// polygonContains, a point is inside if it is inside the first ring and
+// outside every hole.
+func signedRingsDistance(x, y float64, rings [][][2]float64) float64 {
+	inside := false
+	best := math.Inf(1)
+	for r, ring := range rings {
+		inRing := false
+		n := len(ring)
+		for i, j := 0, n-1; i < n; j, i = i, i+1 {
+			a, b := ring[i], ring[j]
+			if (a[1] > y) != (b[1] > y) && x < (b[0]-a[0])*(y-a[1])/(b[1]-a[1])+a[0] {
+				inRing = !inRing
+			}
+			best = math.Min(best, originSegmentDistance(a[0]-x, a[1]-y, b[0]-x, b[1]-y))
+		}
+		switch {
+		case r == 0:
+			inside = inRing
+		case inRing:
+			inside = false
+		}
+	}
+
+	if inside {
+		return best
+	}
+	return -best
+}
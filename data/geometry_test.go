@@ -0,0 +1,141 @@
+package data
+
+import (
+	"math"
+	"testing"
+
+	h3utils "github.com/politic-in/core/h3-utils"
+)
+
+// testSquare returns a closed square ring of size degrees with its south-west corner at (lat, lng)
+func testSquare(lat, lng, size float64) [][]float64 {
+	return [][]float64{{lng, lat}, {lng + size, lat}, {lng + size, lat + size}, {lng, lat + size}, {lng, lat}}
+}
+
+func TestACBoundaryAreaKm2(t *testing.T) {
+	// A latitude-longitude rectangle has area R² Δλ (sin φ2 - sin φ1)
+	want := earthRadiusM * earthRadiusM * (math.Pi / 180) * (math.Sin(13*math.Pi/180) - math.Sin(12*math.Pi/180)) / 1e6
+	square := ACBoundary{Polygon: [][][]float64{testSquare(12, 77, 1)}}
+	if got := square.AreaKm2(); math.Abs(got-want) > 1e-6*want {
+		t.Errorf("square AreaKm2() = %.3f, want %.3f", got, want)
+	}
+
+	// Holes are subtracted whichever way the rings wind
+	hole := testSquare(12.25, 77.25, 0.5)
+	for i, j := 0, len(hole)-1; i < j; i, j = i+1, j-1 {
+		hole[i], hole[j] = hole[j], hole[i]
+	}
+	holed := ACBoundary{Polygon: [][][]float64{testSquare(12, 77, 1), hole}}
+	holeArea := ACBoundary{Polygon: [][][]float64{testSquare(12.25, 77.25, 0.5)}}.AreaKm2()
+	if got := holed.AreaKm2(); math.Abs(got-(want-holeArea)) > 1e-6*want {
+		t.Errorf("holed AreaKm2() = %.3f, want %.3f", got, want-holeArea)
+	}
+
+	// An H3 cell outline agrees with H3's own area
+	cellID := h3utils.LatLngToCellAtResolution(12.97, 77.59, 5)
+	vertices, err := h3utils.GetCellBoundary(cellID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring := make([][]float64, 0, len(vertices)+1)
+	for _, v := range vertices {
+		ring = append(ring, []float64{v.Lng, v.Lat})
+	}
+	ring = append(ring, ring[0])
+	cellAreaM2, _ := h3utils.CellArea(cellID)
+	if got := (ACBoundary{Polygon: [][][]float64{ring}}).AreaKm2(); math.Abs(got-cellAreaM2/1e6) > 0.005*cellAreaM2/1e6 {
+		t.Errorf("H3 cell AreaKm2() = %.3f, H3 says %.3f", got, cellAreaM2/1e6)
+	}
+}
+
+func TestACBoundaryPerimeterKm(t *testing.T) {
+	square := ACBoundary{Polygon: [][][]float64{testSquare(12, 77, 1)}}
+	want := (h3utils.HaversineDistance(12, 77, 12, 78) + h3utils.HaversineDistance(12, 78, 13, 78) +
+		h3utils.HaversineDistance(13, 78, 13, 77) + h3utils.HaversineDistance(13, 77, 12, 77)) / 1000
+	if got := square.PerimeterKm(); math.Abs(got-want) > 1e-9 {
+		t.Errorf("PerimeterKm() = %.3f, want %.3f", got, want)
+	}
+}
+
+func TestACBoundaryCentroidAndLabelPoint(t *testing.T) {
+	square := ACBoundary{Polygon: [][][]float64{testSquare(12, 77, 1)}}
+	if lat, lng := square.Centroid(); math.Abs(lat-12.5) > 0.01 || math.Abs(lng-77.5) > 1e-9 {
+		t.Errorf("square Centroid() = (%f, %f), want about (12.5, 77.5)", lat, lng)
+	}
+	if lat, lng := square.LabelPoint(); math.Abs(lat-12.5) > 0.01 || math.Abs(lng-77.5) > 0.01 {
+		t.Errorf("square LabelPoint() = (%f, %f), want about (12.5, 77.5)", lat, lng)
+	}
+
+	// A C shape opening east: the centroid falls in the gap, the label point does not
+	cShape := ACBoundary{Polygon: [][][]float64{{
+		{77, 12}, {78, 12}, {78, 12.2}, {77.2, 12.2}, {77.2, 12.8}, {78, 12.8}, {78, 13}, {77, 13}, {77, 12},
+	}}}
+	lat, lng := cShape.Centroid()
+	if cShape.ContainsPoint(lat, lng) {
+		t.Errorf("C shape Centroid() = (%f, %f) should fall in the gap", lat, lng)
+	}
+	lat, lng = cShape.LabelPoint()
+	if !cShape.ContainsPoint(lat, lng) {
+		t.Fatalf("C shape LabelPoint() = (%f, %f) is outside", lat, lng)
+	}
+	// The widest part is the 0.2° spine or arms, so the label is about 0.1° from the border
+	if distanceM := cShape.DistanceToBoundary(lat, lng); distanceM < 10000 {
+		t.Errorf("C shape LabelPoint() is %.0f m from the border, want about 11 km", distanceM)
+	}
+
+	// Multi-part boundaries are labelled in their largest part
+	islands := ACBoundary{Polygons: [][][][]float64{{testSquare(10, 70, 0.1)}, {testSquare(12, 77, 1)}}}
+	if lat, lng := islands.LabelPoint(); math.Abs(lat-12.5) > 0.01 || math.Abs(lng-77.5) > 0.01 {
+		t.Errorf("multi-part LabelPoint() = (%f, %f), want the larger square", lat, lng)
+	}
+
+	// A "hole" outside the exterior ring is not part of the boundary, however large
+	outerHole := ACBoundary{Polygon: [][][]float64{testSquare(10, 70, 0.1), testSquare(12, 77, 1)}}
+	if lat, lng := outerHole.LabelPoint(); !outerHole.ContainsPoint(lat, lng) {
+		t.Errorf("LabelPoint() with a hole outside the exterior = (%f, %f) is outside", lat, lng)
+	}
+}
+
+func TestParseGeoJSONPolygonsSplitsOuterHoles(t *testing.T) {
+	geometry := geoJSONGeometry{Type: "Polygon", Coordinates: []byte(`[
+		[[77, 12], [78, 12], [78, 13], [77, 13], [77, 12]],
+		[[77.25, 12.25], [77.25, 12.75], [77.75, 12.75], [77.75, 12.25], [77.25, 12.25]],
+		[[79, 12], [79, 13], [80, 13], [80, 12], [79, 12]]]`)}
+	polygons, err := parseGeoJSONPolygons(geometry)
+	if err != nil {
+		t.Fatalf("parseGeoJSONPolygons() error: %v", err)
+	}
+	if len(polygons) != 2 || len(polygons[0]) != 2 || len(polygons[1]) != 1 {
+		t.Fatalf("parsed %d polygons, want the shell with its hole and the outer ring as a part", len(polygons))
+	}
+
+	boundary := ACBoundary{Polygons: polygons}
+	if !boundary.ContainsPoint(12.5, 79.5) || boundary.ContainsPoint(12.5, 77.5) {
+		t.Error("ContainsPoint() should include the split part and exclude the real hole")
+	}
+	shell := ACBoundary{Polygon: [][][]float64{testSquare(12, 77, 1)}}.AreaKm2()
+	hole := ACBoundary{Polygon: [][][]float64{testSquare(12.25, 77.25, 0.5)}}.AreaKm2()
+	if got, want := boundary.AreaKm2(), 2*shell-hole; math.Abs(got-want) > 1e-6*want {
+		t.Errorf("AreaKm2() = %.3f, want %.3f with the split part added", got, want)
+	}
+}
+
+func TestGetACStatsGeometry(t *testing.T) {
+	index := newTestIndex(t)
+
+	stats, err := index.GetACStats("testland", 2)
+	if err != nil {
+		t.Fatalf("GetACStats() error: %v", err)
+	}
+
+	boundary, _ := index.GetBoundaryForAC("testland", 2)
+	if stats.AreaKm2 != boundary.AreaKm2() || stats.PerimeterKm != boundary.PerimeterKm() {
+		t.Errorf("stats area %.1f perimeter %.1f", stats.AreaKm2, stats.PerimeterKm)
+	}
+	if stats.AreaKm2 < 11900 || stats.AreaKm2 > 12100 {
+		t.Errorf("AreaKm2 = %.1f, want about 12000 for a one-degree square", stats.AreaKm2)
+	}
+	if !boundary.ContainsPoint(stats.LabelLat, stats.LabelLng) || !boundary.ContainsPoint(stats.CentroidLat, stats.CentroidLng) {
+		t.Errorf("centroid (%f, %f) or label (%f, %f) outside Beta", stats.CentroidLat, stats.CentroidLng, stats.LabelLat, stats.LabelLng)
+	}
+}
@@ -1,7 +1,10 @@
 package data
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"sort"
 
 	h3utils "github.com/politic-in/core/h3-utils"
 )
@@ -60,33 +63,64 @@ func (g *GeoIndex) GetACForH3Cell(cellID string) (*ACBoundary, string, error) {
 	return g.FindACAtPointAllStates(lat, lng)
 }
 
-// GetH3CellsForAC returns H3 cells that cover an AC boundary
+// GetH3CellsForAC returns the H3 cells whose centre lies inside an AC
+// boundary, across all of its polygons and outside its holes
 func (g *GeoIndex) GetH3CellsForAC(stateSlug string, consCode, resolution int) ([]string, error) {
+	return g.GetH3CellsForACWithContainment(stateSlug, consCode, resolution, h3utils.ContainmentCentroid)
+}
+
+// GetH3CellsForACWithContainment returns the H3 cells of an AC boundary
+// chosen by containment mode: h3utils.ContainmentFull for cells entirely
+// inside the AC, h3utils.ContainmentOverlapping for every cell touching it
+func (g *GeoIndex) GetH3CellsForACWithContainment(stateSlug string, consCode, resolution int, mode h3utils.ContainmentMode) ([]string, error) {
 	boundary, err := g.GetBoundaryForAC(stateSlug, consCode)
 	if err != nil {
 		return nil, err
 	}
 
-	ring := boundary.GetExteriorRing()
-	if len(ring) == 0 {
+	polygons := boundary.GetPolygons()
+	if len(polygons) == 0 {
 		return nil, fmt.Errorf("%w: empty polygon for %s/%d", ErrBoundaryNotFound, stateSlug, consCode)
 	}
 
-	// Convert ring to lat/lng pairs for polyfill
-	coords := make([][2]float64, len(ring))
-	for i, pt := range ring {
-		coords[i] = [2]float64{pt[1], pt[0]} // [lat, lng] - GeoJSON is [lng, lat]
+	return polygonsToCells(polygons, resolution, mode)
+}
+
+// polygonsToCells returns the H3 cells of a set of GeoJSON polygons, with
+// their holes, chosen by containment mode. Rings too short to enclose
+// anything are skipped.
+func polygonsToCells(polygons [][][][]float64, resolution int, mode h3utils.ContainmentMode) ([]string, error) {
+	parts := make([]h3utils.Polygon, 0, len(polygons))
+	for _, polygon := range polygons {
+		if len(polygon) == 0 || len(polygon[0]) < 3 {
+			continue
+		}
+
+		part := h3utils.Polygon{Outer: latLngRing(polygon[0])}
+		for _, hole := range polygon[1:] {
+			if len(hole) >= 3 {
+				part.Holes = append(part.Holes, latLngRing(hole))
+			}
+		}
+		parts = append(parts, part)
 	}
 
-	// Use h3-utils to get cells covering the polygon
-	cells, err := h3utils.PolygonToCells(coords, resolution)
+	cells, err := h3utils.PolygonsToCells(parts, resolution, mode)
 	if err != nil {
 		return nil, fmt.Errorf("polyfill error: %w", err)
 	}
-
 	return cells, nil
 }
 
+// latLngRing converts a GeoJSON [lng, lat] ring to the [lat, lng] pairs used by h3utils
+func latLngRing(ring [][]float64) [][2]float64 {
+	coords := make([][2]float64, len(ring))
+	for i, pt := range ring {
+		coords[i] = [2]float64{pt[1], pt[0]}
+	}
+	return coords
+}
+
 // H3CellToACMapping maps H3 cells to their ACs for a state
 type H3CellToACMapping struct {
 	CellID     string
@@ -126,51 +160,334 @@ func (g *GeoIndex) BuildH3ToACMapping(stateSlug string, resolution int) ([]H3Cel
 	return mappings, nil
 }
 
-// NearbyBooths finds booths near a location using H3 neighbors
+// NearbyBooth is a booth with its distance from a query point
+type NearbyBooth struct {
+	Booth     *PollingBooth
+	StateSlug string
+	DistanceM float64
+}
+
+// NearbyBoothsResult holds the booths found around a point
+type NearbyBoothsResult struct {
+	Booths    []NearbyBooth   // Booths with coordinates, nearest first
+	Unlocated []*PollingBooth // Booths in the searched ACs that have no coordinates
+	ACs       []ACNode        // ACs whose booths were searched, in any state
+}
+
+// NearbyBooths finds booths within a k-ring of resolution-9 H3 cells around a
+// location, nearest first. Booths without coordinates are left out; use
+// NearbyBoothsRanked for them and for the distances.
 func (g *GeoIndex) NearbyBooths(stateSlug string, lat, lng float64, k int) ([]*PollingBooth, error) {
+	result, err := g.NearbyBoothsRanked(stateSlug, lat, lng, k)
+	if err != nil {
+		return nil, err
+	}
+
+	booths := make([]*PollingBooth, len(result.Booths))
+	for i, nearby := range result.Booths {
+		booths[i] = nearby.Booth
+	}
+	return booths, nil
+}
+
+// NearbyBoothsRanked finds booths within a k-ring of resolution-9 H3 cells
+// around a location, ranked by distance. Booths in neighbouring ACs are
+// included when the ring crosses an AC boundary, in other states as well.
+func (g *GeoIndex) NearbyBoothsRanked(stateSlug string, lat, lng float64, k int) (*NearbyBoothsResult, error) {
 	// Get H3 cell at resolution 9 (~0.1 km^2)
 	cellID := h3utils.LatLngToCellAtResolution(lat, lng, 9)
 
-	// Get the AC at this location
-	boundary, err := g.FindACAtPoint(stateSlug, lat, lng)
+	// Get k-ring of cells around the location
+	neighbors, err := h3utils.GetCellsInRadius(cellID, k)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get all booths in the AC
-	booths, err := g.GetBoothsForAC(stateSlug, boundary.ConsCode)
+	// Create a set of neighbor cells and find how far the ring reaches
+	cellSet := make(map[string]bool, len(neighbors))
+	var reachM float64
+	for _, n := range neighbors {
+		cellSet[n] = true
+
+		vertices, err := h3utils.GetCellBoundary(n)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vertices {
+			reachM = math.Max(reachM, h3utils.HaversineDistance(lat, lng, v.Lat, v.Lng))
+		}
+	}
+
+	return g.nearbyBooths(stateSlug, lat, lng, reachM, func(booth *PollingBooth, _ float64) bool {
+		return cellSet[h3utils.LatLngToCellAtResolution(*booth.Lat, *booth.Lon, 9)]
+	})
+}
+
+// NearbyBoothsWithinRadius finds booths within radiusM metres of a location,
+// ranked by distance, in other states as well when the radius crosses a
+// state border
+func (g *GeoIndex) NearbyBoothsWithinRadius(stateSlug string, lat, lng, radiusM float64) (*NearbyBoothsResult, error) {
+	if radiusM < 0 {
+		return nil, fmt.Errorf("radius must be non-negative")
+	}
+
+	return g.nearbyBooths(stateSlug, lat, lng, radiusM, func(_ *PollingBooth, distanceM float64) bool {
+		return distanceM <= radiusM
+	})
+}
+
+// nearbyBooths collects booths from every AC whose boundary comes within
+// reachM of the point and keeps those accepted by the filter
+func (g *GeoIndex) nearbyBooths(stateSlug string, lat, lng, reachM float64, accept func(*PollingBooth, float64) bool) (*NearbyBoothsResult, error) {
+	near, err := g.boundariesNearAllStates(stateSlug, lat, lng, reachM)
 	if err != nil {
 		return nil, err
 	}
+	if len(near) == 0 {
+		return nil, fmt.Errorf("%w: no AC near (%.6f, %.6f)", ErrACNotFound, lat, lng)
+	}
 
-	// Get k-ring of cells around the location
-	neighbors, err := h3utils.GetCellsInRadius(cellID, k)
+	result := &NearbyBoothsResult{}
+	for _, nb := range near {
+		booths, err := g.GetBoothsForAC(nb.stateSlug, nb.boundary.ConsCode)
+		switch {
+		case errors.Is(err, ErrStateNotFound) && nb.stateSlug != stateSlug:
+			// A neighbouring state with boundaries but no booth data
+			continue
+		case err != nil:
+			return nil, err
+		}
+		result.ACs = append(result.ACs, ACNode{StateSlug: nb.stateSlug, ConsCode: nb.boundary.ConsCode})
+
+		for _, booth := range booths {
+			if booth.Lat == nil || booth.Lon == nil {
+				result.Unlocated = append(result.Unlocated, booth)
+				continue
+			}
+
+			distance := h3utils.HaversineDistance(lat, lng, *booth.Lat, *booth.Lon)
+			if accept(booth, distance) {
+				result.Booths = append(result.Booths, NearbyBooth{Booth: booth, StateSlug: nb.stateSlug, DistanceM: distance})
+			}
+		}
+	}
+
+	sort.SliceStable(result.Booths, func(i, j int) bool {
+		return result.Booths[i].DistanceM < result.Booths[j].DistanceM
+	})
+
+	return result, nil
+}
+
+// boundariesNear returns the AC boundaries whose bounding box lies within
+// radiusM metres of a point
+func (g *GeoIndex) boundariesNear(stateSlug string, lat, lng, radiusM float64) ([]*ACBoundary, error) {
+	var result []*ACBoundary
+	err := g.readBoundaries(stateSlug, func() {
+		boundaries := g.boundariesByState[stateSlug]
+		for _, i := range g.boundaryIndex[stateSlug].SearchBBox(radiusBBox(lat, lng, radiusM)) {
+			result = append(result, boundaries[i])
+		}
+	})
+
+	return result, err
+}
+
+// stateBoundary is an AC boundary with the state it belongs to
+type stateBoundary struct {
+	stateSlug string
+	boundary  *ACBoundary
+}
+
+// boundariesNearAllStates returns the AC boundaries whose bounding box lies
+// within radiusM metres of a point, in stateSlug first and then in every
+// other state with boundaries, so ACs just across a state border are found.
+// Other states are only loaded if their boundary extent comes within radiusM.
+// States whose boundaries cannot be loaded are skipped, as in
+// FindACAtPointAllStates; only an error for stateSlug itself is returned.
+func (g *GeoIndex) boundariesNearAllStates(stateSlug string, lat, lng, radiusM float64) ([]stateBoundary, error) {
+	var result []stateBoundary
+	own, err := g.boundariesNear(stateSlug, lat, lng, radiusM)
 	if err != nil {
 		return nil, err
 	}
+	for _, boundary := range own {
+		result = append(result, stateBoundary{stateSlug, boundary})
+	}
 
-	// Create a set of neighbor cells
-	cellSet := make(map[string]bool)
-	for _, n := range neighbors {
-		cellSet[n] = true
+	availableStates, err := g.availableBoundaryStates()
+	if err != nil {
+		return nil, err
 	}
+	for _, stateName := range availableStates {
+		other := ToSlug(stateName)
+		if other == stateSlug {
+			continue
+		}
+		if extent, err := g.boundaryExtent(other); err != nil || !extentNear(extent, lat, lng, radiusM) {
+			continue
+		}
+		boundaries, err := g.boundariesNear(other, lat, lng, radiusM)
+		if err != nil {
+			continue
+		}
+		for _, boundary := range boundaries {
+			result = append(result, stateBoundary{other, boundary})
+		}
+	}
+	return result, nil
+}
 
-	// Filter booths that fall within the cell neighborhood
-	// Since booths don't have coordinates, return all booths in the AC
-	// In a real implementation, booths would have lat/lng
-	return booths, nil
+// radiusBBox returns a [minLng, minLat, maxLng, maxLat] box enclosing a circle
+func radiusBBox(lat, lng, radiusM float64) [4]float64 {
+	const metresPerDegree = 111320.0
+
+	dLat := radiusM / metresPerDegree
+	dLng := radiusM / (metresPerDegree * math.Max(math.Cos(lat*math.Pi/180), 0.01))
+
+	return [4]float64{lng - dLng, lat - dLat, lng + dLng, lat + dLat}
+}
+
+// maxChildExpansion is the largest resolution gap for which BoothsInH3Cell
+// expands a coarse cell into its children instead of scanning the index
+const maxChildExpansion = 5
+
+// BoothCellResolution returns the H3 resolution of the booth cell index
+func (g *GeoIndex) BoothCellResolution() int {
+	return g.config.BoothCellResolution
 }
 
-// BoothsInH3Cell returns booths whose AC contains an H3 cell
+// BoothCell returns the H3 cell at the booth index resolution that serves a booth
+func (g *GeoIndex) BoothCell(booth *PollingBooth) (string, bool) {
+	return g.boothCell(booth)
+}
+
+// GetBoothCell returns the H3 cell that serves a booth identified by state, AC and part ID
+func (g *GeoIndex) GetBoothCell(stateSlug string, acNumber, partID int) (string, error) {
+	booth, err := g.GetBooth(stateSlug, acNumber, partID)
+	if err != nil {
+		return "", err
+	}
+
+	cellID, ok := g.boothCell(booth)
+	if !ok {
+		return "", fmt.Errorf("%w: %s AC:%d Part:%d has no coordinates", ErrBoothNotFound, stateSlug, acNumber, partID)
+	}
+	return cellID, nil
+}
+
+// boothCell returns the booth's cell at the configured resolution
+func (g *GeoIndex) boothCell(booth *PollingBooth) (string, bool) {
+	if booth.Lat == nil || booth.Lon == nil {
+		return "", false
+	}
+	cellID := h3utils.LatLngToCellAtResolution(*booth.Lat, *booth.Lon, g.config.BoothCellResolution)
+	return cellID, cellID != ""
+}
+
+// BoothsInH3Cell returns the booths whose coordinates fall inside an H3 cell.
+// The cell may be at any resolution. Every state with booth data whose booth
+// extent comes near the cell is searched, loading its booths if needed.
 func (g *GeoIndex) BoothsInH3Cell(cellID string) ([]*PollingBooth, error) {
-	// Get AC for this cell
-	boundary, stateSlug, err := g.GetACForH3Cell(cellID)
+	res, err := h3utils.GetResolution(cellID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid H3 cell: %w", err)
+	}
+
+	// How far the cell reaches from its centre
+	lat, lng, err := h3utils.CellToLatLng(cellID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid H3 cell: %w", err)
+	}
+	vertices, err := h3utils.GetCellBoundary(cellID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid H3 cell: %w", err)
+	}
+	var reachM float64
+	for _, v := range vertices {
+		reachM = math.Max(reachM, h3utils.HaversineDistance(lat, lng, v.Lat, v.Lng))
+	}
+
+	boothDirs, err := ListAvailableStatesFS(g.fsys)
 	if err != nil {
 		return nil, err
 	}
 
-	// Return all booths in this AC
-	return g.GetBoothsForAC(stateSlug, boundary.ConsCode)
+	// The cell index is shared by all loaded states, so a booth may be seen
+	// once per state searched
+	var booths []*PollingBooth
+	seen := make(map[*PollingBooth]bool)
+	for _, dirName := range boothDirs {
+		stateSlug := NormalizeBoothDirToStateSlug(dirName)
+		if extent, err := g.boothExtent(stateSlug); err == nil && !extentNear(extent, lat, lng, reachM) {
+			continue
+		}
+
+		var found []*PollingBooth
+		var cellErr error
+		err := g.readBooths(stateSlug, func() {
+			found, cellErr = g.boothsInH3CellLocked(cellID, res)
+		})
+		if err == nil {
+			err = cellErr
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, booth := range found {
+			if !seen[booth] {
+				seen[booth] = true
+				booths = append(booths, booth)
+			}
+		}
+	}
+	return booths, nil
+}
+
+// boothsInH3CellLocked collects the indexed booths inside a cell at resolution res (must hold lock)
+func (g *GeoIndex) boothsInH3CellLocked(cellID string, res int) ([]*PollingBooth, error) {
+	indexRes := g.config.BoothCellResolution
+	switch {
+	case res == indexRes:
+		return g.boothsByCell[cellID], nil
+
+	case res > indexRes:
+		// Finer cell: filter the booths of its parent
+		parent, err := h3utils.GetParent(cellID, indexRes)
+		if err != nil {
+			return nil, err
+		}
+		var booths []*PollingBooth
+		for _, booth := range g.boothsByCell[parent] {
+			if inside, _ := h3utils.CellContains(cellID, *booth.Lat, *booth.Lon); inside {
+				booths = append(booths, booth)
+			}
+		}
+		return booths, nil
+
+	case indexRes-res <= maxChildExpansion:
+		// Coarser cell: collect the booths of its children
+		children, err := h3utils.GetChildren(cellID, indexRes)
+		if err != nil {
+			return nil, err
+		}
+		var booths []*PollingBooth
+		for _, child := range children {
+			booths = append(booths, g.boothsByCell[child]...)
+		}
+		return booths, nil
+
+	default:
+		// Very coarse cell: scan the indexed cells instead of its children
+		var booths []*PollingBooth
+		for indexedCell, cellBooths := range g.boothsByCell {
+			if parent, err := h3utils.GetParent(indexedCell, res); err == nil && parent == cellID {
+				booths = append(booths, cellBooths...)
+			}
+		}
+		return booths, nil
+	}
 }
 
 // ACStats returns statistics for an AC
@@ -179,10 +496,15 @@ type ACStats struct {
 	ACCode      int
 	ACName      string
 	BoothCount  int
-	AreaKm2     float64
+	AreaKm2     float64    // Spherical area, holes excluded
+	PerimeterKm float64    // Length of every ring, holes included
 	BoundingBox [4]float64 // [minLng, minLat, maxLng, maxLat]
-	CenterLat   float64
+	CenterLat   float64    // Bounding box midpoint, which may lie outside the AC
 	CenterLng   float64
+	CentroidLat float64 // Area-weighted centroid, which may lie outside a concave AC
+	CentroidLng float64
+	LabelLat    float64 // Interior point farthest from the border, see ACBoundary.LabelPoint
+	LabelLng    float64
 	H3CellsRes9 int // Number of H3 cells at resolution 9
 }
 
@@ -201,6 +523,8 @@ func (g *GeoIndex) GetACStats(stateSlug string, consCode int) (*ACStats, error)
 		ACCode:      consCode,
 		ACName:      boundary.ConsName,
 		BoothCount:  len(booths),
+		AreaKm2:     boundary.AreaKm2(),
+		PerimeterKm: boundary.PerimeterKm(),
 		BoundingBox: boundary.BoundingBox(),
 	}
 
@@ -208,12 +532,12 @@ func (g *GeoIndex) GetACStats(stateSlug string, consCode int) (*ACStats, error)
 	stats.CenterLat = (stats.BoundingBox[1] + stats.BoundingBox[3]) / 2
 	stats.CenterLng = (stats.BoundingBox[0] + stats.BoundingBox[2]) / 2
 
-	// Calculate area using H3 cells
+	stats.CentroidLat, stats.CentroidLng = boundary.Centroid()
+	stats.LabelLat, stats.LabelLng = boundary.LabelPoint()
+
 	cells, err := g.GetH3CellsForAC(stateSlug, consCode, 9)
 	if err == nil {
 		stats.H3CellsRes9 = len(cells)
-		// Each res-9 cell is approximately 0.1052 km^2
-		stats.AreaKm2 = float64(len(cells)) * 0.1052
 	}
 
 	return stats, nil
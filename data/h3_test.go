@@ -0,0 +1,232 @@
+package data
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	h3utils "github.com/politic-in/core/h3-utils"
+)
+
+func TestNearbyBoothsWithinRadius(t *testing.T) {
+	index := NewGeoIndex(".")
+	booths, err := index.GetBoothsForState("goa")
+	if err != nil {
+		t.Skipf("goa booths not available: %v", err)
+	}
+
+	var origin *PollingBooth
+	for _, booth := range booths {
+		if booth.Lat != nil && booth.Lon != nil {
+			origin = booth
+			break
+		}
+	}
+	if origin == nil {
+		t.Skip("no goa booth with coordinates")
+	}
+
+	result, err := index.NearbyBoothsWithinRadius("goa", *origin.Lat, *origin.Lon, 2000)
+	if err != nil {
+		t.Fatalf("NearbyBoothsWithinRadius() error: %v", err)
+	}
+	if len(result.Booths) == 0 {
+		t.Fatal("expected at least the origin booth")
+	}
+
+	for i, nb := range result.Booths {
+		if nb.DistanceM > 2000 {
+			t.Errorf("booth %d at %.0fm is outside the radius", nb.Booth.PartID, nb.DistanceM)
+		}
+		if i > 0 && nb.DistanceM < result.Booths[i-1].DistanceM {
+			t.Errorf("booths not sorted by distance at index %d", i)
+		}
+	}
+}
+
+func TestNearbyBooths(t *testing.T) {
+	index := newTestIndex(t)
+
+	booths, err := index.NearbyBooths("testland", 12.5, 77.5, 2)
+	if err != nil {
+		t.Fatalf("NearbyBooths() error: %v", err)
+	}
+	if len(booths) != 1 || booths[0].PartID != 1 {
+		t.Errorf("NearbyBooths() = %v, want the Alpha booth", booths)
+	}
+}
+
+func TestNearbyBoothsAcrossStates(t *testing.T) {
+	files := testDataFiles()
+	files[filepath.Join(BoundariesDir, "otherland.geojson")] = `{"type": "FeatureCollection", "state_name": "Otherland", "features": [` +
+		testSquareFeature(1, "Delta", 80.0003, 12) + `]}`
+	files[filepath.Join(BoothsDir, "otherland", "east.json")] = `[
+		{"partId": 1, "stateName": "Otherland", "districtName": "EAST", "acNumber": 1, "acName": "Delta",
+		 "partNumber": 1, "partName": "Ward Office, Delta", "lat": 12.5, "lon": 80.002}]`
+	index := NewGeoIndex(writeTestData(t, files))
+
+	// Gamma's only booth has no coordinates; the nearest booth is over the state border
+	result, err := index.NearbyBoothsWithinRadius("testland", 12.5, 79.998, 1000)
+	if err != nil {
+		t.Fatalf("NearbyBoothsWithinRadius() error: %v", err)
+	}
+	if len(result.Booths) != 1 || result.Booths[0].StateSlug != "otherland" || result.Booths[0].Booth.ACName != "Delta" {
+		t.Fatalf("NearbyBoothsWithinRadius() booths = %+v, want the Delta booth", result.Booths)
+	}
+	if want := []ACNode{{"testland", 3}, {"otherland", 1}}; !reflect.DeepEqual(result.ACs, want) {
+		t.Errorf("searched ACs = %v, want %v", result.ACs, want)
+	}
+	if len(result.Unlocated) != 1 {
+		t.Errorf("Unlocated = %v, want Gamma's booth", result.Unlocated)
+	}
+}
+
+func TestNearbyBoothsSkipsDistantStates(t *testing.T) {
+	files := testDataFiles()
+	files[filepath.Join(BoundariesDir, "farland.geojson")] = `{"type": "FeatureCollection", "state_name": "Farland", "features": [` +
+		testSquareFeature(1, "Zeta", 88, 22) + `]}`
+	index := NewGeoIndex(writeTestData(t, files))
+
+	if _, err := index.NearbyBoothsWithinRadius("testland", 12.5, 77.5, 1000); err != nil {
+		t.Fatalf("NearbyBoothsWithinRadius() error: %v", err)
+	}
+	index.mu.RLock()
+	defer index.mu.RUnlock()
+	if index.loadedBounds["farland"] {
+		t.Error("Farland boundaries were loaded for a query 10 degrees away")
+	}
+	if want := [4]float64{88, 22, 89, 23}; index.boundaryExtents["farland"] != want {
+		t.Errorf("Farland extent = %v, want %v", index.boundaryExtents["farland"], want)
+	}
+}
+
+func TestBoothsInH3CellWithoutBoundaries(t *testing.T) {
+	// Otherland has booths but no boundary file
+	files := testDataFiles()
+	files[filepath.Join(BoothsDir, "otherland", "east.json")] = `[
+		{"partId": 1, "stateName": "Otherland", "districtName": "EAST", "acNumber": 1, "acName": "Delta",
+		 "partNumber": 1, "partName": "Ward Office, Delta", "lat": 20.5, "lon": 85.5}]`
+	index := NewGeoIndex(writeTestData(t, files))
+
+	for _, tt := range []struct {
+		lat, lng float64
+		partName string
+	}{
+		{20.5, 85.5, "Ward Office, Delta"},
+		{12.5, 77.5, "Govt School, Alpha"},
+	} {
+		booths, err := index.BoothsInH3Cell(LatLngToH3CellAtResolution(tt.lat, tt.lng, 8))
+		if err != nil {
+			t.Fatalf("BoothsInH3Cell() error: %v", err)
+		}
+		if len(booths) != 1 || booths[0].PartName != tt.partName {
+			t.Errorf("BoothsInH3Cell() at (%g, %g) = %v, want %q", tt.lat, tt.lng, booths, tt.partName)
+		}
+	}
+}
+
+func TestBoothsInH3CellSkipsDistantStates(t *testing.T) {
+	// Otherland has booths but no boundary file
+	files := testDataFiles()
+	files[filepath.Join(BoothsDir, "otherland", "east.json")] = `[
+		{"partId": 1, "stateName": "Otherland", "districtName": "EAST", "acNumber": 1, "acName": "Delta",
+		 "partNumber": 1, "partName": "Ward Office, Delta", "lat": 20.5, "lon": 85.5},
+		{"partId": 2, "stateName": "Otherland", "districtName": "EAST", "acNumber": 1, "acName": "Delta",
+		 "partNumber": 2, "partName": "Unmapped School, Delta", "lat": null}]`
+	index := NewGeoIndex(writeTestData(t, files))
+
+	booths, err := index.BoothsInH3Cell(LatLngToH3CellAtResolution(12.5, 77.5, 8))
+	if err != nil {
+		t.Fatalf("BoothsInH3Cell() error: %v", err)
+	}
+	if len(booths) != 1 || booths[0].PartName != "Govt School, Alpha" {
+		t.Errorf("BoothsInH3Cell() = %v, want the Alpha booth", booths)
+	}
+
+	index.mu.RLock()
+	defer index.mu.RUnlock()
+	if index.loadedStates["otherland"] {
+		t.Error("Otherland booths were loaded for a cell 8 degrees away")
+	}
+	if want := [4]float64{85.5, 20.5, 85.5, 20.5}; index.boothExtents["otherland"] != want {
+		t.Errorf("Otherland extent = %v, want %v", index.boothExtents["otherland"], want)
+	}
+}
+
+func TestBoothsInH3Cell(t *testing.T) {
+	index := NewGeoIndex(".")
+	booths, err := index.GetBoothsForState("goa")
+	if err != nil {
+		t.Skipf("goa booths not available: %v", err)
+	}
+
+	var booth *PollingBooth
+	for _, b := range booths {
+		if b.Lat != nil && b.Lon != nil {
+			booth = b
+			break
+		}
+	}
+	if booth == nil {
+		t.Skip("no goa booth with coordinates")
+	}
+
+	cellID, ok := index.BoothCell(booth)
+	if !ok {
+		t.Fatal("BoothCell() ok = false for booth with coordinates")
+	}
+
+	fineCell := LatLngToH3CellAtResolution(*booth.Lat, *booth.Lon, 11)
+	coarseCell := LatLngToH3CellAtResolution(*booth.Lat, *booth.Lon, 7)
+
+	for _, cell := range []string{cellID, fineCell, coarseCell} {
+		found, err := index.BoothsInH3Cell(cell)
+		if err != nil {
+			t.Fatalf("BoothsInH3Cell(%s) error: %v", cell, err)
+		}
+
+		contains := false
+		for _, b := range found {
+			if b == booth {
+				contains = true
+			}
+		}
+		if !contains {
+			t.Errorf("BoothsInH3Cell(%s) does not include booth %d", cell, booth.PartID)
+		}
+	}
+}
+
+func TestGetH3CellsForACWithContainment(t *testing.T) {
+	// Alpha with a lake in the middle
+	files := testDataFiles()
+	files[filepath.Join(BoundariesDir, "testland.geojson")] = `{"type": "FeatureCollection", "state_name": "Testland", "features": [
+		{"type": "Feature", "properties": {"objectid": 1, "uid": "T1", "state_ut": "Testland", "cons_code": 1, "cons_name": "Alpha"},
+		 "geometry": {"type": "Polygon", "coordinates": [
+			[[77, 12], [78, 12], [78, 13], [77, 13], [77, 12]],
+			[[77.4, 12.4], [77.6, 12.4], [77.6, 12.6], [77.4, 12.6], [77.4, 12.4]]]}}]}`
+	index := NewGeoIndex(writeTestData(t, files))
+
+	lake := h3utils.LatLngToCellAtResolution(12.5, 77.5, 7)
+	var counts []int
+	for _, mode := range []h3utils.ContainmentMode{h3utils.ContainmentFull, h3utils.ContainmentCentroid, h3utils.ContainmentOverlapping} {
+		cells, err := index.GetH3CellsForACWithContainment("testland", 1, 7, mode)
+		if err != nil {
+			t.Fatalf("GetH3CellsForACWithContainment(%s) error: %v", mode, err)
+		}
+		for _, cellID := range cells {
+			if cellID == lake {
+				t.Errorf("%s cells include the lake", mode)
+			}
+		}
+		counts = append(counts, len(cells))
+	}
+	if !(counts[0] < counts[1] && counts[1] < counts[2]) {
+		t.Errorf("cell counts full, centroid, overlapping = %v, want increasing", counts)
+	}
+
+	cells, err := index.GetH3CellsForAC("testland", 1, 7)
+	if err != nil || len(cells) != counts[1] {
+		t.Errorf("GetH3CellsForAC() = %d cells, %v, want the %d centroid cells", len(cells), err, counts[1])
+	}
+}
@@ -0,0 +1,493 @@
+package data
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	h3utils "github.com/politic-in/core/h3-utils"
+)
+
+// H3ACTableVersion is the current H3 AC table file format version
+const H3ACTableVersion = 3
+
+// h3ACTableMagic identifies an H3 AC table file
+var h3ACTableMagic = [8]byte{'P', 'O', 'L', 'H', '3', 'A', 'C', 'T'}
+
+// H3ACMatch is the entry of an H3ACTable for a cell
+type H3ACMatch struct {
+	AC ACNode // The AC covering the whole cell, unset for border cells
+
+	// Border is true if the cell straddles the border of an AC, with
+	// another AC or with no AC at all, or lies where the boundaries of
+	// several ACs overlap. Candidates lists the ACs it touches.
+	Border     bool
+	Candidates []ACNode
+}
+
+// H3ACTable maps H3 cells to the ACs covering them, for every state with
+// boundaries. Cells entirely inside an AC are stored compacted to the
+// coarsest ancestor inside it; cells crossing a border are stored at the
+// table's resolution with the ACs they touch. Build it with
+// GeoIndex.BuildH3ACTable and persist it with WriteTo and ReadH3ACTable.
+type H3ACTable struct {
+	resolution int
+	source     [32]byte // Fingerprint of the source files the table was built from
+	acs        []ACNode
+	interior   map[uint64]uint32   // Compacted cell -> index into acs
+	border     map[uint64][]uint32 // Cell at resolution -> indices into acs
+	coarsest   int                 // Coarsest resolution in interior
+}
+
+// h3ACTableData is the gob payload of a table file. Cells are sorted and
+// delta-encoded, which roughly halves the file.
+type h3ACTableData struct {
+	Resolution  int
+	ACs         []ACNode
+	Cells       []uint64 // Interior cells
+	CellACs     []uint32
+	BorderCells []uint64
+	BorderACs   [][]uint32
+}
+
+// Resolution returns the resolution of the table's border cells. Lookups
+// need a cell at this resolution or finer.
+func (t *H3ACTable) Resolution() int {
+	return t.resolution
+}
+
+// Len returns the number of compacted interior cells and of border cells stored
+func (t *H3ACTable) Len() (interior, border int) {
+	return len(t.interior), len(t.border)
+}
+
+// Lookup returns the table entry for a point
+func (t *H3ACTable) Lookup(lat, lng float64) (H3ACMatch, bool) {
+	return t.LookupCell(h3utils.LatLngToCellAtResolution(lat, lng, t.resolution))
+}
+
+// LookupCell returns the table entry for a cell at the table's resolution or
+// finer, walking up its ancestors to the compacted cell that holds it
+func (t *H3ACTable) LookupCell(cellID string) (H3ACMatch, bool) {
+	resolution, err := h3utils.GetResolution(cellID)
+	if err != nil || resolution < t.resolution {
+		return H3ACMatch{}, false
+	}
+	if resolution > t.resolution {
+		if cellID, err = h3utils.GetParent(cellID, t.resolution); err != nil {
+			return H3ACMatch{}, false
+		}
+	}
+
+	key, ok := h3CellKey(cellID)
+	if !ok {
+		return H3ACMatch{}, false
+	}
+	if acs, ok := t.border[key]; ok {
+		match := H3ACMatch{Border: true, Candidates: make([]ACNode, len(acs))}
+		for i, ac := range acs {
+			match.Candidates[i] = t.acs[ac]
+		}
+		return match, true
+	}
+
+	for res := t.resolution; res >= t.coarsest; res-- {
+		if res < t.resolution {
+			parent, err := h3utils.GetParent(cellID, res)
+			if err != nil {
+				return H3ACMatch{}, false
+			}
+			key, _ = h3CellKey(parent)
+		}
+		if ac, ok := t.interior[key]; ok {
+			return H3ACMatch{AC: t.acs[ac]}, true
+		}
+	}
+	return H3ACMatch{}, false
+}
+
+// h3CellKey returns a cell ID as the integer it encodes
+func h3CellKey(cellID string) (uint64, bool) {
+	key, err := strconv.ParseUint(cellID, 16, 64)
+	return key, err == nil
+}
+
+// h3CellID returns the cell ID of an integer key
+func h3CellID(key uint64) string {
+	return strconv.FormatUint(key, 16)
+}
+
+// BuildH3ACTable builds the cell to AC table of every state with boundaries
+// at a resolution. It loads every state's boundaries and takes a few seconds
+// per state at resolution 9.
+func (g *GeoIndex) BuildH3ACTable(resolution int) (*H3ACTable, error) {
+	if resolution < h3utils.MinResolution || resolution > h3utils.MaxResolution {
+		return nil, fmt.Errorf("%w: %d", h3utils.ErrInvalidResolution, resolution)
+	}
+
+	source, err := sourceFingerprint(g.fsys)
+	if err != nil {
+		return nil, fmt.Errorf("fingerprinting source data: %w", err)
+	}
+	states, err := g.availableBoundaryStates()
+	if err != nil {
+		return nil, err
+	}
+
+	table := &H3ACTable{
+		resolution: resolution,
+		source:     source,
+		interior:   make(map[uint64]uint32),
+		border:     make(map[uint64][]uint32),
+		coarsest:   resolution,
+	}
+
+	overlaps := make(map[uint64][]uint32)
+	for _, stateName := range states {
+		stateSlug := ToSlug(stateName)
+		boundaries, err := g.GetBoundariesForState(stateSlug)
+		if err != nil {
+			return nil, fmt.Errorf("loading boundaries for %s: %w", stateSlug, err)
+		}
+
+		for _, boundary := range boundaries {
+			if err := table.addBoundary(stateSlug, boundary, overlaps); err != nil {
+				return nil, fmt.Errorf("filling %s/%d: %w", stateSlug, boundary.ConsCode, err)
+			}
+		}
+	}
+
+	if err := table.resolveOverlaps(overlaps); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// addBoundary adds an AC's interior cells, compacted, and its border cells.
+// Interior cells already claimed by another AC are recorded in overlaps.
+func (t *H3ACTable) addBoundary(stateSlug string, boundary *ACBoundary, overlaps map[uint64][]uint32) error {
+	var parts []h3utils.Polygon
+	for _, polygon := range boundary.GetPolygons() {
+		if len(polygon) == 0 || len(polygon[0]) < 3 {
+			continue
+		}
+		part := h3utils.Polygon{Outer: latLngRing(polygon[0])}
+		for _, hole := range polygon[1:] {
+			if len(hole) >= 3 {
+				part.Holes = append(part.Holes, latLngRing(hole))
+			}
+		}
+		parts = append(parts, part)
+	}
+
+	inside, border, err := h3utils.PolygonsToCellCover(parts, t.resolution)
+	if err != nil {
+		return err
+	}
+	compacted, err := h3utils.CompactCells(inside)
+	if err != nil {
+		return err
+	}
+
+	ac := uint32(len(t.acs))
+	t.acs = append(t.acs, ACNode{StateSlug: stateSlug, ConsCode: boundary.ConsCode})
+	for _, cellID := range compacted {
+		key, _ := h3CellKey(cellID)
+		if other, ok := t.interior[key]; ok && other != ac {
+			overlaps[key] = appendUnique(appendUnique(overlaps[key], other), ac)
+		}
+		t.interior[key] = ac
+		if resolution, err := h3utils.GetResolution(cellID); err == nil && resolution < t.coarsest {
+			t.coarsest = resolution
+		}
+	}
+	for _, cellID := range border {
+		key, _ := h3CellKey(cellID)
+		t.border[key] = append(t.border[key], ac)
+	}
+	return nil
+}
+
+// resolveOverlaps handles cells claimed by more than one AC, which only
+// happens where source boundaries overlap. Interior cells inside another
+// AC's interior cell, or claimed twice, are replaced by border cells listing
+// every AC claiming them. Border cells that also lie in another AC's
+// interior list that AC among the candidates.
+func (t *H3ACTable) resolveOverlaps(overlaps map[uint64][]uint32) error {
+	for key, ac := range t.interior {
+		cellID := h3CellID(key)
+		resolution, err := h3utils.GetResolution(cellID)
+		if err != nil {
+			return err
+		}
+		for res := resolution - 1; res >= t.coarsest; res-- {
+			ancestor, err := h3utils.GetParent(cellID, res)
+			if err != nil {
+				return err
+			}
+			ancestorKey, _ := h3CellKey(ancestor)
+			if other, ok := t.interior[ancestorKey]; ok && other != ac {
+				overlaps[key] = appendUnique(appendUnique(overlaps[key], ac), other)
+			}
+		}
+	}
+
+	for key, acs := range overlaps {
+		delete(t.interior, key)
+		children, err := h3utils.UncompactCells([]string{h3CellID(key)}, t.resolution)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			childKey, _ := h3CellKey(child)
+			for _, ac := range acs {
+				t.border[childKey] = appendUnique(t.border[childKey], ac)
+			}
+		}
+	}
+
+	for key, acs := range t.border {
+		cellID := h3CellID(key)
+		for res := t.resolution; res >= t.coarsest; res-- {
+			ancestor := cellID
+			if res < t.resolution {
+				ancestor, _ = h3utils.GetParent(cellID, res)
+			}
+			ancestorKey, _ := h3CellKey(ancestor)
+			if ac, ok := t.interior[ancestorKey]; ok {
+				t.border[key] = appendUnique(acs, ac)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// appendUnique appends v to list unless it is already there
+func appendUnique(list []uint32, v uint32) []uint32 {
+	if containsUint32(list, v) {
+		return list
+	}
+	return append(list, v)
+}
+
+// containsUint32 returns true if list contains v
+func containsUint32(list []uint32, v uint32) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteTo writes the table as a checksummed binary file
+func (t *H3ACTable) WriteTo(w io.Writer) (int64, error) {
+	data := h3ACTableData{Resolution: t.resolution, ACs: t.acs}
+
+	cells := make([]uint64, 0, len(t.interior))
+	for key := range t.interior {
+		cells = append(cells, key)
+	}
+	sort.Slice(cells, func(i, j int) bool { return cells[i] < cells[j] })
+	data.CellACs = make([]uint32, len(cells))
+	for i, key := range cells {
+		data.CellACs[i] = t.interior[key]
+	}
+	data.Cells = deltaEncode(cells)
+
+	borderCells := make([]uint64, 0, len(t.border))
+	for key := range t.border {
+		borderCells = append(borderCells, key)
+	}
+	sort.Slice(borderCells, func(i, j int) bool { return borderCells[i] < borderCells[j] })
+	data.BorderACs = make([][]uint32, len(borderCells))
+	for i, key := range borderCells {
+		data.BorderACs[i] = t.border[key]
+	}
+	data.BorderCells = deltaEncode(borderCells)
+
+	var payload bytes.Buffer
+	if err := gob.NewEncoder(&payload).Encode(data); err != nil {
+		return 0, fmt.Errorf("encoding H3 AC table: %w", err)
+	}
+
+	header := snapshotHeader{
+		Magic:      h3ACTableMagic,
+		Version:    H3ACTableVersion,
+		Source:     t.source,
+		Checksum:   sha256.Sum256(payload.Bytes()),
+		PayloadLen: uint64(payload.Len()),
+	}
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return 0, err
+	}
+	n, err := payload.WriteTo(w)
+	return n + int64(binary.Size(header)), err
+}
+
+// ReadH3ACTable reads a table written by WriteTo
+func ReadH3ACTable(r io.Reader) (*H3ACTable, error) {
+	var header snapshotHeader
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("%w: reading H3 AC table header: %v", ErrSnapshotInvalid, err)
+	}
+	if header.Magic != h3ACTableMagic {
+		return nil, fmt.Errorf("%w: not an H3 AC table", ErrSnapshotInvalid)
+	}
+	if header.Version != H3ACTableVersion {
+		return nil, fmt.Errorf("%w: H3 AC table version %d, want %d", ErrSnapshotInvalid, header.Version, H3ACTableVersion)
+	}
+
+	hash := sha256.New()
+	payload := io.TeeReader(io.LimitReader(r, int64(header.PayloadLen)), hash)
+	var data h3ACTableData
+	if err := gob.NewDecoder(payload).Decode(&data); err != nil {
+		return nil, fmt.Errorf("%w: decoding H3 AC table: %v", ErrSnapshotInvalid, err)
+	}
+	if _, err := io.Copy(io.Discard, payload); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(hash.Sum(nil), header.Checksum[:]) {
+		return nil, fmt.Errorf("%w: H3 AC table checksum mismatch", ErrSnapshotInvalid)
+	}
+	if len(data.CellACs) != len(data.Cells) || len(data.BorderACs) != len(data.BorderCells) {
+		return nil, fmt.Errorf("%w: H3 AC table cell counts differ", ErrSnapshotInvalid)
+	}
+
+	table := &H3ACTable{
+		resolution: data.Resolution,
+		source:     header.Source,
+		acs:        data.ACs,
+		interior:   make(map[uint64]uint32, len(data.Cells)),
+		border:     make(map[uint64][]uint32, len(data.BorderCells)),
+		coarsest:   data.Resolution,
+	}
+	for i, key := range deltaDecode(data.Cells) {
+		if int(data.CellACs[i]) >= len(table.acs) {
+			return nil, fmt.Errorf("%w: H3 AC table references AC %d of %d", ErrSnapshotInvalid, data.CellACs[i], len(table.acs))
+		}
+		table.interior[key] = data.CellACs[i]
+		if resolution, err := h3utils.GetResolution(h3CellID(key)); err == nil && resolution < table.coarsest {
+			table.coarsest = resolution
+		}
+	}
+	for i, key := range deltaDecode(data.BorderCells) {
+		for _, ac := range data.BorderACs[i] {
+			if int(ac) >= len(table.acs) {
+				return nil, fmt.Errorf("%w: H3 AC table references AC %d of %d", ErrSnapshotInvalid, ac, len(table.acs))
+			}
+		}
+		table.border[key] = data.BorderACs[i]
+	}
+	return table, nil
+}
+
+// deltaEncode replaces sorted values with their differences, which gob
+// encodes in fewer bytes
+func deltaEncode(sorted []uint64) []uint64 {
+	deltas := make([]uint64, len(sorted))
+	var prev uint64
+	for i, v := range sorted {
+		deltas[i], prev = v-prev, v
+	}
+	return deltas
+}
+
+// deltaDecode reverses deltaEncode
+func deltaDecode(deltas []uint64) []uint64 {
+	values := make([]uint64, len(deltas))
+	var prev uint64
+	for i, d := range deltas {
+		prev += d
+		values[i] = prev
+	}
+	return values
+}
+
+// UseH3ACTable makes FindACAtPointAllStates, and with it Resolve, look points
+// up in a table: O(1) inside an AC, with a polygon check only against the
+// candidates of border cells. It returns ErrSnapshotStale if the table was
+// built from other source data. The table is dropped on reload.
+func (g *GeoIndex) UseH3ACTable(table *H3ACTable) error {
+	source, err := sourceFingerprint(g.fsys)
+	if err != nil {
+		return fmt.Errorf("fingerprinting source data: %w", err)
+	}
+	if table.source != source {
+		return fmt.Errorf("%w: H3 AC table", ErrSnapshotStale)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.h3ACTable = table
+	return nil
+}
+
+// LoadH3ACTable loads a table file and uses it, see UseH3ACTable. A missing,
+// stale or unreadable file, or one at another resolution, is rebuilt at the
+// resolution and saved. If only saving fails, the table is in use and the
+// save error is returned.
+func (g *GeoIndex) LoadH3ACTable(path string, resolution int) error {
+	if f, err := os.Open(path); err == nil {
+		table, err := ReadH3ACTable(f)
+		f.Close()
+		if err == nil && table.resolution == resolution && g.UseH3ACTable(table) == nil {
+			return nil
+		}
+	}
+
+	table, err := g.BuildH3ACTable(resolution)
+	if err != nil {
+		return err
+	}
+	if err := g.UseH3ACTable(table); err != nil {
+		return err
+	}
+
+	err = writeFileAtomic(path, func(w io.Writer) error {
+		_, err := table.WriteTo(w)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("saving H3 AC table: %w", err)
+	}
+	return nil
+}
+
+// findACWithTable looks a point up in an H3 AC table
+func (g *GeoIndex) findACWithTable(table *H3ACTable, lat, lng float64) (*ACBoundary, string, error) {
+	match, ok := table.Lookup(lat, lng)
+	if !ok {
+		return nil, "", fmt.Errorf("%w: no AC found at (%.6f, %.6f) in any state", ErrACNotFound, lat, lng)
+	}
+
+	if !match.Border {
+		boundary, err := g.GetBoundaryForAC(match.AC.StateSlug, match.AC.ConsCode)
+		if err != nil {
+			return nil, "", err
+		}
+		return boundary, match.AC.StateSlug, nil
+	}
+
+	for _, candidate := range match.Candidates {
+		boundary, err := g.GetBoundaryForAC(candidate.StateSlug, candidate.ConsCode)
+		if errors.Is(err, ErrBoundaryNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if boundary.ContainsPoint(lat, lng) {
+			return boundary, candidate.StateSlug, nil
+		}
+	}
+	return nil, "", fmt.Errorf("%w: no AC found at (%.6f, %.6f) in any state", ErrACNotFound, lat, lng)
+}
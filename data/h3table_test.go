@@ -0,0 +1,163 @@
+package data
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestH3ACTableLookup(t *testing.T) {
+	index := newTestIndex(t)
+	table, err := index.BuildH3ACTable(6)
+	if err != nil {
+		t.Fatalf("BuildH3ACTable() error: %v", err)
+	}
+	if interior, border := table.Len(); interior == 0 || border == 0 {
+		t.Fatalf("Len() = %d interior, %d border, want both", interior, border)
+	}
+	if table.coarsest >= 6 {
+		t.Errorf("coarsest resolution = %d, want interior cells compacted below 6", table.coarsest)
+	}
+
+	match, ok := table.Lookup(12.5, 77.5)
+	if !ok || match.Border || match.AC != (ACNode{"testland", 1}) {
+		t.Errorf("Lookup() inside Alpha = %+v, %v", match, ok)
+	}
+
+	match, ok = table.Lookup(12.5, 78.001)
+	if !ok || !match.Border {
+		t.Fatalf("Lookup() on the Alpha-Beta border = %+v, %v, want a border cell", match, ok)
+	}
+	if want := []ACNode{{"testland", 1}, {"testland", 2}}; !reflect.DeepEqual(match.Candidates, want) {
+		t.Errorf("border candidates = %v, want %v", match.Candidates, want)
+	}
+
+	if _, ok := table.Lookup(20, 70); ok {
+		t.Error("Lookup() outside every AC found an entry")
+	}
+	if _, ok := table.LookupCell("invalid"); ok {
+		t.Error("LookupCell() of an invalid cell found an entry")
+	}
+}
+
+func TestH3ACTableOverlappingInteriors(t *testing.T) {
+	// Overlap lies across the eastern half of Alpha and the western half of Beta
+	files := testDataFiles()
+	files[filepath.Join(BoundariesDir, "otherland.geojson")] = `{"type": "FeatureCollection", "state_name": "Otherland", "features": [` +
+		testSquareFeature(1, "Overlap", 77.5, 12) + `]}`
+	table, err := NewGeoIndex(writeTestData(t, files)).BuildH3ACTable(6)
+	if err != nil {
+		t.Fatalf("BuildH3ACTable() error: %v", err)
+	}
+
+	match, ok := table.Lookup(12.5, 77.75)
+	if !ok || !match.Border {
+		t.Fatalf("Lookup() inside Alpha and Overlap = %+v, %v, want a cell listing both", match, ok)
+	}
+	got := map[ACNode]bool{}
+	for _, candidate := range match.Candidates {
+		got[candidate] = true
+	}
+	if want := map[ACNode]bool{{"testland", 1}: true, {"otherland", 1}: true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("candidates = %v, want Alpha and Overlap", match.Candidates)
+	}
+
+	if match, ok := table.Lookup(12.5, 77.2); !ok || match.Border || match.AC != (ACNode{"testland", 1}) {
+		t.Errorf("Lookup() inside Alpha only = %+v, %v", match, ok)
+	}
+}
+
+func TestUseH3ACTable(t *testing.T) {
+	dataDir := writeTestData(t, testDataFiles())
+	scan := NewGeoIndex(dataDir)
+	index := NewGeoIndex(dataDir)
+
+	table, err := index.BuildH3ACTable(6)
+	if err != nil {
+		t.Fatalf("BuildH3ACTable() error: %v", err)
+	}
+	if err := index.UseH3ACTable(table); err != nil {
+		t.Fatalf("UseH3ACTable() error: %v", err)
+	}
+
+	// Lookups through the table agree with scanning the polygons
+	for lat := 11.95; lat < 13.1; lat += 0.05 {
+		for lng := 76.95; lng < 80.1; lng += 0.05 {
+			want, _, wantErr := scan.FindACAtPointAllStates(lat, lng)
+			got, _, err := index.FindACAtPointAllStates(lat, lng)
+			if (err == nil) != (wantErr == nil) || (err == nil && got.ConsCode != want.ConsCode) {
+				t.Fatalf("FindACAtPointAllStates(%.2f, %.2f) with table = %v, %v, want %v, %v", lat, lng, got, err, want, wantErr)
+			}
+		}
+	}
+
+	if err := index.ReloadState("testland"); err != nil {
+		t.Fatalf("ReloadState() error: %v", err)
+	}
+	if index.h3ACTable != nil {
+		t.Error("ReloadState() kept the H3 AC table")
+	}
+
+	// A table built before the source changed is refused
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dataDir, PartiesFile), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.UseH3ACTable(table); !errors.Is(err, ErrSnapshotStale) {
+		t.Errorf("UseH3ACTable() after source change error = %v, want ErrSnapshotStale", err)
+	}
+}
+
+func TestH3ACTableFile(t *testing.T) {
+	dataDir := writeTestData(t, testDataFiles())
+	table, err := NewGeoIndex(dataDir).BuildH3ACTable(6)
+	if err != nil {
+		t.Fatalf("BuildH3ACTable() error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := table.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error: %v", err)
+	}
+	encoded := buf.Bytes()
+
+	read, err := ReadH3ACTable(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatalf("ReadH3ACTable() error: %v", err)
+	}
+	if !reflect.DeepEqual(read, table) {
+		t.Error("ReadH3ACTable() does not match the written table")
+	}
+
+	corrupt := bytes.Clone(encoded)
+	corrupt[len(corrupt)-1] ^= 0xff
+	if _, err := ReadH3ACTable(bytes.NewReader(corrupt)); !errors.Is(err, ErrSnapshotInvalid) {
+		t.Errorf("ReadH3ACTable() of corrupt table error = %v, want ErrSnapshotInvalid", err)
+	}
+
+	// LoadH3ACTable builds and saves a missing table, then reads it back
+	path := filepath.Join(t.TempDir(), "cells.h3ac")
+	built := NewGeoIndex(dataDir)
+	if err := built.LoadH3ACTable(path, 6); err != nil {
+		t.Fatalf("LoadH3ACTable() build error: %v", err)
+	}
+	loaded := NewGeoIndex(dataDir)
+	if err := loaded.LoadH3ACTable(path, 6); err != nil {
+		t.Fatalf("LoadH3ACTable() error: %v", err)
+	}
+	if loaded.h3ACTable == nil || loaded.h3ACTable.Resolution() != 6 {
+		t.Fatal("LoadH3ACTable() did not put the table in use")
+	}
+	if loaded.loadedBounds["testland"] {
+		t.Error("LoadH3ACTable() rebuilt a table it could read")
+	}
+
+	// Another resolution rebuilds it
+	if err := loaded.LoadH3ACTable(path, 5); err != nil || loaded.h3ACTable.Resolution() != 5 {
+		t.Errorf("LoadH3ACTable() at another resolution = %v, want a rebuilt table", err)
+	}
+}
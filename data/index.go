@@ -1,14 +1,20 @@
 package data
 
 import (
+	"container/list"
+	"errors"
 	"fmt"
+	"io/fs"
 	"sync"
+
+	h3utils "github.com/politic-in/core/h3-utils"
 )
 
 // GeoIndex provides fast O(1) lookups for Indian geographic and electoral data.
 // It builds hierarchical indices: State → District → AC → Booth
 type GeoIndex struct {
-	dataDir string
+	fsys   fs.FS // Data directory layout, see the File names constants
+	config GeoIndexConfig
 
 	// State indices
 	statesByID   map[string]*State // "AP" -> State
@@ -25,16 +31,42 @@ type GeoIndex struct {
 	acsByID      map[string]*AssemblyConstituency   // "state_slug:ac_1" -> AC
 	acsByNumber  map[string]*AssemblyConstituency   // "state_slug:123" -> AC
 	acsByNameMap map[string]*AssemblyConstituency   // "state_slug:ac_slug" -> AC
+	acsByAlias   map[string]*AssemblyConstituency   // "state_slug:alias_slug" -> AC
+	acsByCode    map[string]*AssemblyConstituency   // "state_slug:cons_code" -> AC, via the boundary lookup
+
+	// AC names and aliases prepared for fuzzy matching
+	acNamesByState map[string][]acNameEntry     // state slug -> names
+	acNameAliases  map[string]map[string]string // state name -> AC name -> alias, as loaded
+
+	// PC indices
+	pcsByState  map[string][]*ParliamentaryConstituency // state slug -> PCs
+	pcsByNumber map[string]*ParliamentaryConstituency   // "state_slug:pc_number" -> PC
+	pcByAC      map[string]*ParliamentaryConstituency   // "state_slug:ac_number" -> PC
 
 	// Booth indices
-	boothsByState    map[string][]*PollingBooth // state slug -> booths
-	boothsByAC       map[string][]*PollingBooth // "state_slug:ac_number" -> booths
-	boothsByDistrict map[string][]*PollingBooth // "state_slug:district_slug" -> booths
-	boothByPartID    map[string]*PollingBooth   // "state_slug:ac:part_id" -> booth
+	boothsByState     map[string][]*PollingBooth // state slug -> booths
+	boothsByAC        map[string][]*PollingBooth // "state_slug:ac_number" -> booths
+	boothsByDistrict  map[string][]*PollingBooth // "state_slug:district_slug" -> booths
+	boothByPartID     map[string]*PollingBooth   // "state_slug:ac:part_id" -> booth
+	boothsByCell      map[string][]*PollingBooth // H3 cell at config.BoothCellResolution -> booths
+	boothCellsByState map[string][]string        // state slug -> distinct booth cells, for eviction
 
 	// Boundary indices
 	boundariesByState map[string][]*ACBoundary // state slug -> boundaries
 	boundaryByAC      map[string]*ACBoundary   // "state_slug:cons_code" -> boundary
+	boundaryIndex     map[string]*spatialIndex // state slug -> R-tree over boundariesByState
+	boundaryLODs      map[string][]*ACBoundary // "state_slug:level" -> simplified boundaries
+
+	// District boundary indices
+	districtBoundariesByState map[string][]*DistrictBoundary // state slug -> district boundaries
+	districtBoundaryByName    map[string]*DistrictBoundary   // "state_slug:district_slug" -> boundary
+	districtBoundaryIndex     map[string]*spatialIndex       // state slug -> R-tree over districtBoundariesByState
+
+	// AC adjacency across every state, built on first use by ACGraph
+	acGraph *ACGraph
+
+	// Cell to AC table set by UseH3ACTable, kept across snapshot loads
+	h3ACTable *H3ACTable
 
 	// Party indices
 	partiesByID        map[int]*Party
@@ -44,36 +76,144 @@ type GeoIndex struct {
 	constituencyLookup []ConstituencyBoundaryLookup
 
 	// Load state tracking
-	loadedStates map[string]bool
-	loadedBounds map[string]bool
-	mu           sync.RWMutex
+	loadedStates    map[string]bool
+	loadedBounds    map[string]bool
+	loadedDistricts map[string]bool // states with district boundaries loaded
+	availableBounds []string        // cached boundary file listing
+
+	// Per-state bounding boxes, kept when the data they describe is evicted
+	boundaryExtents map[string][4]float64 // state slug -> extent of its AC boundaries
+	boothExtents    map[string][4]float64 // state slug -> extent of its located booths
+
+	// Memory budget tracking for per-state booths and boundaries
+	residentLRU    *list.List               // *residentEntry, most recently used first
+	residentByKey  map[string]*list.Element // "booths:state_slug" / "boundaries:state_slug" -> LRU element
+	residentBytes  int64                    // estimated bytes held by residentLRU entries
+	cacheHits      uint64
+	cacheMisses    uint64
+	cacheEvictions uint64
+
+	// Reload tracking
+	generation uint64
+	onChange   []func(ChangeEvent)
+
+	mu sync.RWMutex
+}
+
+// GeoIndexConfig configures a GeoIndex
+type GeoIndexConfig struct {
+	// BoothCellResolution is the H3 resolution used to index booths by location
+	BoothCellResolution int
+
+	// MemoryBudgetBytes caps the estimated memory held by per-state booths, AC
+	// boundaries and district boundaries. When exceeded, the least recently
+	// used state data is evicted and reloaded on next use. 0 means unlimited. Snapshots only
+	// contain the state data resident when they are written.
+	MemoryBudgetBytes int64
+
+	// LODTolerancesM are the simplification tolerances, in metres, of the
+	// levels of detail served by GetBoundariesForStateAtLOD. Level 0 is the
+	// full boundary and level i uses LODTolerancesM[i-1].
+	LODTolerancesM []float64
+
+	// CoarseToleranceM is the tolerance of the simplified copy that indexed
+	// boundaries use in ContainsPoint to decide points away from the border
+	// without scanning every vertex. 0 disables it.
+	CoarseToleranceM float64
+
+	// ACGraph configures the AC adjacency graph built by GeoIndex.ACGraph
+	ACGraph ACGraphConfig
+
+	// Edition tags every loaded AC, AC boundary and booth. The zero value
+	// leaves records untagged. See EditionIndex.
+	Edition Edition
+}
+
+// DefaultGeoIndexConfig returns the default configuration
+func DefaultGeoIndexConfig() GeoIndexConfig {
+	return GeoIndexConfig{
+		BoothCellResolution: h3utils.DefaultResolution,
+		LODTolerancesM:      []float64{10, 50, 250},
+		CoarseToleranceM:    100,
+		ACGraph:             DefaultACGraphConfig(),
+	}
 }
 
 // NewGeoIndex creates a new geographic index from the given data directory
 func NewGeoIndex(dataDir string) *GeoIndex {
-	return &GeoIndex{
-		dataDir:            dataDir,
-		statesByID:         make(map[string]*State),
-		statesByName:       make(map[string]*State),
-		statesBySlug:       make(map[string]*State),
-		districtsByID:      make(map[int]*District),
-		districtsByState:   make(map[string][]*District),
-		districtsByNameMap: make(map[string]*District),
-		acsByState:         make(map[string][]*AssemblyConstituency),
-		acsByID:            make(map[string]*AssemblyConstituency),
-		acsByNumber:        make(map[string]*AssemblyConstituency),
-		acsByNameMap:       make(map[string]*AssemblyConstituency),
-		boothsByState:      make(map[string][]*PollingBooth),
-		boothsByAC:         make(map[string][]*PollingBooth),
-		boothsByDistrict:   make(map[string][]*PollingBooth),
-		boothByPartID:      make(map[string]*PollingBooth),
-		boundariesByState:  make(map[string][]*ACBoundary),
-		boundaryByAC:       make(map[string]*ACBoundary),
-		partiesByID:        make(map[int]*Party),
-		partiesByShortName: make(map[string]*Party),
-		loadedStates:       make(map[string]bool),
-		loadedBounds:       make(map[string]bool),
-	}
+	return NewGeoIndexWithConfig(dataDir, DefaultGeoIndexConfig())
+}
+
+// NewGeoIndexWithConfig creates a geographic index with custom configuration
+func NewGeoIndexWithConfig(dataDir string, config GeoIndexConfig) *GeoIndex {
+	return NewGeoIndexFSWithConfig(dirFS(dataDir), config)
+}
+
+// NewGeoIndexFS creates a geographic index that reads the data directory
+// layout from a file system, such as an embedded or zip-backed one
+func NewGeoIndexFS(fsys fs.FS) *GeoIndex {
+	return NewGeoIndexFSWithConfig(fsys, DefaultGeoIndexConfig())
+}
+
+// NewGeoIndexFSWithConfig creates a file system backed geographic index with custom configuration
+func NewGeoIndexFSWithConfig(fsys fs.FS, config GeoIndexConfig) *GeoIndex {
+	if config.BoothCellResolution < h3utils.MinResolution || config.BoothCellResolution > h3utils.MaxResolution {
+		config.BoothCellResolution = h3utils.DefaultResolution
+	}
+
+	g := &GeoIndex{
+		fsys:   fsys,
+		config: config,
+	}
+	g.resetLocked()
+	return g
+}
+
+// resetLocked replaces every index with an empty one (must hold lock)
+func (g *GeoIndex) resetLocked() {
+	g.statesByID = make(map[string]*State)
+	g.statesByName = make(map[string]*State)
+	g.statesBySlug = make(map[string]*State)
+	g.districtsByID = make(map[int]*District)
+	g.districtsByState = make(map[string][]*District)
+	g.districtsByNameMap = make(map[string]*District)
+	g.acsByState = make(map[string][]*AssemblyConstituency)
+	g.acsByID = make(map[string]*AssemblyConstituency)
+	g.acsByNumber = make(map[string]*AssemblyConstituency)
+	g.acsByNameMap = make(map[string]*AssemblyConstituency)
+	g.acsByAlias = make(map[string]*AssemblyConstituency)
+	g.acsByCode = make(map[string]*AssemblyConstituency)
+	g.acNamesByState = make(map[string][]acNameEntry)
+	g.pcsByState = make(map[string][]*ParliamentaryConstituency)
+	g.pcsByNumber = make(map[string]*ParliamentaryConstituency)
+	g.pcByAC = make(map[string]*ParliamentaryConstituency)
+	g.boothsByState = make(map[string][]*PollingBooth)
+	g.boothsByAC = make(map[string][]*PollingBooth)
+	g.boothsByDistrict = make(map[string][]*PollingBooth)
+	g.boothByPartID = make(map[string]*PollingBooth)
+	g.boothsByCell = make(map[string][]*PollingBooth)
+	g.boothCellsByState = make(map[string][]string)
+	g.boundariesByState = make(map[string][]*ACBoundary)
+	g.boundaryByAC = make(map[string]*ACBoundary)
+	g.boundaryIndex = make(map[string]*spatialIndex)
+	g.boundaryLODs = make(map[string][]*ACBoundary)
+	g.districtBoundariesByState = make(map[string][]*DistrictBoundary)
+	g.districtBoundaryByName = make(map[string]*DistrictBoundary)
+	g.districtBoundaryIndex = make(map[string]*spatialIndex)
+	g.partiesByID = make(map[int]*Party)
+	g.partiesByShortName = make(map[string]*Party)
+	g.loadedStates = make(map[string]bool)
+	g.loadedBounds = make(map[string]bool)
+	g.loadedDistricts = make(map[string]bool)
+	g.constituencyLookup = nil
+	g.acNameAliases = nil
+	g.availableBounds = nil
+	g.boundaryExtents = make(map[string][4]float64)
+	g.boothExtents = make(map[string][4]float64)
+	g.acGraph = nil
+	g.residentLRU = list.New()
+	g.residentByKey = make(map[string]*list.Element)
+	g.residentBytes = 0
 }
 
 // LoadAll loads all available data into the index
@@ -96,6 +236,16 @@ func (g *GeoIndex) LoadAll() error {
 		return fmt.Errorf("loading constituencies: %w", err)
 	}
 
+	// Load AC name aliases - optional
+	if err := g.loadACNameAliasesLocked(); err != nil && !errors.Is(err, ErrFileNotFound) {
+		return fmt.Errorf("loading AC name aliases: %w", err)
+	}
+
+	// Load parliamentary constituencies - optional, the PC dataset might not be present
+	if err := g.loadParliamentaryConstituenciesLocked(); err != nil && !errors.Is(err, ErrFileNotFound) {
+		return fmt.Errorf("loading parliamentary constituencies: %w", err)
+	}
+
 	// Load parties
 	if err := g.loadPartiesLocked(); err != nil {
 		return fmt.Errorf("loading parties: %w", err)
@@ -112,28 +262,36 @@ func (g *GeoIndex) LoadAll() error {
 
 // loadStatesLocked loads states (must hold lock)
 func (g *GeoIndex) loadStatesLocked() error {
-	states, err := LoadStates(g.dataDir)
+	states, err := LoadStatesFS(g.fsys)
 	if err != nil {
 		return err
 	}
+	g.indexStatesLocked(states)
+	return nil
+}
 
+// indexStatesLocked indexes states (must hold lock)
+func (g *GeoIndex) indexStatesLocked(states []State) {
 	for i := range states {
 		state := &states[i]
 		g.statesByID[state.StateID] = state
 		g.statesByName[state.Name] = state
 		g.statesBySlug[state.Slug()] = state
 	}
-
-	return nil
 }
 
 // loadDistrictsLocked loads districts (must hold lock)
 func (g *GeoIndex) loadDistrictsLocked() error {
-	districts, err := LoadDistricts(g.dataDir)
+	districts, err := LoadDistrictsFS(g.fsys)
 	if err != nil {
 		return err
 	}
+	g.indexDistrictsLocked(districts)
+	return nil
+}
 
+// indexDistrictsLocked indexes districts (must hold lock)
+func (g *GeoIndex) indexDistrictsLocked(districts []District) {
 	for i := range districts {
 		district := &districts[i]
 		g.districtsByID[district.ID] = district
@@ -144,23 +302,27 @@ func (g *GeoIndex) loadDistrictsLocked() error {
 		key := fmt.Sprintf("%s:%s", stateSlug, district.Slug())
 		g.districtsByNameMap[key] = district
 	}
-
-	return nil
 }
 
 // loadConstituenciesLocked loads constituencies (must hold lock)
 func (g *GeoIndex) loadConstituenciesLocked() error {
-	acMap, err := LoadConstituencies(g.dataDir)
+	acMap, err := LoadConstituenciesFS(g.fsys)
 	if err != nil {
 		return err
 	}
+	g.indexConstituenciesLocked(acMap)
+	return nil
+}
 
+// indexConstituenciesLocked indexes constituencies by state name (must hold lock)
+func (g *GeoIndex) indexConstituenciesLocked(acMap map[string][]AssemblyConstituency) {
 	for stateName, acs := range acMap {
 		stateSlug := ToSlug(stateName)
 		acList := make([]*AssemblyConstituency, len(acs))
 
 		for i := range acs {
 			ac := &acs[i]
+			ac.Edition = g.config.Edition.Name
 			acList[i] = ac
 
 			// Index by various keys
@@ -172,56 +334,116 @@ func (g *GeoIndex) loadConstituenciesLocked() error {
 
 			nameKey := fmt.Sprintf("%s:%s", stateSlug, ToSlug(ac.Name))
 			g.acsByNameMap[nameKey] = ac
+
+			g.acNamesByState[stateSlug] = append(g.acNamesByState[stateSlug], newACNameEntry(ac, ac.Name))
 		}
 
 		g.acsByState[stateSlug] = acList
 	}
+}
 
+// loadParliamentaryConstituenciesLocked loads PCs and the AC -> PC mapping (must hold lock)
+func (g *GeoIndex) loadParliamentaryConstituenciesLocked() error {
+	pcMap, err := LoadParliamentaryConstituenciesFS(g.fsys)
+	if err != nil {
+		return err
+	}
+	g.indexParliamentaryConstituenciesLocked(pcMap)
 	return nil
 }
 
+// indexParliamentaryConstituenciesLocked indexes PCs by state name (must hold lock)
+func (g *GeoIndex) indexParliamentaryConstituenciesLocked(pcMap map[string][]ParliamentaryConstituency) {
+	for stateName, pcs := range pcMap {
+		stateSlug := ToSlug(stateName)
+		pcList := make([]*ParliamentaryConstituency, len(pcs))
+
+		for i := range pcs {
+			pc := &pcs[i]
+			pcList[i] = pc
+
+			numKey := fmt.Sprintf("%s:%d", stateSlug, pc.PCNumber)
+			g.pcsByNumber[numKey] = pc
+
+			for _, acNumber := range pc.ACNumbers {
+				acKey := fmt.Sprintf("%s:%d", stateSlug, acNumber)
+				g.pcByAC[acKey] = pc
+			}
+		}
+
+		g.pcsByState[stateSlug] = pcList
+	}
+}
+
 // loadPartiesLocked loads parties (must hold lock)
 func (g *GeoIndex) loadPartiesLocked() error {
-	parties, err := LoadParties(g.dataDir)
+	parties, err := LoadPartiesFS(g.fsys)
 	if err != nil {
 		return err
 	}
+	g.indexPartiesLocked(parties)
+	return nil
+}
 
+// indexPartiesLocked indexes parties (must hold lock)
+func (g *GeoIndex) indexPartiesLocked(parties []Party) {
 	for i := range parties {
 		party := &parties[i]
 		g.partiesByID[party.ID] = party
 		g.partiesByShortName[party.ShortName] = party
 	}
-
-	return nil
 }
 
 // loadConstituencyLookupLocked loads constituency lookup (must hold lock)
 func (g *GeoIndex) loadConstituencyLookupLocked() error {
-	lookup, err := LoadConstituencyLookup(g.dataDir)
+	lookup, err := LoadConstituencyLookupFS(g.fsys)
 	if err != nil {
 		return err
 	}
-	g.constituencyLookup = lookup
+	g.indexConstituencyLookupLocked(lookup)
 	return nil
 }
 
+// indexConstituencyLookupLocked indexes the constituency lookup (must hold lock)
+func (g *GeoIndex) indexConstituencyLookupLocked(lookup []ConstituencyBoundaryLookup) {
+	g.constituencyLookup = lookup
+
+	// Link boundary cons codes to AC metadata, which is numbered across all states
+	for _, entry := range lookup {
+		if ac, ok := g.acsByID[fmt.Sprintf("%s:%s", entry.StateSlug, entry.ACID)]; ok {
+			g.acsByCode[fmt.Sprintf("%s:%d", entry.StateSlug, entry.ACCode)] = ac
+		}
+	}
+}
+
 // LoadBoothsForState lazily loads booths for a state
 func (g *GeoIndex) LoadBoothsForState(stateSlug string) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
 	if g.loadedStates[stateSlug] {
+		g.touchResidentLocked(residentBooths, stateSlug)
 		return nil
 	}
+	g.cacheMisses++
 
-	booths, err := LoadBoothsForState(g.dataDir, stateSlug)
+	booths, err := LoadBoothsForStateFS(g.fsys, stateSlug)
 	if err != nil {
 		return err
 	}
+	g.indexBoothsLocked(stateSlug, booths, nil)
+	g.evictLocked()
+	return nil
+}
 
+// indexBoothsLocked indexes a state's booths (must hold lock). cells may hold
+// each booth's precomputed H3 cell at config.BoothCellResolution; if nil the
+// cells are computed from the booth coordinates.
+func (g *GeoIndex) indexBoothsLocked(stateSlug string, booths []PollingBooth, cells []string) {
+	stateCells := make(map[string]bool)
 	for i := range booths {
 		booth := &booths[i]
+		booth.Edition = g.config.Edition.Name
 		g.boothsByState[stateSlug] = append(g.boothsByState[stateSlug], booth)
 
 		// Index by AC
@@ -235,10 +457,27 @@ func (g *GeoIndex) LoadBoothsForState(stateSlug string) error {
 		// Index by part ID
 		partKey := fmt.Sprintf("%s:%d:%d", stateSlug, booth.ACNumber, booth.PartID)
 		g.boothByPartID[partKey] = booth
+
+		// Index by H3 cell
+		var cellID string
+		if cells != nil {
+			cellID = cells[i]
+		} else {
+			cellID, _ = g.boothCell(booth)
+		}
+		if cellID != "" {
+			g.boothsByCell[cellID] = append(g.boothsByCell[cellID], booth)
+			stateCells[cellID] = true
+		}
 	}
 
+	for cellID := range stateCells {
+		g.boothCellsByState[stateSlug] = append(g.boothCellsByState[stateSlug], cellID)
+	}
+
+	g.boothExtents[stateSlug] = boothsExtent(booths)
 	g.loadedStates[stateSlug] = true
-	return nil
+	g.trackResidentLocked(residentBooths, stateSlug, estimateBoothBytes(booths))
 }
 
 // LoadBoundariesForState lazily loads AC boundaries for a state
@@ -247,24 +486,46 @@ func (g *GeoIndex) LoadBoundariesForState(stateSlug string) error {
 	defer g.mu.Unlock()
 
 	if g.loadedBounds[stateSlug] {
+		g.touchResidentLocked(residentBoundaries, stateSlug)
 		return nil
 	}
+	g.cacheMisses++
 
-	boundaries, err := LoadBoundariesForState(g.dataDir, stateSlug)
+	boundaries, err := LoadBoundariesForStateFS(g.fsys, stateSlug)
 	if err != nil {
 		return err
 	}
+	g.indexBoundariesLocked(stateSlug, boundaries)
+	g.evictLocked()
+	return nil
+}
 
+// indexBoundariesLocked indexes a state's AC boundaries (must hold lock)
+func (g *GeoIndex) indexBoundariesLocked(stateSlug string, boundaries []ACBoundary) {
 	for i := range boundaries {
 		boundary := &boundaries[i]
+		boundary.Edition = g.config.Edition.Name
+		boundary.coarse = newCoarseBoundary(boundary, g.config.CoarseToleranceM)
 		g.boundariesByState[stateSlug] = append(g.boundariesByState[stateSlug], boundary)
 
 		key := fmt.Sprintf("%s:%d", stateSlug, boundary.ConsCode)
 		g.boundaryByAC[key] = boundary
 	}
 
+	// Build the spatial index once per loaded state
+	boxes := make([][4]float64, len(boundaries))
+	for i := range boundaries {
+		boxes[i] = boundaries[i].BoundingBox()
+	}
+	g.boundaryIndex[stateSlug] = newSpatialIndex(boxes)
+	extent := emptyExtent
+	if bounds, ok := g.boundaryIndex[stateSlug].Bounds(); ok {
+		extent = bounds
+	}
+	g.boundaryExtents[stateSlug] = extent
+
 	g.loadedBounds[stateSlug] = true
-	return nil
+	g.trackResidentLocked(residentBoundaries, stateSlug, estimateBoundaryBytes(boundaries))
 }
 
 // --- State Lookups ---
@@ -351,15 +612,42 @@ func (g *GeoIndex) GetACByNumber(stateSlug string, acNumber int) (*AssemblyConst
 	return ac, ok
 }
 
-// GetACByName returns an AC by state slug and AC name slug
+// GetACByName returns an AC by state slug and AC name or name slug.
+// Aliases and close spellings are resolved; ambiguous names are not.
+// Use ResolveACName to see the candidates.
 func (g *GeoIndex) GetACByName(stateSlug, acNameSlug string) (*AssemblyConstituency, bool) {
+	candidates, err := g.ResolveACName(stateSlug, acNameSlug)
+	if err != nil {
+		return nil, false
+	}
+	return candidates[0].AC, true
+}
+
+// GetACByConsCode returns an AC by state slug and the cons_code used in
+// boundary and booth files. AC metadata IDs are numbered across all states,
+// so this goes through the constituency boundary lookup.
+func (g *GeoIndex) GetACByConsCode(stateSlug string, consCode int) (*AssemblyConstituency, bool) {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
-	key := fmt.Sprintf("%s:%s", stateSlug, acNameSlug)
-	ac, ok := g.acsByNameMap[key]
+	key := fmt.Sprintf("%s:%d", stateSlug, consCode)
+	ac, ok := g.acsByCode[key]
 	return ac, ok
 }
 
+// GetConstituencyLookup returns the constituency boundary lookup entries for a state
+func (g *GeoIndex) GetConstituencyLookup(stateSlug string) []ConstituencyBoundaryLookup {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	var entries []ConstituencyBoundaryLookup
+	for _, entry := range g.constituencyLookup {
+		if entry.StateSlug == stateSlug {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
 // GetACsForState returns all ACs for a state
 func (g *GeoIndex) GetACsForState(stateSlug string) []*AssemblyConstituency {
 	g.mu.RLock()
@@ -367,49 +655,103 @@ func (g *GeoIndex) GetACsForState(stateSlug string) []*AssemblyConstituency {
 	return g.acsByState[stateSlug]
 }
 
-// --- Booth Lookups ---
+// --- PC Lookups ---
 
-// GetBoothsForState returns all booths for a state (loads if needed)
-func (g *GeoIndex) GetBoothsForState(stateSlug string) ([]*PollingBooth, error) {
-	if err := g.LoadBoothsForState(stateSlug); err != nil {
-		return nil, err
-	}
+// GetPC returns a parliamentary constituency by state slug and PC number
+func (g *GeoIndex) GetPC(stateSlug string, pcNumber int) (*ParliamentaryConstituency, bool) {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
-	return g.boothsByState[stateSlug], nil
+	key := fmt.Sprintf("%s:%d", stateSlug, pcNumber)
+	pc, ok := g.pcsByNumber[key]
+	return pc, ok
 }
 
-// GetBoothsForAC returns all booths for an AC (loads state if needed)
-func (g *GeoIndex) GetBoothsForAC(stateSlug string, acNumber int) ([]*PollingBooth, error) {
-	if err := g.LoadBoothsForState(stateSlug); err != nil {
-		return nil, err
-	}
+// GetPCsForState returns all parliamentary constituencies for a state
+func (g *GeoIndex) GetPCsForState(stateSlug string) []*ParliamentaryConstituency {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.pcsByState[stateSlug]
+}
+
+// GetPCForAC returns the parliamentary constituency an AC belongs to
+func (g *GeoIndex) GetPCForAC(stateSlug string, acNumber int) (*ParliamentaryConstituency, bool) {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 	key := fmt.Sprintf("%s:%d", stateSlug, acNumber)
-	return g.boothsByAC[key], nil
+	pc, ok := g.pcByAC[key]
+	return pc, ok
 }
 
-// GetBoothsForDistrict returns all booths for a district (loads state if needed)
-func (g *GeoIndex) GetBoothsForDistrict(stateSlug, districtSlug string) ([]*PollingBooth, error) {
-	if err := g.LoadBoothsForState(stateSlug); err != nil {
+// GetACsForPC returns the AC numbers of the assembly segments of a PC
+func (g *GeoIndex) GetACsForPC(stateSlug string, pcNumber int) ([]int, error) {
+	pc, ok := g.GetPC(stateSlug, pcNumber)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s/%d", ErrPCNotFound, stateSlug, pcNumber)
+	}
+	return pc.ACNumbers, nil
+}
+
+// GetBoundariesForPC returns the AC boundaries that make up a PC
+func (g *GeoIndex) GetBoundariesForPC(stateSlug string, pcNumber int) ([]*ACBoundary, error) {
+	acNumbers, err := g.GetACsForPC(stateSlug, pcNumber)
+	if err != nil {
 		return nil, err
 	}
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-	key := fmt.Sprintf("%s:%s", stateSlug, districtSlug)
-	return g.boothsByDistrict[key], nil
+
+	boundaries := make([]*ACBoundary, 0, len(acNumbers))
+	for _, acNumber := range acNumbers {
+		boundary, err := g.GetBoundaryForAC(stateSlug, acNumber)
+		if err != nil {
+			return nil, err
+		}
+		boundaries = append(boundaries, boundary)
+	}
+
+	return boundaries, nil
+}
+
+// --- Booth Lookups ---
+
+// GetBoothsForState returns all booths for a state (loads if needed)
+func (g *GeoIndex) GetBoothsForState(stateSlug string) ([]*PollingBooth, error) {
+	var booths []*PollingBooth
+	err := g.readBooths(stateSlug, func() {
+		booths = g.boothsByState[stateSlug]
+	})
+	return booths, err
+}
+
+// GetBoothsForAC returns all booths for an AC (loads state if needed)
+func (g *GeoIndex) GetBoothsForAC(stateSlug string, acNumber int) ([]*PollingBooth, error) {
+	var booths []*PollingBooth
+	err := g.readBooths(stateSlug, func() {
+		key := fmt.Sprintf("%s:%d", stateSlug, acNumber)
+		booths = g.boothsByAC[key]
+	})
+	return booths, err
+}
+
+// GetBoothsForDistrict returns all booths for a district (loads state if needed)
+func (g *GeoIndex) GetBoothsForDistrict(stateSlug, districtSlug string) ([]*PollingBooth, error) {
+	var booths []*PollingBooth
+	err := g.readBooths(stateSlug, func() {
+		key := fmt.Sprintf("%s:%s", stateSlug, districtSlug)
+		booths = g.boothsByDistrict[key]
+	})
+	return booths, err
 }
 
 // GetBooth returns a specific booth by state, AC, and part ID
 func (g *GeoIndex) GetBooth(stateSlug string, acNumber, partID int) (*PollingBooth, error) {
-	if err := g.LoadBoothsForState(stateSlug); err != nil {
+	var booth *PollingBooth
+	var ok bool
+	err := g.readBooths(stateSlug, func() {
+		key := fmt.Sprintf("%s:%d:%d", stateSlug, acNumber, partID)
+		booth, ok = g.boothByPartID[key]
+	})
+	if err != nil {
 		return nil, err
 	}
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-	key := fmt.Sprintf("%s:%d:%d", stateSlug, acNumber, partID)
-	booth, ok := g.boothByPartID[key]
 	if !ok {
 		return nil, fmt.Errorf("%w: %s AC:%d Part:%d", ErrBoothNotFound, stateSlug, acNumber, partID)
 	}
@@ -420,23 +762,24 @@ func (g *GeoIndex) GetBooth(stateSlug string, acNumber, partID int) (*PollingBoo
 
 // GetBoundariesForState returns all AC boundaries for a state (loads if needed)
 func (g *GeoIndex) GetBoundariesForState(stateSlug string) ([]*ACBoundary, error) {
-	if err := g.LoadBoundariesForState(stateSlug); err != nil {
-		return nil, err
-	}
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-	return g.boundariesByState[stateSlug], nil
+	var boundaries []*ACBoundary
+	err := g.readBoundaries(stateSlug, func() {
+		boundaries = g.boundariesByState[stateSlug]
+	})
+	return boundaries, err
 }
 
 // GetBoundaryForAC returns the boundary for a specific AC
 func (g *GeoIndex) GetBoundaryForAC(stateSlug string, consCode int) (*ACBoundary, error) {
-	if err := g.LoadBoundariesForState(stateSlug); err != nil {
+	var boundary *ACBoundary
+	var ok bool
+	err := g.readBoundaries(stateSlug, func() {
+		key := fmt.Sprintf("%s:%d", stateSlug, consCode)
+		boundary, ok = g.boundaryByAC[key]
+	})
+	if err != nil {
 		return nil, err
 	}
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-	key := fmt.Sprintf("%s:%d", stateSlug, consCode)
-	boundary, ok := g.boundaryByAC[key]
 	if !ok {
 		return nil, fmt.Errorf("%w: %s/%d", ErrBoundaryNotFound, stateSlug, consCode)
 	}
@@ -477,60 +820,71 @@ func (g *GeoIndex) ListParties() []*Party {
 
 // FindACAtPoint finds the AC that contains the given point
 func (g *GeoIndex) FindACAtPoint(stateSlug string, lat, lng float64) (*ACBoundary, error) {
-	boundaries, err := g.GetBoundariesForState(stateSlug)
+	var boundary *ACBoundary
+	err := g.readBoundaries(stateSlug, func() {
+		boundary = g.findACAtPointLocked(stateSlug, lat, lng)
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	for _, boundary := range boundaries {
-		if boundary.ContainsPoint(lat, lng) {
-			return boundary, nil
-		}
+	if boundary != nil {
+		return boundary, nil
 	}
 
 	return nil, fmt.Errorf("%w: no AC found at (%.6f, %.6f)", ErrACNotFound, lat, lng)
 }
 
-// FindACAtPointAllStates searches all states for an AC containing the point
-// This is slower but useful when state is unknown
-func (g *GeoIndex) FindACAtPointAllStates(lat, lng float64) (*ACBoundary, string, error) {
-	// First try using the lookup table for a quick approximation
-	if len(g.constituencyLookup) > 0 {
-		// Find nearest center point
-		var bestMatch *ConstituencyBoundaryLookup
-		bestDist := float64(1e9)
-
-		for i := range g.constituencyLookup {
-			lookup := &g.constituencyLookup[i]
-			// Simple Euclidean distance (good enough for nearby points)
-			dist := (lat-lookup.CenterLat)*(lat-lookup.CenterLat) +
-				(lng-lookup.CenterLng)*(lng-lookup.CenterLng)
-			if dist < bestDist {
-				bestDist = dist
-				bestMatch = lookup
-			}
-		}
+// findACAtPointLocked queries the state's spatial index and ray casts only
+// the candidate boundaries (must hold lock)
+func (g *GeoIndex) findACAtPointLocked(stateSlug string, lat, lng float64) *ACBoundary {
+	index := g.boundaryIndex[stateSlug]
+	bounds, ok := index.Bounds()
+	if !ok || !bboxContainsPoint(bounds, lat, lng) {
+		return nil
+	}
 
-		if bestMatch != nil {
-			stateSlug := ToSlug(bestMatch.StateName)
-			// Verify the point is actually in this AC
-			boundary, err := g.GetBoundaryForAC(stateSlug, bestMatch.ACCode)
-			if err == nil && boundary.ContainsPoint(lat, lng) {
-				return boundary, stateSlug, nil
-			}
+	boundaries := g.boundariesByState[stateSlug]
+	for _, i := range index.SearchPoint(lat, lng) {
+		if boundaries[i].ContainsPoint(lat, lng) {
+			return boundaries[i]
 		}
 	}
 
-	// Fall back to checking all boundaries
-	availableStates, err := ListAvailableBoundaries(g.dataDir)
+	return nil
+}
+
+// FindACAtPointAllStates searches all states for an AC containing the point.
+// Only states whose boundary extent contains the point are searched, loading
+// their boundaries on first use. With an H3 AC table in use, see
+// UseH3ACTable, only the ACs of the point's cell are touched.
+func (g *GeoIndex) FindACAtPointAllStates(lat, lng float64) (*ACBoundary, string, error) {
+	g.mu.RLock()
+	table := g.h3ACTable
+	g.mu.RUnlock()
+	if table != nil {
+		return g.findACWithTable(table, lat, lng)
+	}
+
+	availableStates, err := g.availableBoundaryStates()
 	if err != nil {
 		return nil, "", err
 	}
 
 	for _, stateName := range availableStates {
 		stateSlug := ToSlug(stateName)
-		boundary, err := g.FindACAtPoint(stateSlug, lat, lng)
-		if err == nil {
+		if extent, err := g.boundaryExtent(stateSlug); err != nil || !bboxContainsPoint(extent, lat, lng) {
+			continue
+		}
+
+		var boundary *ACBoundary
+		err := g.readBoundaries(stateSlug, func() {
+			boundary = g.findACAtPointLocked(stateSlug, lat, lng)
+		})
+		if err != nil {
+			continue
+		}
+
+		if boundary != nil {
 			return boundary, stateSlug, nil
 		}
 	}
@@ -538,6 +892,55 @@ func (g *GeoIndex) FindACAtPointAllStates(lat, lng float64) (*ACBoundary, string
 	return nil, "", fmt.Errorf("%w: no AC found at (%.6f, %.6f) in any state", ErrACNotFound, lat, lng)
 }
 
+// availableBoundaryStates returns the cached list of states with boundary files
+func (g *GeoIndex) availableBoundaryStates() ([]string, error) {
+	g.mu.RLock()
+	states := g.availableBounds
+	g.mu.RUnlock()
+	if states != nil {
+		return states, nil
+	}
+
+	states, err := ListAvailableBoundariesFS(g.fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	g.availableBounds = states
+	g.mu.Unlock()
+
+	return states, nil
+}
+
+// FindPCAtPoint finds the parliamentary constituency that contains the given point
+func (g *GeoIndex) FindPCAtPoint(stateSlug string, lat, lng float64) (*ParliamentaryConstituency, error) {
+	boundary, err := g.FindACAtPoint(stateSlug, lat, lng)
+	if err != nil {
+		return nil, err
+	}
+
+	pc, ok := g.GetPCForAC(stateSlug, boundary.ConsCode)
+	if !ok {
+		return nil, fmt.Errorf("%w: no PC for AC %s/%d", ErrPCNotFound, stateSlug, boundary.ConsCode)
+	}
+	return pc, nil
+}
+
+// FindPCAtPointAllStates searches all states for a PC containing the point
+func (g *GeoIndex) FindPCAtPointAllStates(lat, lng float64) (*ParliamentaryConstituency, string, error) {
+	boundary, stateSlug, err := g.FindACAtPointAllStates(lat, lng)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pc, ok := g.GetPCForAC(stateSlug, boundary.ConsCode)
+	if !ok {
+		return nil, "", fmt.Errorf("%w: no PC for AC %s/%d", ErrPCNotFound, stateSlug, boundary.ConsCode)
+	}
+	return pc, stateSlug, nil
+}
+
 // --- Statistics ---
 
 // Stats returns statistics about the loaded data
@@ -545,11 +948,18 @@ type IndexStats struct {
 	States           int
 	Districts        int
 	ACs              int
+	PCs              int
 	BoothsLoaded     int
 	BoundariesLoaded int
 	Parties          int
 	StatesWithBooths int
 	StatesWithBounds int
+
+	// Per-state booth and boundary cache, see GeoIndexConfig.MemoryBudgetBytes
+	CacheHits      uint64 // Lookups served from loaded data
+	CacheMisses    uint64 // Lookups that loaded data from disk
+	CacheEvictions uint64
+	ResidentBytes  int64 // Estimated memory held by loaded booths and boundaries
 }
 
 // GetStats returns statistics about the loaded index
@@ -558,9 +968,13 @@ func (g *GeoIndex) GetStats() IndexStats {
 	defer g.mu.RUnlock()
 
 	stats := IndexStats{
-		States:    len(g.statesByID),
-		Districts: len(g.districtsByID),
-		Parties:   len(g.partiesByID),
+		States:         len(g.statesByID),
+		Districts:      len(g.districtsByID),
+		Parties:        len(g.partiesByID),
+		CacheHits:      g.cacheHits,
+		CacheMisses:    g.cacheMisses,
+		CacheEvictions: g.cacheEvictions,
+		ResidentBytes:  g.residentBytes,
 	}
 
 	// Count ACs
@@ -568,6 +982,11 @@ func (g *GeoIndex) GetStats() IndexStats {
 		stats.ACs += len(acs)
 	}
 
+	// Count PCs
+	for _, pcs := range g.pcsByState {
+		stats.PCs += len(pcs)
+	}
+
 	// Count loaded booths
 	for _, booths := range g.boothsByState {
 		stats.BoothsLoaded += len(booths)
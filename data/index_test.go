@@ -0,0 +1,150 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testSquareFeature returns a GeoJSON AC feature covering a one-degree square
+func testSquareFeature(consCode int, consName string, lng, lat float64) string {
+	return fmt.Sprintf(`{"type": "Feature", "properties": {"objectid": %d, "uid": "T%d", "state_ut": "Testland",
+		"cons_code": %d, "cons_name": %q}, "geometry": {"type": "Polygon", "coordinates": [[
+		[%g, %g], [%g, %g], [%g, %g], [%g, %g], [%g, %g]]]}}`,
+		consCode, consCode, consCode, consName,
+		lng, lat, lng+1, lat, lng+1, lat+1, lng, lat+1, lng, lat)
+}
+
+// testDataFiles returns a minimal data directory layout: one state with
+// three square ACs side by side along longitude 77-80 and latitude 12-13
+func testDataFiles() map[string]string {
+	return map[string]string{
+		StatesFile: `{"states": [{"id": 1, "stateId": "TL", "name": "Testland", "type": "state",
+			"latitude": 12.5, "longitude": 78.5}], "unionTerritories": []}`,
+		DistrictsFile: `{"districts": [{"id": 1, "name": "North", "state": "Testland", "latitude": 12.5, "longitude": 77.5}]}`,
+		AssemblyConstituenciesFile: `{"states": [{"name": "Testland", "totalSeats": 3, "constituencies": [
+			{"id": "ac_1", "name": "Alpha", "reserved": "None", "latitude": 12.5, "longitude": 77.5},
+			{"id": "ac_2", "name": "Beta", "reserved": "SC", "latitude": 12.5, "longitude": 78.5},
+			{"id": "ac_3", "name": "Gamma", "reserved": "ST", "latitude": 12.5, "longitude": 79.5}]}]}`,
+		PartiesFile: `{"parties": [{"id": 1, "name": "Test Party", "shortName": "TP"}]}`,
+		ParliamentaryConstituencyFile: `{"states": [{"name": "Testland", "totalSeats": 2, "constituencies": [
+			{"id": "pc_1", "name": "West", "reserved": "None", "acNumbers": [1, 2]},
+			{"id": "pc_2", "name": "East", "reserved": "ST", "acNumbers": [3]}]}]}`,
+		ConstituencyBoundaryLookupFile: `{
+			"ac_1": {"state_file": "testland.geojson", "state_feature_index": 0, "cons_code": 1, "cons_name": "Alpha", "uid": "T1", "match_type": "exact"},
+			"ac_2": {"state_file": "testland.geojson", "state_feature_index": 1, "cons_code": 2, "cons_name": "Beta", "uid": "T2", "match_type": "exact"},
+			"ac_3": {"state_file": "testland.geojson", "state_feature_index": 2, "cons_code": 3, "cons_name": "Gamma", "uid": "T3", "match_type": "exact"}}`,
+		filepath.Join(BoundariesDir, "testland.geojson"): `{"type": "FeatureCollection", "state_name": "Testland", "features": [` +
+			testSquareFeature(1, "Alpha", 77, 12) + `, ` +
+			testSquareFeature(2, "Beta", 78, 12) + `, ` +
+			testSquareFeature(3, "Gamma", 79, 12) + `]}`,
+		filepath.Join(BoundariesDir, DistrictBoundariesDir, "testland.geojson"): `{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "properties": {"dist_code": 1, "dist_name": "North", "state_ut": "Testland"},
+			 "geometry": {"type": "Polygon", "coordinates": [[[77, 12], [78.5, 12], [78.5, 13], [77, 13], [77, 12]]]}},
+			{"type": "Feature", "properties": {"dist_code": 2, "dist_name": "South", "state_ut": "Testland"},
+			 "geometry": {"type": "MultiPolygon", "coordinates": [[[[78.5, 12], [80, 12], [80, 13], [78.5, 13], [78.5, 12]]]]}}]}`,
+		filepath.Join(BoothsDir, "testland", "north.json"): `[
+			{"partId": 1, "stateName": "Testland", "districtName": "NORTH", "acNumber": 1, "acName": "Alpha",
+			 "partNumber": 1, "partName": "Govt School, Alpha", "lat": 12.5, "lon": 77.5},
+			{"partId": 2, "stateName": "Testland", "districtName": "NORTH", "acNumber": 2, "acName": "Beta",
+			 "partNumber": 1, "partName": "Panchayat Office, Beta", "lat": 12.5, "lon": 78.8},
+			{"partId": 3, "stateName": "Testland", "districtName": "NORTH", "acNumber": 3, "acName": "Gamma",
+			 "partNumber": 1, "partName": "Community Hall, Gamma"}]`,
+	}
+}
+
+// writeTestData writes files into a temporary data directory
+func writeTestData(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// newTestIndex returns a fully loaded index over the test data directory
+func newTestIndex(t *testing.T) *GeoIndex {
+	t.Helper()
+	index := NewGeoIndex(writeTestData(t, testDataFiles()))
+	if err := index.LoadAll(); err != nil {
+		t.Fatalf("LoadAll() error: %v", err)
+	}
+	return index
+}
+
+func TestParliamentaryConstituencies(t *testing.T) {
+	index := newTestIndex(t)
+
+	if pcs := index.GetPCsForState("testland"); len(pcs) != 2 {
+		t.Fatalf("GetPCsForState() len = %d, want %d", len(pcs), 2)
+	}
+
+	pc, ok := index.GetPCForAC("testland", 2)
+	if !ok || pc.Name != "West" {
+		t.Errorf("GetPCForAC(2) = %v, %v, want West", pc, ok)
+	}
+
+	acs, err := index.GetACsForPC("testland", 2)
+	if err != nil || len(acs) != 1 || acs[0] != 3 {
+		t.Errorf("GetACsForPC(2) = %v, %v, want [3]", acs, err)
+	}
+
+	if _, err := index.GetACsForPC("testland", 9); !errors.Is(err, ErrPCNotFound) {
+		t.Errorf("GetACsForPC(9) error = %v, want ErrPCNotFound", err)
+	}
+
+	pc, err = index.FindPCAtPoint("testland", 12.5, 79.5)
+	if err != nil || pc.PCNumber != 2 || !pc.IsReserved() {
+		t.Errorf("FindPCAtPoint() = %v, %v, want reserved PC 2", pc, err)
+	}
+
+	boundaries, err := index.GetBoundariesForPC("testland", 1)
+	if err != nil || len(boundaries) != 2 {
+		t.Errorf("GetBoundariesForPC(1) = %d boundaries, %v, want 2", len(boundaries), err)
+	}
+}
+
+func TestLoadAllWithoutPCFile(t *testing.T) {
+	files := testDataFiles()
+	delete(files, ParliamentaryConstituencyFile)
+
+	index := NewGeoIndex(writeTestData(t, files))
+	if err := index.LoadAll(); err != nil {
+		t.Fatalf("LoadAll() without PC file error: %v", err)
+	}
+	if stats := index.GetStats(); stats.PCs != 0 {
+		t.Errorf("GetStats().PCs = %d, want 0", stats.PCs)
+	}
+}
+
+func TestFindACAtPointAllStatesSkipsDistantStates(t *testing.T) {
+	files := testDataFiles()
+	files[filepath.Join(BoundariesDir, "farland.geojson")] = `{"type": "FeatureCollection", "state_name": "Farland", "features": [` +
+		testSquareFeature(1, "Zeta", 88, 22) + `]}`
+	index := NewGeoIndex(writeTestData(t, files))
+
+	boundary, stateSlug, err := index.FindACAtPointAllStates(12.5, 77.5)
+	if err != nil || stateSlug != "testland" || boundary.ConsName != "Alpha" {
+		t.Fatalf("FindACAtPointAllStates(12.5, 77.5) = %v, %q, %v, want Alpha in testland", boundary, stateSlug, err)
+	}
+	index.mu.RLock()
+	loaded := index.loadedBounds["farland"]
+	index.mu.RUnlock()
+	if loaded {
+		t.Error("Farland boundaries were loaded for a point outside its extent")
+	}
+
+	boundary, stateSlug, err = index.FindACAtPointAllStates(22.5, 88.5)
+	if err != nil || stateSlug != "farland" || boundary.ConsName != "Zeta" {
+		t.Errorf("FindACAtPointAllStates(22.5, 88.5) = %v, %q, %v, want Zeta in farland", boundary, stateSlug, err)
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
-	"path/filepath"
+	"path"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -19,8 +22,10 @@ var (
 	ErrStateNotFound    = errors.New("state not found")
 	ErrDistrictNotFound = errors.New("district not found")
 	ErrACNotFound       = errors.New("assembly constituency not found")
+	ErrPCNotFound       = errors.New("parliamentary constituency not found")
 	ErrBoothNotFound    = errors.New("booth not found")
 	ErrBoundaryNotFound = errors.New("boundary not found")
+	ErrAmbiguousACName  = errors.New("ambiguous assembly constituency name")
 )
 
 // statesFile is the JSON structure for states.json
@@ -50,6 +55,11 @@ type acFile struct {
 	States []StateConstituencies `json:"states"`
 }
 
+// pcFile is the JSON structure for parliamentary_constituency.json
+type pcFile struct {
+	States []StateParliamentaryConstituencies `json:"states"`
+}
+
 // partiesFile is the JSON structure for parties.json
 type partiesFile struct {
 	Metadata struct {
@@ -59,6 +69,11 @@ type partiesFile struct {
 	Parties []Party `json:"parties"`
 }
 
+// aliasesFile is the JSON structure for ac_name_aliases.json
+type aliasesFile struct {
+	Aliases map[string]map[string]string `json:"aliases"` // state name -> AC name -> alias
+}
+
 // geoJSONFile represents a GeoJSON FeatureCollection
 type geoJSONFile struct {
 	Type      string           `json:"type"`
@@ -76,19 +91,27 @@ type geoJSONFeature struct {
 		ConsCode int    `json:"cons_code"`
 		ConsName string `json:"cons_name"`
 	} `json:"properties"`
-	Geometry struct {
-		Type        string          `json:"type"`
-		Coordinates json.RawMessage `json:"coordinates"`
-	} `json:"geometry"`
+	Geometry geoJSONGeometry `json:"geometry"`
+}
+
+// geoJSONGeometry represents a GeoJSON geometry with undecoded coordinates
+type geoJSONGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
 }
 
 // LoadStates loads all states and union territories from states.json
 func LoadStates(dataDir string) ([]State, error) {
-	filePath := filepath.Join(dataDir, StatesFile)
+	return LoadStatesFS(dirFS(dataDir))
+}
 
-	data, err := os.ReadFile(filePath)
+// LoadStatesFS is like LoadStates but reads from fsys
+func LoadStatesFS(fsys fs.FS) ([]State, error) {
+	filePath := StatesFile
+
+	data, err := fs.ReadFile(fsys, filePath)
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, fs.ErrNotExist) {
 			return nil, fmt.Errorf("%w: %s", ErrFileNotFound, filePath)
 		}
 		return nil, err
@@ -109,11 +132,16 @@ func LoadStates(dataDir string) ([]State, error) {
 
 // LoadDistricts loads all districts from districts.json
 func LoadDistricts(dataDir string) ([]District, error) {
-	filePath := filepath.Join(dataDir, DistrictsFile)
+	return LoadDistrictsFS(dirFS(dataDir))
+}
 
-	data, err := os.ReadFile(filePath)
+// LoadDistrictsFS is like LoadDistricts but reads from fsys
+func LoadDistrictsFS(fsys fs.FS) ([]District, error) {
+	filePath := DistrictsFile
+
+	data, err := fs.ReadFile(fsys, filePath)
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, fs.ErrNotExist) {
 			return nil, fmt.Errorf("%w: %s", ErrFileNotFound, filePath)
 		}
 		return nil, err
@@ -130,11 +158,16 @@ func LoadDistricts(dataDir string) ([]District, error) {
 // LoadConstituencies loads all assembly constituencies
 // Returns a map of state name -> constituencies
 func LoadConstituencies(dataDir string) (map[string][]AssemblyConstituency, error) {
-	filePath := filepath.Join(dataDir, AssemblyConstituenciesFile)
+	return LoadConstituenciesFS(dirFS(dataDir))
+}
+
+// LoadConstituenciesFS is like LoadConstituencies but reads from fsys
+func LoadConstituenciesFS(fsys fs.FS) (map[string][]AssemblyConstituency, error) {
+	filePath := AssemblyConstituenciesFile
 
-	data, err := os.ReadFile(filePath)
+	data, err := fs.ReadFile(fsys, filePath)
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, fs.ErrNotExist) {
 			return nil, fmt.Errorf("%w: %s", ErrFileNotFound, filePath)
 		}
 		return nil, err
@@ -167,7 +200,12 @@ func LoadConstituencies(dataDir string) (map[string][]AssemblyConstituency, erro
 
 // LoadConstituenciesForState loads constituencies for a specific state
 func LoadConstituenciesForState(dataDir, stateName string) ([]AssemblyConstituency, error) {
-	all, err := LoadConstituencies(dataDir)
+	return LoadConstituenciesForStateFS(dirFS(dataDir), stateName)
+}
+
+// LoadConstituenciesForStateFS is like LoadConstituenciesForState but reads from fsys
+func LoadConstituenciesForStateFS(fsys fs.FS, stateName string) ([]AssemblyConstituency, error) {
+	all, err := LoadConstituenciesFS(fsys)
 	if err != nil {
 		return nil, err
 	}
@@ -188,13 +226,87 @@ func LoadConstituenciesForState(dataDir, stateName string) ([]AssemblyConstituen
 	return nil, fmt.Errorf("%w: %s", ErrStateNotFound, stateName)
 }
 
+// LoadParliamentaryConstituencies loads all parliamentary constituencies
+// Returns a map of state name -> constituencies
+func LoadParliamentaryConstituencies(dataDir string) (map[string][]ParliamentaryConstituency, error) {
+	return LoadParliamentaryConstituenciesFS(dirFS(dataDir))
+}
+
+// LoadParliamentaryConstituenciesFS is like LoadParliamentaryConstituencies but reads from fsys
+func LoadParliamentaryConstituenciesFS(fsys fs.FS) (map[string][]ParliamentaryConstituency, error) {
+	filePath := ParliamentaryConstituencyFile
+
+	data, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrFileNotFound, filePath)
+		}
+		return nil, err
+	}
+
+	var file pcFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+
+	result := make(map[string][]ParliamentaryConstituency)
+	for _, state := range file.States {
+		// Enrich each constituency with state info
+		for i := range state.Constituencies {
+			state.Constituencies[i].StateName = state.Name
+			state.Constituencies[i].StateSlug = ToSlug(state.Name)
+			// Parse PC number from ID (e.g., "pc_1" -> 1)
+			if strings.HasPrefix(state.Constituencies[i].ID, "pc_") {
+				numStr := strings.TrimPrefix(state.Constituencies[i].ID, "pc_")
+				if num, err := strconv.Atoi(numStr); err == nil {
+					state.Constituencies[i].PCNumber = num
+				}
+			}
+		}
+		result[state.Name] = state.Constituencies
+	}
+
+	return result, nil
+}
+
+// LoadACNameAliases loads alternate spellings of AC names
+// Returns a map of state name -> AC name (as in assembly_constituency.json) -> alias
+func LoadACNameAliases(dataDir string) (map[string]map[string]string, error) {
+	return LoadACNameAliasesFS(dirFS(dataDir))
+}
+
+// LoadACNameAliasesFS is like LoadACNameAliases but reads from fsys
+func LoadACNameAliasesFS(fsys fs.FS) (map[string]map[string]string, error) {
+	filePath := ACNameAliasesFile
+
+	data, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrFileNotFound, filePath)
+		}
+		return nil, err
+	}
+
+	var file aliasesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+
+	return file.Aliases, nil
+}
+
 // LoadParties loads all political parties from parties.json
 func LoadParties(dataDir string) ([]Party, error) {
-	filePath := filepath.Join(dataDir, PartiesFile)
+	return LoadPartiesFS(dirFS(dataDir))
+}
 
-	data, err := os.ReadFile(filePath)
+// LoadPartiesFS is like LoadParties but reads from fsys
+func LoadPartiesFS(fsys fs.FS) ([]Party, error) {
+	filePath := PartiesFile
+
+	data, err := fs.ReadFile(fsys, filePath)
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, fs.ErrNotExist) {
 			return nil, fmt.Errorf("%w: %s", ErrFileNotFound, filePath)
 		}
 		return nil, err
@@ -210,12 +322,17 @@ func LoadParties(dataDir string) ([]Party, error) {
 
 // LoadBoothsForState loads all booths for a specific state
 func LoadBoothsForState(dataDir, stateSlug string) ([]PollingBooth, error) {
-	boothsDir := filepath.Join(dataDir, BoothsDir, FromSlug(stateSlug))
+	return LoadBoothsForStateFS(dirFS(dataDir), stateSlug)
+}
+
+// LoadBoothsForStateFS is like LoadBoothsForState but reads from fsys
+func LoadBoothsForStateFS(fsys fs.FS, stateSlug string) ([]PollingBooth, error) {
+	boothsDir := path.Join(BoothsDir, boothDirForStateSlug(stateSlug))
 
 	// Check if directory exists
-	info, err := os.Stat(boothsDir)
+	info, err := fs.Stat(fsys, boothsDir)
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, fs.ErrNotExist) {
 			return nil, fmt.Errorf("%w: %s", ErrStateNotFound, stateSlug)
 		}
 		return nil, err
@@ -225,7 +342,7 @@ func LoadBoothsForState(dataDir, stateSlug string) ([]PollingBooth, error) {
 	}
 
 	// Read all JSON files in the directory
-	entries, err := os.ReadDir(boothsDir)
+	entries, err := fs.ReadDir(fsys, boothsDir)
 	if err != nil {
 		return nil, err
 	}
@@ -236,8 +353,8 @@ func LoadBoothsForState(dataDir, stateSlug string) ([]PollingBooth, error) {
 			continue
 		}
 
-		filePath := filepath.Join(boothsDir, entry.Name())
-		data, err := os.ReadFile(filePath)
+		filePath := path.Join(boothsDir, entry.Name())
+		data, err := fs.ReadFile(fsys, filePath)
 		if err != nil {
 			return nil, err
 		}
@@ -255,11 +372,16 @@ func LoadBoothsForState(dataDir, stateSlug string) ([]PollingBooth, error) {
 
 // LoadBoothsForDistrict loads booths for a specific district within a state
 func LoadBoothsForDistrict(dataDir, stateSlug, districtSlug string) ([]PollingBooth, error) {
-	filePath := filepath.Join(dataDir, BoothsDir, FromSlug(stateSlug), FromSlug(districtSlug)+".json")
+	return LoadBoothsForDistrictFS(dirFS(dataDir), stateSlug, districtSlug)
+}
 
-	data, err := os.ReadFile(filePath)
+// LoadBoothsForDistrictFS is like LoadBoothsForDistrict but reads from fsys
+func LoadBoothsForDistrictFS(fsys fs.FS, stateSlug, districtSlug string) ([]PollingBooth, error) {
+	filePath := path.Join(BoothsDir, boothDirForStateSlug(stateSlug), FromSlug(districtSlug)+".json")
+
+	data, err := fs.ReadFile(fsys, filePath)
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, fs.ErrNotExist) {
 			return nil, fmt.Errorf("%w: %s/%s", ErrDistrictNotFound, stateSlug, districtSlug)
 		}
 		return nil, err
@@ -273,13 +395,35 @@ func LoadBoothsForDistrict(dataDir, stateSlug, districtSlug string) ([]PollingBo
 	return booths, nil
 }
 
+// districtGeoJSONFile represents a GeoJSON FeatureCollection of district boundaries
+type districtGeoJSONFile struct {
+	Type     string                   `json:"type"`
+	Features []districtGeoJSONFeature `json:"features"`
+}
+
+// districtGeoJSONFeature represents a GeoJSON Feature for a district
+type districtGeoJSONFeature struct {
+	Type       string `json:"type"`
+	Properties struct {
+		DistCode int    `json:"dist_code"`
+		DistName string `json:"dist_name"`
+		StateUT  string `json:"state_ut"`
+	} `json:"properties"`
+	Geometry geoJSONGeometry `json:"geometry"`
+}
+
 // LoadBoundariesForState loads AC boundaries (GeoJSON) for a state
 func LoadBoundariesForState(dataDir, stateSlug string) ([]ACBoundary, error) {
-	filePath := filepath.Join(dataDir, BoundariesDir, FromSlug(stateSlug)+".geojson")
+	return LoadBoundariesForStateFS(dirFS(dataDir), stateSlug)
+}
+
+// LoadBoundariesForStateFS is like LoadBoundariesForState but reads from fsys
+func LoadBoundariesForStateFS(fsys fs.FS, stateSlug string) ([]ACBoundary, error) {
+	filePath := path.Join(BoundariesDir, FromSlug(stateSlug)+".geojson")
 
-	data, err := os.ReadFile(filePath)
+	data, err := fs.ReadFile(fsys, filePath)
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, fs.ErrNotExist) {
 			return nil, fmt.Errorf("%w: %s", ErrStateNotFound, stateSlug)
 		}
 		return nil, err
@@ -300,24 +444,14 @@ func LoadBoundariesForState(dataDir, stateSlug string) ([]ACBoundary, error) {
 			ConsName: feature.Properties.ConsName,
 		}
 
-		// Parse coordinates based on geometry type
-		switch feature.Geometry.Type {
-		case "Polygon":
-			var coords [][][]float64
-			if err := json.Unmarshal(feature.Geometry.Coordinates, &coords); err != nil {
-				return nil, fmt.Errorf("%w: polygon coordinates: %v", ErrInvalidGeoJSON, err)
-			}
-			boundary.Polygon = coords
-
-		case "MultiPolygon":
-			var multiCoords [][][][]float64
-			if err := json.Unmarshal(feature.Geometry.Coordinates, &multiCoords); err != nil {
-				return nil, fmt.Errorf("%w: multipolygon coordinates: %v", ErrInvalidGeoJSON, err)
-			}
-			// Use the first polygon (largest)
-			if len(multiCoords) > 0 {
-				boundary.Polygon = multiCoords[0]
-			}
+		// Keep every part so islands and exclaves are not dropped
+		polygons, err := parseGeoJSONPolygons(feature.Geometry)
+		if err != nil {
+			return nil, err
+		}
+		if len(polygons) > 0 {
+			boundary.Polygon = polygons[0]
+			boundary.Polygons = polygons
 		}
 
 		boundaries = append(boundaries, boundary)
@@ -326,9 +460,118 @@ func LoadBoundariesForState(dataDir, stateSlug string) ([]ACBoundary, error) {
 	return boundaries, nil
 }
 
+// LoadDistrictBoundariesForState loads district boundaries (GeoJSON) for a state
+// from boundaries/districts/<state>.geojson
+func LoadDistrictBoundariesForState(dataDir, stateSlug string) ([]DistrictBoundary, error) {
+	return LoadDistrictBoundariesForStateFS(dirFS(dataDir), stateSlug)
+}
+
+// LoadDistrictBoundariesForStateFS is like LoadDistrictBoundariesForState but reads from fsys
+func LoadDistrictBoundariesForStateFS(fsys fs.FS, stateSlug string) ([]DistrictBoundary, error) {
+	filePath := path.Join(BoundariesDir, DistrictBoundariesDir, FromSlug(stateSlug)+".geojson")
+
+	data, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrStateNotFound, stateSlug)
+		}
+		return nil, err
+	}
+
+	var geoJSON districtGeoJSONFile
+	if err := json.Unmarshal(data, &geoJSON); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGeoJSON, err)
+	}
+
+	boundaries := make([]DistrictBoundary, 0, len(geoJSON.Features))
+	for _, feature := range geoJSON.Features {
+		polygons, err := parseGeoJSONPolygons(feature.Geometry)
+		if err != nil {
+			return nil, err
+		}
+
+		boundaries = append(boundaries, DistrictBoundary{
+			DistCode: feature.Properties.DistCode,
+			DistName: feature.Properties.DistName,
+			StateUT:  feature.Properties.StateUT,
+			Polygons: polygons,
+		})
+	}
+
+	return boundaries, nil
+}
+
+// parseGeoJSONPolygons parses Polygon and MultiPolygon geometries into a list of polygons
+func parseGeoJSONPolygons(geometry geoJSONGeometry) ([][][][]float64, error) {
+	switch geometry.Type {
+	case "Polygon":
+		var coords [][][]float64
+		if err := json.Unmarshal(geometry.Coordinates, &coords); err != nil {
+			return nil, fmt.Errorf("%w: polygon coordinates: %v", ErrInvalidGeoJSON, err)
+		}
+		return splitOuterHoles([][][][]float64{coords}), nil
+
+	case "MultiPolygon":
+		var multiCoords [][][][]float64
+		if err := json.Unmarshal(geometry.Coordinates, &multiCoords); err != nil {
+			return nil, fmt.Errorf("%w: multipolygon coordinates: %v", ErrInvalidGeoJSON, err)
+		}
+		return splitOuterHoles(multiCoords), nil
+	}
+
+	return nil, nil
+}
+
+// splitOuterHoles turns hole rings lying outside their polygon's exterior
+// ring into polygons of their own. Some source files store a disjoint part
+// of an AC as a hole, which would otherwise be left out of both the AC and
+// its area.
+func splitOuterHoles(polygons [][][][]float64) [][][][]float64 {
+	result := make([][][][]float64, 0, len(polygons))
+	var parts [][][][]float64
+	for _, polygon := range polygons {
+		if len(polygon) < 2 {
+			result = append(result, polygon)
+			continue
+		}
+
+		rings := [][][]float64{polygon[0]}
+		for _, hole := range polygon[1:] {
+			if ringMostlyOutside(hole, polygon[0]) {
+				slices.Reverse(hole) // Exterior rings run counterclockwise
+				parts = append(parts, [][][]float64{hole})
+			} else {
+				rings = append(rings, hole)
+			}
+		}
+		result = append(result, rings)
+	}
+	return append(result, parts...)
+}
+
+// ringMostlyOutside reports whether more of a ring's vertices lie outside
+// another ring than inside it. Counting vertices tolerates holes that touch
+// or slightly cross the exterior ring.
+func ringMostlyOutside(ring, exterior [][]float64) bool {
+	inside, outside := 0, 0
+	for _, pt := range ring {
+		if pointInRing(pt[1], pt[0], exterior) {
+			inside++
+		} else {
+			outside++
+		}
+	}
+	return outside > inside
+}
+
 // LoadBoundaryForAC loads a specific AC boundary
 func LoadBoundaryForAC(dataDir, stateSlug string, consCode int) (*ACBoundary, error) {
-	boundaries, err := LoadBoundariesForState(dataDir, stateSlug)
+	return LoadBoundaryForACFS(dirFS(dataDir), stateSlug, consCode)
+}
+
+// LoadBoundaryForACFS is like LoadBoundaryForAC but reads from fsys
+func LoadBoundaryForACFS(fsys fs.FS, stateSlug string, consCode int) (*ACBoundary, error) {
+	boundaries, err := LoadBoundariesForStateFS(fsys, stateSlug)
 	if err != nil {
 		return nil, err
 	}
@@ -344,31 +587,56 @@ func LoadBoundaryForAC(dataDir, stateSlug string, consCode int) (*ACBoundary, er
 
 // LoadConstituencyLookup loads the constituency boundary lookup table
 func LoadConstituencyLookup(dataDir string) ([]ConstituencyBoundaryLookup, error) {
-	filePath := filepath.Join(dataDir, ConstituencyBoundaryLookupFile)
+	return LoadConstituencyLookupFS(dirFS(dataDir))
+}
+
+// LoadConstituencyLookupFS is like LoadConstituencyLookup but reads from fsys
+func LoadConstituencyLookupFS(fsys fs.FS) ([]ConstituencyBoundaryLookup, error) {
+	filePath := ConstituencyBoundaryLookupFile
 
-	data, err := os.ReadFile(filePath)
+	data, err := fs.ReadFile(fsys, filePath)
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, fs.ErrNotExist) {
 			return nil, fmt.Errorf("%w: %s", ErrFileNotFound, filePath)
 		}
 		return nil, err
 	}
 
-	var lookup []ConstituencyBoundaryLookup
-	if err := json.Unmarshal(data, &lookup); err != nil {
+	var entries map[string]ConstituencyBoundaryLookup
+	if err := json.Unmarshal(data, &entries); err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrInvalidJSON, err)
 	}
 
+	lookup := make([]ConstituencyBoundaryLookup, 0, len(entries))
+	for acID, entry := range entries {
+		entry.ACID = acID
+		entry.StateSlug = ToSlug(strings.TrimSuffix(entry.StateFile, ".geojson"))
+		lookup = append(lookup, entry)
+	}
+
+	// Map iteration order is random; keep the result stable
+	sort.Slice(lookup, func(i, j int) bool {
+		if lookup[i].StateSlug != lookup[j].StateSlug {
+			return lookup[i].StateSlug < lookup[j].StateSlug
+		}
+		return lookup[i].ACCode < lookup[j].ACCode
+	})
+
 	return lookup, nil
 }
 
 // ListAvailableStates returns the list of states that have booth data
 func ListAvailableStates(dataDir string) ([]string, error) {
-	boothsDir := filepath.Join(dataDir, BoothsDir)
+	return ListAvailableStatesFS(dirFS(dataDir))
+}
 
-	entries, err := os.ReadDir(boothsDir)
+// ListAvailableStatesFS is like ListAvailableStates but reads from fsys
+func ListAvailableStatesFS(fsys fs.FS) ([]string, error) {
+	boothsDir := BoothsDir
+
+	entries, err := fs.ReadDir(fsys, boothsDir)
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, fs.ErrNotExist) {
 			return nil, fmt.Errorf("%w: %s", ErrDataDirNotFound, boothsDir)
 		}
 		return nil, err
@@ -386,11 +654,16 @@ func ListAvailableStates(dataDir string) ([]string, error) {
 
 // ListAvailableBoundaries returns the list of states that have boundary data
 func ListAvailableBoundaries(dataDir string) ([]string, error) {
-	boundariesDir := filepath.Join(dataDir, BoundariesDir)
+	return ListAvailableBoundariesFS(dirFS(dataDir))
+}
 
-	entries, err := os.ReadDir(boundariesDir)
+// ListAvailableBoundariesFS is like ListAvailableBoundaries but reads from fsys
+func ListAvailableBoundariesFS(fsys fs.FS) ([]string, error) {
+	boundariesDir := BoundariesDir
+
+	entries, err := fs.ReadDir(fsys, boundariesDir)
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, fs.ErrNotExist) {
 			return nil, fmt.Errorf("%w: %s", ErrDataDirNotFound, boundariesDir)
 		}
 		return nil, err
@@ -409,7 +682,12 @@ func ListAvailableBoundaries(dataDir string) ([]string, error) {
 
 // GetBoothCount returns the total number of booths for a state
 func GetBoothCount(dataDir, stateSlug string) (int, error) {
-	booths, err := LoadBoothsForState(dataDir, stateSlug)
+	return GetBoothCountFS(dirFS(dataDir), stateSlug)
+}
+
+// GetBoothCountFS is like GetBoothCount but reads from fsys
+func GetBoothCountFS(fsys fs.FS, stateSlug string) (int, error) {
+	booths, err := LoadBoothsForStateFS(fsys, stateSlug)
 	if err != nil {
 		return 0, err
 	}
@@ -418,9 +696,22 @@ func GetBoothCount(dataDir, stateSlug string) (int, error) {
 
 // GetACCount returns the number of ACs for a state
 func GetACCount(dataDir, stateName string) (int, error) {
-	acs, err := LoadConstituenciesForState(dataDir, stateName)
+	return GetACCountFS(dirFS(dataDir), stateName)
+}
+
+// GetACCountFS is like GetACCount but reads from fsys
+func GetACCountFS(fsys fs.FS, stateName string) (int, error) {
+	acs, err := LoadConstituenciesForStateFS(fsys, stateName)
 	if err != nil {
 		return 0, err
 	}
 	return len(acs), nil
 }
+
+// dirFS returns the file system rooted at a data directory
+func dirFS(dataDir string) fs.FS {
+	if dataDir == "" {
+		dataDir = "."
+	}
+	return os.DirFS(dataDir)
+}
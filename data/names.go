@@ -0,0 +1,163 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+
+	boothmatching "github.com/politic-in/core/booth-matching"
+)
+
+// AC name matching thresholds
+const (
+	// MinACNameConfidence is the minimum confidence for a fuzzy AC name candidate
+	MinACNameConfidence = 0.75
+
+	// ACNameAmbiguityMargin is how close the top two candidates must be to count as ambiguous
+	ACNameAmbiguityMargin = 0.05
+
+	// phoneticBaseConfidence is the confidence given to a sound-alike match before spelling similarity
+	phoneticBaseConfidence = 0.85
+)
+
+// ACNameCandidate is a possible AC for a user-typed name
+type ACNameCandidate struct {
+	AC         *AssemblyConstituency
+	MatchedOn  string  // The AC name or alias that matched
+	Confidence float64 // 0.0 to 1.0
+	MatchType  string  // "exact", "alias", "phonetic", "fuzzy"
+}
+
+// acNameEntry is a precomputed AC name or alias for fuzzy matching
+type acNameEntry struct {
+	ac         *AssemblyConstituency
+	name       string
+	normalized string
+	phonetic   string
+}
+
+// newACNameEntry precomputes the normalized and phonetic forms of a name
+func newACNameEntry(ac *AssemblyConstituency, name string) acNameEntry {
+	normalized := normalizeACName(name)
+	return acNameEntry{
+		ac:         ac,
+		name:       name,
+		normalized: normalized,
+		phonetic:   boothmatching.PhoneticEncode(strings.ReplaceAll(normalized, " ", "")),
+	}
+}
+
+// normalizeACName normalizes an AC name or slug for fuzzy comparison.
+// Reservation suffixes such as "(SC)" are dropped.
+func normalizeACName(name string) string {
+	name = strings.ReplaceAll(name, "_", " ")
+	name = strings.ReplaceAll(name, "–", " ")
+	lower := strings.ToLower(strings.TrimSpace(name))
+	for _, suffix := range []string{"(sc)", "(st)"} {
+		lower = strings.TrimSpace(strings.TrimSuffix(lower, suffix))
+	}
+	return boothmatching.Normalize(lower)
+}
+
+// loadACNameAliasesLocked indexes AC name aliases (must hold lock)
+func (g *GeoIndex) loadACNameAliasesLocked() error {
+	aliases, err := LoadACNameAliasesFS(g.fsys)
+	if err != nil {
+		return err
+	}
+	g.indexACNameAliasesLocked(aliases)
+	return nil
+}
+
+// indexACNameAliasesLocked indexes AC name aliases by state name (must hold lock)
+func (g *GeoIndex) indexACNameAliasesLocked(aliases map[string]map[string]string) {
+	g.acNameAliases = aliases
+	for stateName, stateAliases := range aliases {
+		stateSlug := ToSlug(stateName)
+		for acName, alias := range stateAliases {
+			ac, ok := g.acsByNameMap[fmt.Sprintf("%s:%s", stateSlug, ToSlug(acName))]
+			if !ok {
+				continue
+			}
+
+			g.acsByAlias[fmt.Sprintf("%s:%s", stateSlug, ToSlug(alias))] = ac
+			g.acNamesByState[stateSlug] = append(g.acNamesByState[stateSlug], newACNameEntry(ac, alias))
+		}
+	}
+}
+
+// ResolveACName resolves a user-typed AC name within a state. It tries an
+// exact slug match, then the alias table, then fuzzy and phonetic matching.
+// Candidates are returned best first. If the best candidates are too close
+// to tell apart, they are returned together with ErrAmbiguousACName.
+func (g *GeoIndex) ResolveACName(stateSlug, name string) ([]ACNameCandidate, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	key := fmt.Sprintf("%s:%s", stateSlug, ToSlug(name))
+	if ac, ok := g.acsByNameMap[key]; ok {
+		return []ACNameCandidate{{AC: ac, MatchedOn: ac.Name, Confidence: 1.0, MatchType: "exact"}}, nil
+	}
+	if ac, ok := g.acsByAlias[key]; ok {
+		return []ACNameCandidate{{AC: ac, MatchedOn: name, Confidence: 1.0, MatchType: "alias"}}, nil
+	}
+
+	input := newACNameEntry(nil, name)
+	if input.normalized == "" {
+		return nil, fmt.Errorf("%w: empty name", ErrACNotFound)
+	}
+
+	// Keep the best scoring name or alias for each AC
+	best := make(map[*AssemblyConstituency]ACNameCandidate)
+	for _, entry := range g.acNamesByState[stateSlug] {
+		confidence, matchType := scoreACName(input, entry)
+		if confidence < MinACNameConfidence {
+			continue
+		}
+		if current, ok := best[entry.ac]; !ok || confidence > current.Confidence {
+			best[entry.ac] = ACNameCandidate{AC: entry.ac, MatchedOn: entry.name, Confidence: confidence, MatchType: matchType}
+		}
+	}
+
+	if len(best) == 0 {
+		return nil, fmt.Errorf("%w: %s/%s", ErrACNotFound, stateSlug, name)
+	}
+
+	candidates := make([]ACNameCandidate, 0, len(best))
+	for _, candidate := range best {
+		candidates = append(candidates, candidate)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Confidence != candidates[j].Confidence {
+			return candidates[i].Confidence > candidates[j].Confidence
+		}
+		return candidates[i].AC.ACNumber < candidates[j].AC.ACNumber
+	})
+
+	if len(candidates) > 1 && candidates[0].Confidence-candidates[1].Confidence < ACNameAmbiguityMargin {
+		return candidates, fmt.Errorf("%w: %q matches %s and %s", ErrAmbiguousACName, name,
+			candidates[0].AC.Name, candidates[1].AC.Name)
+	}
+
+	return candidates, nil
+}
+
+// scoreACName scores how well an input name matches an AC name or alias
+func scoreACName(input, entry acNameEntry) (float64, string) {
+	if input.normalized == entry.normalized {
+		return 1.0, "exact"
+	}
+
+	maxLen := max(len(input.normalized), len(entry.normalized))
+	distance := fuzzy.LevenshteinDistance(input.normalized, entry.normalized)
+	similarity := 1.0 - float64(distance)/float64(maxLen)
+
+	// Sound-alike names rank above spelling similarity alone, ordered by spelling
+	if input.phonetic != "" && input.phonetic == entry.phonetic {
+		return phoneticBaseConfidence + (1-phoneticBaseConfidence)*similarity, "phonetic"
+	}
+
+	return similarity, "fuzzy"
+}
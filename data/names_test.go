@@ -0,0 +1,89 @@
+package data
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeACName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Churah (SC)", "churah"},
+		{"Ziro–Hapoli", "ziro hapoli"},
+		{"nellore_rural", "nellore rural"},
+		{"  Ponnur ", "ponnur"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := normalizeACName(tt.input); result != tt.expected {
+				t.Errorf("normalizeACName(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetACByNameResolvesAliases(t *testing.T) {
+	index := NewGeoIndex(".")
+	if err := index.LoadAll(); err != nil {
+		t.Skipf("data not available: %v", err)
+	}
+
+	tests := []struct {
+		input     string
+		expected  string
+		matchType string
+	}{
+		{"palakollu", "Palakollu", "exact"},
+		{"Palacole", "Palakollu", "alias"},
+		{"Ponnur", "Ponnuru", "alias"},
+		{"Ichchapuram", "Ichchapuram", "exact"},
+		{"Ichapuram", "Ichchapuram", "phonetic"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			ac, ok := index.GetACByName("andhra_pradesh", tt.input)
+			if !ok {
+				t.Fatalf("GetACByName(%q) not found", tt.input)
+			}
+			if ac.Name != tt.expected {
+				t.Errorf("GetACByName(%q) = %q, want %q", tt.input, ac.Name, tt.expected)
+			}
+
+			candidates, err := index.ResolveACName("andhra_pradesh", tt.input)
+			if err != nil {
+				t.Fatalf("ResolveACName(%q) error: %v", tt.input, err)
+			}
+			if candidates[0].MatchType != tt.matchType {
+				t.Errorf("ResolveACName(%q) match type = %q, want %q", tt.input, candidates[0].MatchType, tt.matchType)
+			}
+		})
+	}
+}
+
+func TestResolveACNameAmbiguous(t *testing.T) {
+	index := newTestIndex(t)
+
+	// Add two ACs whose names differ by one letter from the input
+	index.mu.Lock()
+	for _, name := range []string{"Rampur", "Rampura"} {
+		ac := &AssemblyConstituency{Name: name, StateSlug: "testland"}
+		index.acNamesByState["testland"] = append(index.acNamesByState["testland"], newACNameEntry(ac, name))
+	}
+	index.mu.Unlock()
+
+	candidates, err := index.ResolveACName("testland", "Rampurr")
+	if !errors.Is(err, ErrAmbiguousACName) {
+		t.Fatalf("ResolveACName() error = %v, want ErrAmbiguousACName", err)
+	}
+	if len(candidates) < 2 {
+		t.Errorf("ResolveACName() returned %d candidates, want at least 2", len(candidates))
+	}
+
+	if _, err := index.ResolveACName("testland", "Zzyzx"); !errors.Is(err, ErrACNotFound) {
+		t.Errorf("ResolveACName(unknown) error = %v, want ErrACNotFound", err)
+	}
+}
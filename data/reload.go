@@ -0,0 +1,190 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ChangeEvent describes a reload of the index
+type ChangeEvent struct {
+	Generation uint64 // Generation after the reload
+	StateSlug  string // Reloaded state, empty for a full reload
+}
+
+// Generation returns the data generation. It starts at 0 and increases
+// with every ReloadState or ReloadAll.
+func (g *GeoIndex) Generation() uint64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.generation
+}
+
+// OnChange registers a callback run after every reload, for example to
+// rebuild matchers from BoothMatcherForAC, which keep their own copy of the
+// booths. Callbacks run synchronously, in registration order, without the
+// index lock held.
+func (g *GeoIndex) OnChange(fn func(ChangeEvent)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onChange = append(g.onChange, fn)
+}
+
+// ReloadState re-reads the booths, AC boundaries and district boundaries of a
+// state that are currently loaded. Files are read before the index is locked,
+// and the old data is swapped for the new under a single lock, so readers see
+// either the old or the new generation. Data that is not loaded, and the AC
+// adjacency graph, are picked up on next use as usual; an H3 AC table in use
+// is dropped. Pointers handed out before the reload keep referring to the old
+// generation.
+func (g *GeoIndex) ReloadState(stateSlug string) error {
+	g.mu.RLock()
+	reloadBooths := g.loadedStates[stateSlug]
+	reloadBounds := g.loadedBounds[stateSlug]
+	reloadDistricts := g.loadedDistricts[stateSlug]
+	g.mu.RUnlock()
+
+	// A state whose files were removed is dropped from the index
+	var booths []PollingBooth
+	var cells []string
+	if reloadBooths {
+		var err error
+		booths, err = LoadBoothsForStateFS(g.fsys, stateSlug)
+		if err != nil && !errors.Is(err, ErrStateNotFound) {
+			return fmt.Errorf("reloading booths for %s: %w", stateSlug, err)
+		}
+		reloadBooths = err == nil
+
+		// Compute cells before locking so readers are not held up by H3
+		cells = make([]string, len(booths))
+		for i := range booths {
+			cells[i], _ = g.boothCell(&booths[i])
+		}
+	}
+
+	var boundaries []ACBoundary
+	if reloadBounds {
+		var err error
+		boundaries, err = LoadBoundariesForStateFS(g.fsys, stateSlug)
+		if err != nil && !errors.Is(err, ErrStateNotFound) {
+			return fmt.Errorf("reloading boundaries for %s: %w", stateSlug, err)
+		}
+		reloadBounds = err == nil
+	}
+
+	var districts []DistrictBoundary
+	if reloadDistricts {
+		var err error
+		districts, err = LoadDistrictBoundariesForStateFS(g.fsys, stateSlug)
+		if err != nil && !errors.Is(err, ErrStateNotFound) {
+			return fmt.Errorf("reloading district boundaries for %s: %w", stateSlug, err)
+		}
+		reloadDistricts = err == nil
+	}
+
+	g.mu.Lock()
+	g.unindexBoothsLocked(stateSlug)
+	g.unindexBoundariesLocked(stateSlug)
+	g.unindexDistrictBoundariesLocked(stateSlug)
+	delete(g.boundaryExtents, stateSlug)
+	delete(g.boothExtents, stateSlug)
+	if reloadBooths {
+		g.indexBoothsLocked(stateSlug, booths, cells)
+	}
+	if reloadBounds {
+		g.indexBoundariesLocked(stateSlug, boundaries)
+	}
+	if reloadDistricts {
+		g.indexDistrictBoundariesLocked(stateSlug, districts)
+	}
+	g.availableBounds = nil
+	g.acGraph = nil
+	g.h3ACTable = nil
+	g.evictLocked()
+	g.generation++
+	event := ChangeEvent{Generation: g.generation, StateSlug: stateSlug}
+	g.mu.Unlock()
+
+	g.notifyChange(event)
+	return nil
+}
+
+// ReloadAll rebuilds the whole index from the data source. Everything that
+// was loaded is loaded again into a separate index first, then swapped in
+// under a single lock, dropping any H3 AC table in use. On error the current
+// generation is kept.
+func (g *GeoIndex) ReloadAll() error {
+	g.mu.RLock()
+	config := g.config
+	boothStates := loadedStateSlugs(g.loadedStates)
+	boundStates := loadedStateSlugs(g.loadedBounds)
+	districtStates := loadedStateSlugs(g.loadedDistricts)
+	g.mu.RUnlock()
+
+	// Build the next generation without a budget so nothing is evicted on the way
+	config.MemoryBudgetBytes = 0
+	next := NewGeoIndexFSWithConfig(g.fsys, config)
+	if err := next.LoadAll(); err != nil {
+		return err
+	}
+	for _, stateSlug := range boothStates {
+		if err := next.LoadBoothsForState(stateSlug); err != nil && !errors.Is(err, ErrStateNotFound) {
+			return fmt.Errorf("reloading booths for %s: %w", stateSlug, err)
+		}
+	}
+	for _, stateSlug := range boundStates {
+		if err := next.LoadBoundariesForState(stateSlug); err != nil && !errors.Is(err, ErrStateNotFound) {
+			return fmt.Errorf("reloading boundaries for %s: %w", stateSlug, err)
+		}
+	}
+	for _, stateSlug := range districtStates {
+		if err := next.LoadDistrictBoundariesForState(stateSlug); err != nil && !errors.Is(err, ErrStateNotFound) {
+			return fmt.Errorf("reloading district boundaries for %s: %w", stateSlug, err)
+		}
+	}
+
+	next.mu.RLock()
+	data := next.snapshotDataLocked()
+	next.mu.RUnlock()
+
+	g.mu.Lock()
+	g.applySnapshotLocked(data)
+	g.h3ACTable = nil
+	g.generation++
+	event := ChangeEvent{Generation: g.generation}
+	g.mu.Unlock()
+
+	g.notifyChange(event)
+	return nil
+}
+
+// notifyChange runs the registered change callbacks
+func (g *GeoIndex) notifyChange(event ChangeEvent) {
+	g.mu.RLock()
+	callbacks := g.onChange
+	g.mu.RUnlock()
+
+	for _, fn := range callbacks {
+		fn(event)
+	}
+}
+
+// unindexDistrictBoundariesLocked removes a state's district boundaries from every index (must hold lock)
+func (g *GeoIndex) unindexDistrictBoundariesLocked(stateSlug string) {
+	for _, boundary := range g.districtBoundariesByState[stateSlug] {
+		delete(g.districtBoundaryByName, fmt.Sprintf("%s:%s", stateSlug, boundary.Slug()))
+	}
+
+	delete(g.districtBoundariesByState, stateSlug)
+	delete(g.districtBoundaryIndex, stateSlug)
+	delete(g.loadedDistricts, stateSlug)
+	g.untrackResidentLocked(residentDistricts, stateSlug)
+}
+
+// loadedStateSlugs returns the states marked as loaded
+func loadedStateSlugs(loaded map[string]bool) []string {
+	stateSlugs := make([]string, 0, len(loaded))
+	for stateSlug := range loaded {
+		stateSlugs = append(stateSlugs, stateSlug)
+	}
+	return stateSlugs
+}
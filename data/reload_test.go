@@ -0,0 +1,176 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// writeTestBooths replaces the test booth file with n located booths in AC 1,
+// each named after the given revision
+func writeTestBooths(t *testing.T, dataDir, revision string, n int) {
+	t.Helper()
+	booths := make([]string, n)
+	for i := range booths {
+		booths[i] = fmt.Sprintf(`{"partId": %d, "acNumber": 1, "acName": "Alpha", "partNumber": %d,
+			"partName": "School %s", "lat": 12.5, "lon": 77.5}`, i+1, i+1, revision)
+	}
+
+	// Write then rename, so concurrent lazy loads never read a partial file
+	path := filepath.Join(dataDir, BoothsDir, "testland", "north.json")
+	if err := os.WriteFile(path+".tmp", []byte("["+strings.Join(booths, ",")+"]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadState(t *testing.T) {
+	dataDir := writeTestData(t, testDataFiles())
+	index := NewGeoIndex(dataDir)
+	if err := index.LoadAll(); err != nil {
+		t.Fatalf("LoadAll() error: %v", err)
+	}
+
+	var events []ChangeEvent
+	index.OnChange(func(event ChangeEvent) { events = append(events, event) })
+
+	old, err := index.GetBooth("testland", 1, 1)
+	if err != nil {
+		t.Fatalf("GetBooth() error: %v", err)
+	}
+
+	writeTestBooths(t, dataDir, "v2", 4)
+	if err := index.ReloadState("testland"); err != nil {
+		t.Fatalf("ReloadState() error: %v", err)
+	}
+
+	if index.Generation() != 1 || len(events) != 1 || events[0].StateSlug != "testland" {
+		t.Errorf("after ReloadState: generation %d, events %+v, want one testland event", index.Generation(), events)
+	}
+
+	booth, err := index.GetBooth("testland", 1, 1)
+	if err != nil || booth.PartName != "School v2" {
+		t.Errorf("GetBooth() after reload = %v, %v, want School v2", booth, err)
+	}
+	if old.PartName != "Govt School, Alpha" {
+		t.Errorf("old booth pointer changed to %q", old.PartName)
+	}
+	if _, err := index.GetBooth("testland", 2, 2); !errors.Is(err, ErrBoothNotFound) {
+		t.Errorf("GetBooth() for removed booth error = %v, want ErrBoothNotFound", err)
+	}
+
+	cellID, _ := index.BoothCell(booth)
+	if booths, err := index.BoothsInH3Cell(cellID); err != nil || len(booths) != 4 {
+		t.Errorf("BoothsInH3Cell() after reload = %d booths, %v, want 4", len(booths), err)
+	}
+
+	// A state whose booth files are gone is dropped
+	if err := os.RemoveAll(filepath.Join(dataDir, BoothsDir, "testland")); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.ReloadState("testland"); err != nil {
+		t.Fatalf("ReloadState() after removal error: %v", err)
+	}
+	if _, err := index.GetBoothsForState("testland"); !errors.Is(err, ErrStateNotFound) {
+		t.Errorf("GetBoothsForState() after removal error = %v, want ErrStateNotFound", err)
+	}
+}
+
+func TestReloadAll(t *testing.T) {
+	files := testDataFiles()
+	dataDir := writeTestData(t, files)
+	index := NewGeoIndex(dataDir)
+	if err := index.LoadAll(); err != nil {
+		t.Fatalf("LoadAll() error: %v", err)
+	}
+	if _, err := index.GetBoothsForState("testland"); err != nil {
+		t.Fatalf("GetBoothsForState() error: %v", err)
+	}
+
+	var events []ChangeEvent
+	index.OnChange(func(event ChangeEvent) { events = append(events, event) })
+
+	renamed := strings.Replace(files[AssemblyConstituenciesFile], `"Alpha"`, `"Alpha Nagar"`, 1)
+	if err := os.WriteFile(filepath.Join(dataDir, AssemblyConstituenciesFile), []byte(renamed), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeTestBooths(t, dataDir, "v2", 2)
+
+	if err := index.ReloadAll(); err != nil {
+		t.Fatalf("ReloadAll() error: %v", err)
+	}
+
+	if index.Generation() != 1 || len(events) != 1 || events[0].StateSlug != "" {
+		t.Errorf("after ReloadAll: generation %d, events %+v, want one full reload event", index.Generation(), events)
+	}
+	if ac, ok := index.GetACByNumber("testland", 1); !ok || ac.Name != "Alpha Nagar" {
+		t.Errorf("GetACByNumber(1) after reload = %v, %v, want Alpha Nagar", ac, ok)
+	}
+
+	// Booths that were loaded are reloaded too
+	if stats := index.GetStats(); stats.StatesWithBooths != 1 || stats.BoothsLoaded != 2 {
+		t.Errorf("GetStats() after reload = %+v, want the 2 new booths loaded", stats)
+	}
+}
+
+func TestReloadStateConsistentReads(t *testing.T) {
+	dataDir := writeTestData(t, testDataFiles())
+	writeTestBooths(t, dataDir, "v0", 50)
+	index := NewGeoIndex(dataDir)
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	errs := make(chan error, 1)
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				booths, err := index.GetBoothsForAC("testland", 1)
+				if err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+					return
+				}
+				// Every booth must come from the same generation
+				for _, booth := range booths {
+					if booth.PartName != booths[0].PartName || len(booths) != 50 {
+						select {
+						case errs <- fmt.Errorf("mixed generations: %q and %q", booths[0].PartName, booth.PartName):
+						default:
+						}
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	for i := 1; i <= 10; i++ {
+		writeTestBooths(t, dataDir, fmt.Sprintf("v%d", i), 50)
+		if err := index.ReloadState("testland"); err != nil {
+			t.Fatalf("ReloadState() error: %v", err)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		t.Error(err)
+	default:
+	}
+}
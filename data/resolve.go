@@ -0,0 +1,129 @@
+package data
+
+import (
+	"errors"
+
+	h3utils "github.com/politic-in/core/h3-utils"
+)
+
+// ResolveConfig configures Resolve
+type ResolveConfig struct {
+	// EdgeMarginM is the distance to an AC border, in metres, within which a
+	// point is flagged as near the edge. Typical phone GPS error is 10-50 m.
+	EdgeMarginM float64
+
+	// GPSErrorM weighs the candidate ACs of points near an edge, see ACCandidateConfig
+	GPSErrorM float64
+
+	// BoothRadiusM and BoothLimit bound the nearest booths returned
+	BoothRadiusM float64
+	BoothLimit   int
+}
+
+// DefaultResolveConfig returns the default resolve configuration
+func DefaultResolveConfig() ResolveConfig {
+	return ResolveConfig{
+		EdgeMarginM:  100,
+		GPSErrorM:    30,
+		BoothRadiusM: 5000,
+		BoothLimit:   5,
+	}
+}
+
+// ResolvedCells holds a point's H3 cells at the standard resolutions
+type ResolvedCells struct {
+	State        string // h3utils.StateResolution
+	District     string // h3utils.DistrictResolution
+	AC           string // h3utils.ACResolution
+	Neighborhood string // h3utils.NeighborhoodResolution
+}
+
+// ResolveResult is the electoral hierarchy at a point
+type ResolveResult struct {
+	Lat float64
+	Lng float64
+
+	StateSlug  string
+	State      *State
+	ACBoundary *ACBoundary
+	AC         *AssemblyConstituency      // nil if the boundary lookup has no entry for the AC
+	PC         *ParliamentaryConstituency // nil if the PC dataset is not loaded
+
+	// District is nil if the state has no district boundaries or the point
+	// falls outside them
+	District         *District
+	DistrictBoundary *DistrictBoundary
+
+	NearestBooths []NearbyBooth // Located booths within BoothRadiusM, nearest first
+	Cells         ResolvedCells
+
+	EdgeDistanceM float64 // Distance to the nearest edge of ACBoundary
+	NearEdge      bool    // EdgeDistanceM is within EdgeMarginM, so the AC may be wrong
+
+	// Candidates lists, when NearEdge, every AC within EdgeMarginM with its
+	// likelihood, most likely first, including ACs of neighbouring states
+	// across a state border; ACCandidate.StateSlug gives each AC's state
+	Candidates []ACCandidate
+}
+
+// Resolve returns the state, district, AC, nearest booths and H3 cells at a
+// point in one call. Only the AC is required: missing district boundaries,
+// booths or metadata leave the corresponding fields empty.
+func (g *GeoIndex) Resolve(lat, lng float64) (*ResolveResult, error) {
+	return g.ResolveWithConfig(lat, lng, DefaultResolveConfig())
+}
+
+// ResolveWithConfig resolves a point with custom configuration
+func (g *GeoIndex) ResolveWithConfig(lat, lng float64, config ResolveConfig) (*ResolveResult, error) {
+	boundary, stateSlug, err := g.FindACAtPointAllStates(lat, lng)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ResolveResult{
+		Lat:        lat,
+		Lng:        lng,
+		StateSlug:  stateSlug,
+		ACBoundary: boundary,
+		Cells: ResolvedCells{
+			State:        h3utils.LatLngToCellAtResolution(lat, lng, h3utils.StateResolution),
+			District:     h3utils.LatLngToCellAtResolution(lat, lng, h3utils.DistrictResolution),
+			AC:           h3utils.LatLngToCellAtResolution(lat, lng, h3utils.ACResolution),
+			Neighborhood: h3utils.LatLngToCellAtResolution(lat, lng, h3utils.NeighborhoodResolution),
+		},
+		EdgeDistanceM: boundary.DistanceToBoundary(lat, lng),
+	}
+	result.NearEdge = result.EdgeDistanceM <= config.EdgeMarginM
+	if result.NearEdge {
+		candidateConfig := ACCandidateConfig{MarginM: config.EdgeMarginM, GPSErrorM: config.GPSErrorM}
+		if result.Candidates, err = g.FindACCandidatesWithConfig(stateSlug, lat, lng, candidateConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	result.State, _ = g.GetStateBySlug(stateSlug)
+	result.AC, _ = g.GetACByConsCode(stateSlug, boundary.ConsCode)
+	result.PC, _ = g.GetPCForAC(stateSlug, boundary.ConsCode)
+
+	districtBoundary, err := g.FindDistrictAtPoint(stateSlug, lat, lng)
+	switch {
+	case err == nil:
+		result.DistrictBoundary = districtBoundary
+		result.District, _ = g.GetDistrictByName(stateSlug, districtBoundary.Slug())
+	case !errors.Is(err, ErrStateNotFound) && !errors.Is(err, ErrDistrictNotFound):
+		return nil, err
+	}
+
+	nearby, err := g.NearbyBoothsWithinRadius(stateSlug, lat, lng, config.BoothRadiusM)
+	switch {
+	case err == nil:
+		result.NearestBooths = nearby.Booths
+		if config.BoothLimit > 0 && len(result.NearestBooths) > config.BoothLimit {
+			result.NearestBooths = result.NearestBooths[:config.BoothLimit]
+		}
+	case !errors.Is(err, ErrStateNotFound):
+		return nil, err
+	}
+
+	return result, nil
+}
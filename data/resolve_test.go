@@ -0,0 +1,106 @@
+package data
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	h3utils "github.com/politic-in/core/h3-utils"
+)
+
+func TestResolve(t *testing.T) {
+	index := newTestIndex(t)
+
+	result, err := index.Resolve(12.5, 77.5)
+	if err != nil {
+		t.Fatalf("Resolve() error: %v", err)
+	}
+
+	if result.StateSlug != "testland" || result.State == nil || result.State.StateID != "TL" {
+		t.Errorf("state = %q, %+v", result.StateSlug, result.State)
+	}
+	if result.ACBoundary.ConsCode != 1 || result.AC == nil || result.AC.Name != "Alpha" || result.AC.IsReserved() {
+		t.Errorf("AC = %+v, boundary %+v", result.AC, result.ACBoundary)
+	}
+	if result.PC == nil || result.PC.Name != "West" {
+		t.Errorf("PC = %+v, want West", result.PC)
+	}
+	if result.District == nil || result.District.Name != "North" || result.DistrictBoundary == nil {
+		t.Errorf("district = %+v, boundary %+v", result.District, result.DistrictBoundary)
+	}
+	if len(result.NearestBooths) == 0 || result.NearestBooths[0].Booth.PartID != 1 || result.NearestBooths[0].DistanceM > 1 {
+		t.Errorf("NearestBooths = %+v, want booth 1 first", result.NearestBooths)
+	}
+
+	cells := map[int]string{
+		h3utils.StateResolution:        result.Cells.State,
+		h3utils.DistrictResolution:     result.Cells.District,
+		h3utils.ACResolution:           result.Cells.AC,
+		h3utils.NeighborhoodResolution: result.Cells.Neighborhood,
+	}
+	for resolution, cellID := range cells {
+		if got, err := h3utils.GetResolution(cellID); err != nil || got != resolution {
+			t.Errorf("cell %q resolution = %d, %v, want %d", cellID, got, err, resolution)
+		}
+	}
+
+	if result.NearEdge || result.EdgeDistanceM < 50000 {
+		t.Errorf("centre of AC: NearEdge = %v, EdgeDistanceM = %.0f", result.NearEdge, result.EdgeDistanceM)
+	}
+}
+
+func TestResolveNearEdge(t *testing.T) {
+	index := newTestIndex(t)
+
+	// About 54 m west of the Alpha-Beta border at longitude 78
+	result, err := index.Resolve(12.5, 77.9995)
+	if err != nil {
+		t.Fatalf("Resolve() error: %v", err)
+	}
+	if result.ACBoundary.ConsCode != 1 || !result.NearEdge || result.EdgeDistanceM > 60 {
+		t.Errorf("AC %d, NearEdge = %v, EdgeDistanceM = %.1f", result.ACBoundary.ConsCode, result.NearEdge, result.EdgeDistanceM)
+	}
+	if len(result.Candidates) != 2 || result.Candidates[0].Boundary.ConsCode != 1 || result.Candidates[1].Boundary.ConsCode != 2 {
+		t.Errorf("Candidates = %+v, want Alpha then Beta", result.Candidates)
+	}
+
+	config := DefaultResolveConfig()
+	config.EdgeMarginM = 20
+	result, err = index.ResolveWithConfig(12.5, 77.9995, config)
+	if err != nil {
+		t.Fatalf("ResolveWithConfig() error: %v", err)
+	}
+	if result.NearEdge || result.Candidates != nil {
+		t.Errorf("NearEdge with 20 m margin = true, want false")
+	}
+}
+
+func TestResolveOptionalData(t *testing.T) {
+	files := testDataFiles()
+	for name := range files {
+		if name != StatesFile && name != DistrictsFile && name != AssemblyConstituenciesFile &&
+			name != PartiesFile && name != filepath.Join(BoundariesDir, "testland.geojson") {
+			delete(files, name)
+		}
+	}
+	index := NewGeoIndex(writeTestData(t, files))
+	if err := index.LoadAll(); err != nil {
+		t.Fatalf("LoadAll() error: %v", err)
+	}
+
+	result, err := index.Resolve(12.5, 78.5)
+	if err != nil {
+		t.Fatalf("Resolve() error: %v", err)
+	}
+	if result.ACBoundary.ConsCode != 2 || result.AC != nil || result.PC != nil || result.District != nil || len(result.NearestBooths) != 0 {
+		t.Errorf("Resolve() without optional data = %+v", result)
+	}
+}
+
+func TestResolveOutsideAllACs(t *testing.T) {
+	index := newTestIndex(t)
+
+	if _, err := index.Resolve(20, 70); !errors.Is(err, ErrACNotFound) {
+		t.Errorf("Resolve() outside error = %v, want ErrACNotFound", err)
+	}
+}
@@ -0,0 +1,355 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// ErrInvalidLOD is returned for a level of detail the index is not configured with
+var ErrInvalidLOD = errors.New("invalid level of detail")
+
+// coarseBoundary is a simplified copy of a boundary used by ContainsPoint.
+// Every point of the full boundary lies within toleranceM of the coarse one
+// and vice versa, so points farther than that from the coarse edges are
+// inside the full boundary exactly when they are inside the coarse one.
+type coarseBoundary struct {
+	polygons   [][][][]float64
+	toleranceM float64
+	bbox       [4]float64 // Bounding box of the full boundary
+}
+
+// contains reports whether a point is inside the boundary, and false for ok
+// if the point is too close to the border to tell from the coarse copy
+func (c *coarseBoundary) contains(lat, lng float64) (inside, ok bool) {
+	if !bboxContainsPoint(c.bbox, lat, lng) {
+		return false, true
+	}
+
+	// Allow for the flat-earth approximation used when simplifying
+	if multiPolygonEdgeWithin(c.polygons, lat, lng, c.toleranceM*1.01+1) {
+		return false, false
+	}
+	return multiPolygonContains(c.polygons, lat, lng), true
+}
+
+// multiPolygonEdgeWithin reports whether any ring edge is within distanceM
+// metres of a point. Edges whose box is clearly farther are skipped without
+// measuring.
+func multiPolygonEdgeWithin(polygons [][][][]float64, lat, lng, distanceM float64) bool {
+	const metresPerDegree = 111320.0
+	kx := metresPerDegree * math.Cos(lat*math.Pi/180)
+	dLng, dLat := distanceM/kx, distanceM/metresPerDegree
+
+	for _, polygon := range polygons {
+		for _, ring := range polygon {
+			for i := 1; i < len(ring); i++ {
+				a, b := ring[i-1], ring[i]
+				if (a[0] < lng-dLng && b[0] < lng-dLng) || (a[0] > lng+dLng && b[0] > lng+dLng) ||
+					(a[1] < lat-dLat && b[1] < lat-dLat) || (a[1] > lat+dLat && b[1] > lat+dLat) {
+					continue
+				}
+				ax, ay := (a[0]-lng)*kx, (a[1]-lat)*metresPerDegree
+				bx, by := (b[0]-lng)*kx, (b[1]-lat)*metresPerDegree
+				if originSegmentDistance(ax, ay, bx, by) <= distanceM {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// newCoarseBoundary simplifies a boundary for fast containment checks. It
+// returns nil if simplification does not at least halve the vertex count.
+func newCoarseBoundary(boundary *ACBoundary, toleranceM float64) *coarseBoundary {
+	if toleranceM <= 0 {
+		return nil
+	}
+
+	polygons := boundary.GetPolygons()
+	coarse := simplifyPolygons(polygons, toleranceM, nil)
+	if 2*countVertices(coarse) > countVertices(polygons) {
+		return nil
+	}
+	return &coarseBoundary{polygons: coarse, toleranceM: toleranceM, bbox: boundary.BoundingBox()}
+}
+
+// Simplify returns a copy of the boundary simplified with Douglas–Peucker so
+// that no dropped vertex is more than toleranceM metres from the result.
+// Each boundary is simplified on its own; use SimplifyBoundaries to keep
+// shared borders between neighbouring ACs identical.
+func (b ACBoundary) Simplify(toleranceM float64) ACBoundary {
+	return b.withPolygons(simplifyPolygons(b.GetPolygons(), toleranceM, nil))
+}
+
+// SimplifyBoundaries simplifies a set of boundaries, such as a state's ACs,
+// with Douglas–Peucker while preserving topology between them: borders
+// shared by neighbouring ACs are split at the vertices where neighbours
+// change and simplified identically on both sides, so the simplified ACs
+// meet without gaps or overlaps along them. Only borders whose vertices are
+// shared within the set are preserved; a border with an AC outside the set
+// is simplified like any other edge.
+func SimplifyBoundaries(boundaries []*ACBoundary, toleranceM float64) []ACBoundary {
+	owners := make(map[[2]float64][]int)
+	for i, boundary := range boundaries {
+		for _, polygon := range boundary.GetPolygons() {
+			for _, ring := range polygon {
+				for _, pt := range ring {
+					key := [2]float64{pt[0], pt[1]}
+					if list := owners[key]; len(list) == 0 || list[len(list)-1] != i {
+						owners[key] = append(list, i)
+					}
+				}
+			}
+		}
+	}
+
+	// A vertex is an anchor where the set of ACs sharing the border changes
+	isAnchor := func(ring [][]float64, i int) bool {
+		n := len(ring)
+		current := owners[[2]float64{ring[i][0], ring[i][1]}]
+		prev := ring[(i+n-1)%n]
+		next := ring[(i+1)%n]
+		return !slices.Equal(current, owners[[2]float64{prev[0], prev[1]}]) ||
+			!slices.Equal(current, owners[[2]float64{next[0], next[1]}])
+	}
+
+	result := make([]ACBoundary, len(boundaries))
+	for i, boundary := range boundaries {
+		result[i] = boundary.withPolygons(simplifyPolygons(boundary.GetPolygons(), toleranceM, isAnchor))
+	}
+	return result
+}
+
+// withPolygons returns a copy of the boundary with its geometry replaced
+func (b ACBoundary) withPolygons(polygons [][][][]float64) ACBoundary {
+	b.Polygons = polygons
+	b.Polygon = nil
+	if len(polygons) > 0 {
+		b.Polygon = polygons[0]
+	}
+	b.coarse = nil
+	return b
+}
+
+// simplifyPolygons simplifies every ring. isAnchor, if set, reports vertices
+// of an open ring that must be kept.
+func simplifyPolygons(polygons [][][][]float64, toleranceM float64, isAnchor func(ring [][]float64, i int) bool) [][][][]float64 {
+	result := make([][][][]float64, len(polygons))
+	for i, polygon := range polygons {
+		result[i] = make([][][]float64, len(polygon))
+		for j, ring := range polygon {
+			result[i][j] = simplifyRing(ring, toleranceM, isAnchor)
+		}
+	}
+	return result
+}
+
+// simplifyRing simplifies a closed ring between its anchors. Each stretch
+// between consecutive anchors is simplified in a canonical direction, so a
+// border shared with a neighbouring ring comes out the same in both. Rings
+// that would collapse below a triangle are kept as they are.
+func simplifyRing(ring [][]float64, toleranceM float64, isAnchor func(ring [][]float64, i int) bool) [][]float64 {
+	if len(ring) < 4 || toleranceM <= 0 {
+		return ring
+	}
+
+	open := ring
+	if ringClosed(ring) {
+		open = ring[:len(ring)-1]
+	}
+	n := len(open)
+	if n < 3 {
+		return ring
+	}
+
+	keep := make([]bool, n)
+	var anchors []int
+	if isAnchor != nil {
+		for i := range open {
+			if isAnchor(open, i) {
+				anchors = append(anchors, i)
+			}
+		}
+	}
+
+	// Anchor rings without shared borders at points that do not depend on
+	// where the ring starts or which way it runs
+	if len(anchors) == 0 {
+		lowest := 0
+		for i := 1; i < n; i++ {
+			if pointLess(open[i], open[lowest]) {
+				lowest = i
+			}
+		}
+		anchors = []int{lowest}
+	}
+	if len(anchors) == 1 {
+		anchor := open[anchors[0]]
+		farthest, farthestM := -1, -1.0
+		for i, pt := range open {
+			distanceM := planarDistance(anchor, pt)
+			if distanceM > farthestM || (distanceM == farthestM && pointLess(pt, open[farthest])) {
+				farthest, farthestM = i, distanceM
+			}
+		}
+		anchors = append(anchors, farthest)
+		slices.Sort(anchors)
+	}
+
+	for k, start := range anchors {
+		end := anchors[(k+1)%len(anchors)]
+		keep[start] = true
+
+		// Collect the stretch start..end, wrapping around the ring
+		length := (end-start+n)%n + 1
+		if length == 1 {
+			length = n + 1
+		}
+		indices := make([]int, length)
+		for j := range indices {
+			indices[j] = (start + j) % n
+		}
+		if pointLess(open[end], open[start]) {
+			slices.Reverse(indices)
+		}
+		douglasPeucker(open, indices, toleranceM, keep)
+	}
+
+	simplified := make([][]float64, 0, n+1)
+	for i, pt := range open {
+		if keep[i] {
+			simplified = append(simplified, pt)
+		}
+	}
+	if len(simplified) < 3 {
+		return ring
+	}
+	return append(simplified, simplified[0])
+}
+
+// douglasPeucker marks the points of a stretch, given as ring indices, that
+// are kept at toleranceM. Both ends are kept.
+func douglasPeucker(ring [][]float64, indices []int, toleranceM float64, keep []bool) {
+	type span struct{ first, last int }
+
+	keep[indices[0]] = true
+	keep[indices[len(indices)-1]] = true
+
+	stack := []span{{0, len(indices) - 1}}
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		a, b := ring[indices[s.first]], ring[indices[s.last]]
+		farthest, farthestM := -1, toleranceM
+		for i := s.first + 1; i < s.last; i++ {
+			if distanceM := segmentDistance(ring[indices[i]], a, b); distanceM > farthestM {
+				farthest, farthestM = i, distanceM
+			}
+		}
+
+		if farthest >= 0 {
+			keep[indices[farthest]] = true
+			stack = append(stack, span{s.first, farthest}, span{farthest, s.last})
+		}
+	}
+}
+
+// segmentDistance returns the distance in metres from point p to segment ab
+func segmentDistance(p, a, b []float64) float64 {
+	const metresPerDegree = 111320.0
+	kx := metresPerDegree * math.Cos(p[1]*math.Pi/180)
+
+	return originSegmentDistance(
+		(a[0]-p[0])*kx, (a[1]-p[1])*metresPerDegree,
+		(b[0]-p[0])*kx, (b[1]-p[1])*metresPerDegree,
+	)
+}
+
+// planarDistance returns the approximate distance in metres between two points
+func planarDistance(a, b []float64) float64 {
+	return segmentDistance(a, b, b)
+}
+
+// pointLess orders points by longitude, then latitude
+func pointLess(a, b []float64) bool {
+	if a[0] != b[0] {
+		return a[0] < b[0]
+	}
+	return a[1] < b[1]
+}
+
+// countVertices returns the number of points in all rings
+func countVertices(polygons [][][][]float64) int {
+	count := 0
+	for _, polygon := range polygons {
+		for _, ring := range polygon {
+			count += len(ring)
+		}
+	}
+	return count
+}
+
+// GetBoundariesForStateAtLOD returns a state's AC boundaries at a level of
+// detail. Level 0 is the full boundary; level i is simplified with
+// SimplifyBoundaries at config.LODTolerancesM[i-1], so borders between ACs
+// of the state stay identical. Borders with other states' ACs are simplified
+// on each side separately and may gap or overlap by up to the tolerance; the
+// state files do not share vertices along them in any case. Levels are
+// computed on first use and cached until the boundaries are evicted or reloaded.
+func (g *GeoIndex) GetBoundariesForStateAtLOD(stateSlug string, level int) ([]*ACBoundary, error) {
+	if level < 0 || level > len(g.config.LODTolerancesM) {
+		return nil, fmt.Errorf("%w: %d, have levels 0-%d", ErrInvalidLOD, level, len(g.config.LODTolerancesM))
+	}
+
+	boundaries, err := g.GetBoundariesForState(stateSlug)
+	if err != nil || level == 0 {
+		return boundaries, err
+	}
+
+	key := fmt.Sprintf("%s:%d", stateSlug, level)
+	g.mu.RLock()
+	cached, ok := g.boundaryLODs[key]
+	g.mu.RUnlock()
+	if ok {
+		return cached, nil
+	}
+
+	simplified := SimplifyBoundaries(boundaries, g.config.LODTolerancesM[level-1])
+	result := make([]*ACBoundary, len(simplified))
+	for i := range simplified {
+		result[i] = &simplified[i]
+	}
+
+	// Cache unless the boundaries were reloaded or evicted in the meantime
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if current := g.boundariesByState[stateSlug]; len(current) > 0 && current[0] == boundaries[0] {
+		if cached, ok := g.boundaryLODs[key]; ok {
+			return cached, nil
+		}
+		g.boundaryLODs[key] = result
+		g.growResidentLocked(residentBoundaries, stateSlug, estimateBoundaryBytes(simplified))
+		g.evictLocked()
+	}
+	return result, nil
+}
+
+// GetBoundaryForACAtLOD returns an AC boundary at a level of detail, see GetBoundariesForStateAtLOD
+func (g *GeoIndex) GetBoundaryForACAtLOD(stateSlug string, consCode, level int) (*ACBoundary, error) {
+	boundaries, err := g.GetBoundariesForStateAtLOD(stateSlug, level)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, boundary := range boundaries {
+		if boundary.ConsCode == consCode {
+			return boundary, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s/%d", ErrBoundaryNotFound, stateSlug, consCode)
+}
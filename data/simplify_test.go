@@ -0,0 +1,181 @@
+package data
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// testWigglyRing returns a closed ring around (lat, lng) with n vertices whose
+// radius, in degrees, wobbles by amplitude around radius
+func testWigglyRing(lat, lng, radius, amplitude float64, n int) [][]float64 {
+	ring := make([][]float64, 0, n+1)
+	for i := 0; i < n; i++ {
+		angle := 2 * math.Pi * float64(i) / float64(n)
+		r := radius + amplitude*math.Sin(float64(i)*1.7)
+		ring = append(ring, []float64{lng + r*math.Cos(angle), lat + r*math.Sin(angle)})
+	}
+	return append(ring, ring[0])
+}
+
+// testSharedBorder returns points from (78, 12) to (78, 13) that wobble by
+// about 10 m, with one 200 m bump in the middle
+func testSharedBorder() [][]float64 {
+	var border [][]float64
+	for i := 0; i <= 100; i++ {
+		offset := 0.0001 * float64(i%2)
+		if i == 50 {
+			offset = 0.002
+		}
+		border = append(border, []float64{78 + offset, 12 + float64(i)/100})
+	}
+	return border
+}
+
+func TestACBoundarySimplify(t *testing.T) {
+	// A square whose edges carry points within a few metres of the straight line
+	var ring [][]float64
+	for _, side := range [][4]float64{{77, 12, 78, 12}, {78, 12, 78, 13}, {78, 13, 77, 13}, {77, 13, 77, 12}} {
+		for i := 0; i < 10; i++ {
+			f := float64(i) / 10
+			jitter := 0.00002 * float64(i%2)
+			ring = append(ring, []float64{side[0] + f*(side[2]-side[0]) + jitter, side[1] + f*(side[3]-side[1]) + jitter})
+		}
+	}
+	ring = append(ring, ring[0])
+	boundary := ACBoundary{ConsCode: 1, ConsName: "Square", Polygon: [][][]float64{ring}}
+
+	simplified := boundary.Simplify(50)
+	if got := len(simplified.GetExteriorRing()); got != 5 {
+		t.Errorf("simplified ring has %d points, want the 4 corners closed: %v", got, simplified.GetExteriorRing())
+	}
+	if simplified.ConsName != "Square" || len(boundary.GetExteriorRing()) != 41 {
+		t.Error("Simplify() should copy the metadata and leave the original alone")
+	}
+
+	// Nothing is dropped below the wobble
+	if got := len(boundary.Simplify(1).GetExteriorRing()); got != 41 {
+		t.Errorf("ring simplified at 1 m has %d points, want 41", got)
+	}
+}
+
+func TestSimplifyBoundariesSharedBorder(t *testing.T) {
+	border := testSharedBorder()
+
+	// West AC runs up the shared border, east AC runs down it, starting elsewhere
+	west := [][]float64{{77, 12}}
+	west = append(west, border...)
+	west = append(west, []float64{77, 13}, []float64{77, 12})
+
+	east := [][]float64{{79, 13}}
+	for i := len(border) - 1; i >= 0; i-- {
+		east = append(east, border[i])
+	}
+	east = append(east, []float64{79, 12}, []float64{79, 13})
+
+	boundaries := []*ACBoundary{
+		{ConsCode: 1, Polygon: [][][]float64{west}},
+		{ConsCode: 2, Polygon: [][][]float64{east}},
+	}
+	simplified := SimplifyBoundaries(boundaries, 50)
+
+	borderPoints := func(ring [][]float64) map[[2]float64]bool {
+		points := make(map[[2]float64]bool)
+		for _, pt := range ring {
+			if math.Abs(pt[0]-78) < 0.01 {
+				points[[2]float64{pt[0], pt[1]}] = true
+			}
+		}
+		return points
+	}
+
+	westBorder := borderPoints(simplified[0].GetExteriorRing())
+	eastBorder := borderPoints(simplified[1].GetExteriorRing())
+	if len(westBorder) >= len(border) {
+		t.Errorf("shared border kept %d of %d points", len(westBorder), len(border))
+	}
+	if !westBorder[[2]float64{78.002, 12.5}] {
+		t.Error("200 m bump dropped from the shared border")
+	}
+	if len(westBorder) != len(eastBorder) {
+		t.Fatalf("shared border has %d points in the west AC and %d in the east AC", len(westBorder), len(eastBorder))
+	}
+	for pt := range westBorder {
+		if !eastBorder[pt] {
+			t.Errorf("border point %v kept in the west AC only", pt)
+		}
+	}
+}
+
+func TestCoarseContainsPointMatchesFull(t *testing.T) {
+	// A hole keeps the check honest about interior rings
+	boundary := &ACBoundary{Polygon: [][][]float64{
+		testWigglyRing(12.5, 77.5, 0.3, 0.0003, 2000),
+		testWigglyRing(12.5, 77.5, 0.1, 0.0003, 500),
+	}}
+	boundary.coarse = newCoarseBoundary(boundary, 100)
+	if boundary.coarse == nil {
+		t.Fatal("newCoarseBoundary() = nil, want a coarse copy")
+	}
+	if countVertices(boundary.coarse.polygons) >= countVertices(boundary.GetPolygons())/2 {
+		t.Errorf("coarse copy has %d vertices", countVertices(boundary.coarse.polygons))
+	}
+
+	// Random points over the whole box, then points within about 200 m of the border
+	r := rand.New(rand.NewSource(1))
+	points := make([][2]float64, 0, 7000)
+	for i := 0; i < 5000; i++ {
+		points = append(points, [2]float64{12.1 + r.Float64()*0.8, 77.1 + r.Float64()*0.8})
+	}
+	for _, pt := range boundary.GetExteriorRing() {
+		points = append(points, [2]float64{pt[1] + (r.Float64()-0.5)*0.004, pt[0] + (r.Float64()-0.5)*0.004})
+	}
+
+	for _, pt := range points {
+		lat, lng := pt[0], pt[1]
+		if got, want := boundary.ContainsPoint(lat, lng), multiPolygonContains(boundary.GetPolygons(), lat, lng); got != want {
+			t.Fatalf("ContainsPoint(%f, %f) = %v, full polygon says %v", lat, lng, got, want)
+		}
+	}
+}
+
+func TestGetBoundariesForStateAtLOD(t *testing.T) {
+	index := newTestIndex(t)
+
+	full, err := index.GetBoundariesForStateAtLOD("testland", 0)
+	if err != nil {
+		t.Fatalf("GetBoundariesForStateAtLOD(0) error: %v", err)
+	}
+	boundaries, _ := index.GetBoundariesForState("testland")
+	if len(full) != 3 || full[0] != boundaries[0] {
+		t.Error("level 0 should return the indexed boundaries")
+	}
+
+	coarse, err := index.GetBoundariesForStateAtLOD("testland", 3)
+	if err != nil {
+		t.Fatalf("GetBoundariesForStateAtLOD(3) error: %v", err)
+	}
+	if len(coarse) != 3 || coarse[0] == boundaries[0] || !coarse[1].ContainsPoint(12.5, 78.5) {
+		t.Errorf("level 3 = %+v", coarse)
+	}
+	if again, _ := index.GetBoundariesForStateAtLOD("testland", 3); again[0] != coarse[0] {
+		t.Error("level 3 not cached")
+	}
+
+	beta, err := index.GetBoundaryForACAtLOD("testland", 2, 1)
+	if err != nil || beta.ConsName != "Beta" {
+		t.Errorf("GetBoundaryForACAtLOD() = %+v, %v", beta, err)
+	}
+
+	if _, err := index.GetBoundariesForStateAtLOD("testland", 4); !errors.Is(err, ErrInvalidLOD) {
+		t.Errorf("level 4 error = %v, want ErrInvalidLOD", err)
+	}
+
+	if err := index.ReloadState("testland"); err != nil {
+		t.Fatalf("ReloadState() error: %v", err)
+	}
+	if reloaded, _ := index.GetBoundariesForStateAtLOD("testland", 3); reloaded[0] == coarse[0] {
+		t.Error("level 3 cache survived a reload")
+	}
+}
@@ -0,0 +1,460 @@
+package data
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SnapshotVersion is the current snapshot format version. Snapshots written
+// with another version are rejected and must be rebuilt from the source JSON.
+const SnapshotVersion = 2
+
+// snapshotMagic identifies a GeoIndex snapshot file
+var snapshotMagic = [8]byte{'P', 'O', 'L', 'G', 'E', 'O', 'I', 'X'}
+
+// Snapshot errors
+var (
+	ErrSnapshotInvalid = errors.New("invalid snapshot")
+	ErrSnapshotStale   = errors.New("snapshot is older than source data")
+)
+
+// snapshotHeader is the fixed-size header at the start of a snapshot.
+// The gob-encoded payload of PayloadLen bytes follows it.
+type snapshotHeader struct {
+	Magic      [8]byte
+	Version    uint32
+	Source     [32]byte // Fingerprint of the source files the snapshot was built from
+	Checksum   [32]byte // SHA-256 of the payload
+	PayloadLen uint64
+}
+
+// snapshotData is the snapshot payload: every source record loaded into the
+// index. Derived indices are rebuilt from it when the snapshot is read.
+type snapshotData struct {
+	BoothCellResolution int // Resolution of snapshotBooth.Cell
+	States              []State
+	Districts           []District
+	Constituencies      map[string][]AssemblyConstituency      // state name -> ACs
+	PCs                 map[string][]ParliamentaryConstituency // state name -> PCs
+	ACNameAliases       map[string]map[string]string
+	Parties             []Party
+	Lookup              []ConstituencyBoundaryLookup
+	Booths              map[string][]snapshotBooth    // state slug -> booths
+	Boundaries          map[string][]ACBoundary       // state slug -> boundaries, Polygon unset
+	DistrictBoundaries  map[string][]DistrictBoundary // state slug -> district boundaries
+	ACGraph             *snapshotACGraph              // nil if the graph was not built
+	BoundaryExtents     map[string][4]float64         // state slug -> extent, including evicted states
+	BoothExtents        map[string][4]float64         // state slug -> extent, including evicted states
+}
+
+// snapshotBooth stores a booth with explicit coordinates. gob drops pointers
+// to zero values, so the optional Lat/Lon are flattened with a presence flag.
+type snapshotBooth struct {
+	PollingBooth
+	Lat, Lon    float64
+	HasLocation bool
+	Cell        string // H3 cell at snapshotData.BoothCellResolution, saves recomputing it on read
+}
+
+// snapshotACGraph stores the AC adjacency graph as an edge list, since
+// rebuilding it means reading every state's boundaries
+type snapshotACGraph struct {
+	Config ACGraphConfig
+	Nodes  []ACNode
+	Edges  []snapshotACEdge
+}
+
+// snapshotACEdge is an edge between two of snapshotACGraph.Nodes
+type snapshotACEdge struct {
+	From, To       int
+	SharedBorderKm float64
+}
+
+// WriteSnapshot writes everything currently loaded in the index as a binary
+// snapshot. Load booths and boundaries first (see LoadAllStateData) for a
+// snapshot that can serve every state without touching the source JSON.
+func (g *GeoIndex) WriteSnapshot(w io.Writer) error {
+	source, err := sourceFingerprint(g.fsys)
+	if err != nil {
+		return fmt.Errorf("fingerprinting source data: %w", err)
+	}
+
+	g.mu.RLock()
+	data := g.snapshotDataLocked()
+	g.mu.RUnlock()
+
+	var payload bytes.Buffer
+	if err := gob.NewEncoder(&payload).Encode(data); err != nil {
+		return fmt.Errorf("encoding snapshot: %w", err)
+	}
+
+	header := snapshotHeader{
+		Magic:      snapshotMagic,
+		Version:    SnapshotVersion,
+		Source:     source,
+		Checksum:   sha256.Sum256(payload.Bytes()),
+		PayloadLen: uint64(payload.Len()),
+	}
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	_, err = payload.WriteTo(w)
+	return err
+}
+
+// ReadSnapshot replaces the contents of the index with a snapshot. It checks
+// the format version and payload checksum but not whether the source data has
+// changed since the snapshot was written; LoadSnapshot does that.
+func (g *GeoIndex) ReadSnapshot(r io.Reader) error {
+	header, err := readSnapshotHeader(r)
+	if err != nil {
+		return err
+	}
+	return g.readSnapshotPayload(r, header)
+}
+
+// SaveSnapshot writes a snapshot to a file. The file is replaced atomically
+// so concurrent readers never see a partial snapshot.
+func (g *GeoIndex) SaveSnapshot(path string) error {
+	return writeFileAtomic(path, g.WriteSnapshot)
+}
+
+// writeFileAtomic writes a file through a temporary file renamed over it
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadSnapshot replaces the contents of the index with a snapshot file.
+// It returns ErrSnapshotStale if the source data has changed since the
+// snapshot was written.
+func (g *GeoIndex) LoadSnapshot(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", ErrFileNotFound, path)
+		}
+		return err
+	}
+	defer f.Close()
+
+	header, err := readSnapshotHeader(f)
+	if err != nil {
+		return err
+	}
+
+	source, err := sourceFingerprint(g.fsys)
+	if err != nil {
+		return fmt.Errorf("fingerprinting source data: %w", err)
+	}
+	if header.Source != source {
+		return fmt.Errorf("%w: %s", ErrSnapshotStale, path)
+	}
+
+	return g.readSnapshotPayload(f, header)
+}
+
+// LoadAllWithSnapshot loads the index from a snapshot file, rebuilding it
+// from the source JSON when it is missing, stale or unreadable. A rebuild
+// loads every state's booths and boundaries, builds the AC adjacency graph
+// and saves a fresh snapshot. If
+// only saving fails, the index is fully loaded and the save error is returned.
+func (g *GeoIndex) LoadAllWithSnapshot(path string) error {
+	if err := g.LoadSnapshot(path); err == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	g.resetLocked()
+	g.mu.Unlock()
+
+	if err := g.LoadAll(); err != nil {
+		return err
+	}
+	if err := g.LoadAllStateData(); err != nil {
+		return err
+	}
+	if _, err := g.ACGraph(); err != nil {
+		return err
+	}
+
+	if err := g.SaveSnapshot(path); err != nil {
+		return fmt.Errorf("saving snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadAllStateData loads booths, AC boundaries and district boundaries for
+// every state that has them
+func (g *GeoIndex) LoadAllStateData() error {
+	boothDirs, err := ListAvailableStatesFS(g.fsys)
+	if err != nil && !errors.Is(err, ErrDataDirNotFound) {
+		return err
+	}
+	for _, dirName := range boothDirs {
+		if err := g.LoadBoothsForState(NormalizeBoothDirToStateSlug(dirName)); err != nil {
+			return fmt.Errorf("loading booths for %s: %w", dirName, err)
+		}
+	}
+
+	boundaryStates, err := g.availableBoundaryStates()
+	if err != nil && !errors.Is(err, ErrDataDirNotFound) {
+		return err
+	}
+	for _, stateSlug := range boundaryStates {
+		if err := g.LoadBoundariesForState(stateSlug); err != nil {
+			return fmt.Errorf("loading boundaries for %s: %w", stateSlug, err)
+		}
+		if err := g.LoadDistrictBoundariesForState(stateSlug); err != nil && !errors.Is(err, ErrStateNotFound) {
+			return fmt.Errorf("loading district boundaries for %s: %w", stateSlug, err)
+		}
+	}
+
+	return nil
+}
+
+// snapshotDataLocked collects the loaded source records (must hold lock)
+func (g *GeoIndex) snapshotDataLocked() *snapshotData {
+	data := &snapshotData{
+		BoothCellResolution: g.config.BoothCellResolution,
+		Constituencies:      make(map[string][]AssemblyConstituency, len(g.acsByState)),
+		PCs:                 make(map[string][]ParliamentaryConstituency, len(g.pcsByState)),
+		ACNameAliases:       g.acNameAliases,
+		Lookup:              g.constituencyLookup,
+		Booths:              make(map[string][]snapshotBooth, len(g.boothsByState)),
+		Boundaries:          make(map[string][]ACBoundary, len(g.boundariesByState)),
+		DistrictBoundaries:  make(map[string][]DistrictBoundary, len(g.districtBoundariesByState)),
+		BoundaryExtents:     maps.Clone(g.boundaryExtents),
+		BoothExtents:        maps.Clone(g.boothExtents),
+	}
+
+	for _, state := range g.statesByID {
+		data.States = append(data.States, *state)
+	}
+	sort.Slice(data.States, func(i, j int) bool { return data.States[i].ID < data.States[j].ID })
+
+	for _, district := range g.districtsByID {
+		data.Districts = append(data.Districts, *district)
+	}
+	sort.Slice(data.Districts, func(i, j int) bool { return data.Districts[i].ID < data.Districts[j].ID })
+
+	for _, party := range g.partiesByID {
+		data.Parties = append(data.Parties, *party)
+	}
+	sort.Slice(data.Parties, func(i, j int) bool { return data.Parties[i].ID < data.Parties[j].ID })
+
+	for _, acs := range g.acsByState {
+		if len(acs) == 0 {
+			continue
+		}
+		list := make([]AssemblyConstituency, len(acs))
+		for i, ac := range acs {
+			list[i] = *ac
+		}
+		data.Constituencies[acs[0].StateName] = list
+	}
+
+	for _, pcs := range g.pcsByState {
+		if len(pcs) == 0 {
+			continue
+		}
+		list := make([]ParliamentaryConstituency, len(pcs))
+		for i, pc := range pcs {
+			list[i] = *pc
+		}
+		data.PCs[pcs[0].StateName] = list
+	}
+
+	for stateSlug, booths := range g.boothsByState {
+		list := make([]snapshotBooth, len(booths))
+		for i, booth := range booths {
+			list[i].PollingBooth = *booth
+			list[i].PollingBooth.Lat, list[i].PollingBooth.Lon = nil, nil
+			if booth.Lat != nil && booth.Lon != nil {
+				list[i].Lat, list[i].Lon, list[i].HasLocation = *booth.Lat, *booth.Lon, true
+				list[i].Cell, _ = g.boothCell(booth)
+			}
+		}
+		data.Booths[stateSlug] = list
+	}
+
+	for stateSlug, boundaries := range g.boundariesByState {
+		list := make([]ACBoundary, len(boundaries))
+		for i, boundary := range boundaries {
+			list[i] = *boundary
+			if len(list[i].Polygons) > 0 {
+				list[i].Polygon = nil // Restored from Polygons[0] on read
+			}
+		}
+		data.Boundaries[stateSlug] = list
+	}
+
+	for stateSlug, boundaries := range g.districtBoundariesByState {
+		list := make([]DistrictBoundary, len(boundaries))
+		for i, boundary := range boundaries {
+			list[i] = *boundary
+		}
+		data.DistrictBoundaries[stateSlug] = list
+	}
+
+	if g.acGraph != nil {
+		data.ACGraph = g.acGraph.snapshot()
+	}
+
+	return data
+}
+
+// readSnapshotHeader reads and validates a snapshot header
+func readSnapshotHeader(r io.Reader) (snapshotHeader, error) {
+	var header snapshotHeader
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return header, fmt.Errorf("%w: reading header: %v", ErrSnapshotInvalid, err)
+	}
+	if header.Magic != snapshotMagic {
+		return header, fmt.Errorf("%w: not a GeoIndex snapshot", ErrSnapshotInvalid)
+	}
+	if header.Version != SnapshotVersion {
+		return header, fmt.Errorf("%w: version %d, want %d", ErrSnapshotInvalid, header.Version, SnapshotVersion)
+	}
+	return header, nil
+}
+
+// readSnapshotPayload decodes a snapshot payload as it streams in, verifies
+// its checksum and then replaces the contents of the index with it
+func (g *GeoIndex) readSnapshotPayload(r io.Reader, header snapshotHeader) error {
+	hash := sha256.New()
+	payload := io.TeeReader(io.LimitReader(r, int64(header.PayloadLen)), hash)
+
+	var data snapshotData
+	if err := gob.NewDecoder(payload).Decode(&data); err != nil {
+		return fmt.Errorf("%w: decoding payload: %v", ErrSnapshotInvalid, err)
+	}
+	if _, err := io.Copy(io.Discard, payload); err != nil {
+		return err
+	}
+	if !bytes.Equal(hash.Sum(nil), header.Checksum[:]) {
+		return fmt.Errorf("%w: checksum mismatch", ErrSnapshotInvalid)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.applySnapshotLocked(&data)
+	return nil
+}
+
+// applySnapshotLocked replaces every index with the snapshot contents (must hold lock)
+func (g *GeoIndex) applySnapshotLocked(data *snapshotData) {
+	g.resetLocked()
+
+	g.indexStatesLocked(data.States)
+	g.indexDistrictsLocked(data.Districts)
+	g.indexConstituenciesLocked(data.Constituencies)
+	g.indexACNameAliasesLocked(data.ACNameAliases)
+	g.indexParliamentaryConstituenciesLocked(data.PCs)
+	g.indexPartiesLocked(data.Parties)
+	g.indexConstituencyLookupLocked(data.Lookup)
+
+	// Cells are only reusable if the snapshot was written at our resolution
+	reuseCells := data.BoothCellResolution == g.config.BoothCellResolution
+
+	for stateSlug, list := range data.Booths {
+		booths := make([]PollingBooth, len(list))
+		var cells []string
+		if reuseCells {
+			cells = make([]string, len(list))
+		}
+		for i := range list {
+			booths[i] = list[i].PollingBooth
+			if list[i].HasLocation {
+				lat, lon := list[i].Lat, list[i].Lon
+				booths[i].Lat, booths[i].Lon = &lat, &lon
+			}
+			if reuseCells {
+				cells[i] = list[i].Cell
+			}
+		}
+		g.indexBoothsLocked(stateSlug, booths, cells)
+	}
+
+	for stateSlug, boundaries := range data.Boundaries {
+		for i := range boundaries {
+			if len(boundaries[i].Polygons) > 0 {
+				boundaries[i].Polygon = boundaries[i].Polygons[0]
+			}
+		}
+		g.indexBoundariesLocked(stateSlug, boundaries)
+	}
+
+	for stateSlug, boundaries := range data.DistrictBoundaries {
+		g.indexDistrictBoundariesLocked(stateSlug, boundaries)
+	}
+
+	for stateSlug, extent := range data.BoundaryExtents {
+		g.boundaryExtents[stateSlug] = extent
+	}
+	for stateSlug, extent := range data.BoothExtents {
+		g.boothExtents[stateSlug] = extent
+	}
+
+	// The graph is only reusable if it was built with our configuration
+	if data.ACGraph != nil && data.ACGraph.Config == g.config.ACGraph {
+		g.acGraph = data.ACGraph.graph()
+	}
+
+	g.evictLocked()
+}
+
+// sourceFingerprint hashes the path, size and modification time of every
+// JSON and GeoJSON file in the data source
+func sourceFingerprint(fsys fs.FS) ([32]byte, error) {
+	hash := sha256.New()
+	err := fs.WalkDir(fsys, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !(strings.HasSuffix(path, ".json") || strings.HasSuffix(path, ".geojson")) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(hash, "%s\x00%d\x00%d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+
+	var sum [32]byte
+	if err != nil {
+		return sum, err
+	}
+	copy(sum[:], hash.Sum(nil))
+	return sum, nil
+}
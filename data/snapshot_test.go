@@ -0,0 +1,119 @@
+package data
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// loadedStats returns index statistics without the cache counters, which
+// depend on how the data was loaded
+func loadedStats(index *GeoIndex) IndexStats {
+	stats := index.GetStats()
+	stats.CacheHits, stats.CacheMisses, stats.CacheEvictions = 0, 0, 0
+	return stats
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	index := newTestIndex(t)
+	if err := index.LoadAllStateData(); err != nil {
+		t.Fatalf("LoadAllStateData() error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := index.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("WriteSnapshot() error: %v", err)
+	}
+
+	restored := NewGeoIndexFS(index.fsys)
+	if err := restored.ReadSnapshot(&buf); err != nil {
+		t.Fatalf("ReadSnapshot() error: %v", err)
+	}
+
+	if got, want := loadedStats(restored), loadedStats(index); got != want {
+		t.Errorf("GetStats() after snapshot = %+v, want %+v", got, want)
+	}
+
+	boundary, err := restored.FindACAtPoint("testland", 12.5, 78.5)
+	if err != nil || boundary.ConsCode != 2 || len(boundary.Polygon) == 0 {
+		t.Errorf("FindACAtPoint() after snapshot = %v, %v, want AC 2", boundary, err)
+	}
+
+	booths, err := restored.GetBoothsForAC("testland", 1)
+	if err != nil || len(booths) != 1 || booths[0].Lat == nil || *booths[0].Lat != 12.5 {
+		t.Errorf("GetBoothsForAC(1) after snapshot = %v, %v, want located booth", booths, err)
+	}
+	booths, err = restored.GetBoothsForAC("testland", 3)
+	if err != nil || len(booths) != 1 || booths[0].Lat != nil {
+		t.Errorf("GetBoothsForAC(3) after snapshot = %v, %v, want unlocated booth", booths, err)
+	}
+
+	if ac, ok := restored.GetACByConsCode("testland", 3); !ok || !ac.IsReservedST() {
+		t.Errorf("GetACByConsCode(3) after snapshot = %v, %v, want Gamma", ac, ok)
+	}
+	if pc, ok := restored.GetPCForAC("testland", 3); !ok || pc.Name != "East" {
+		t.Errorf("GetPCForAC(3) after snapshot = %v, %v, want East", pc, ok)
+	}
+	if _, err := restored.FindDistrictAtPoint("testland", 12.5, 79.5); err != nil {
+		t.Errorf("FindDistrictAtPoint() after snapshot error: %v", err)
+	}
+}
+
+func TestSnapshotCorrupt(t *testing.T) {
+	index := newTestIndex(t)
+
+	var buf bytes.Buffer
+	if err := index.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("WriteSnapshot() error: %v", err)
+	}
+	snapshot := buf.Bytes()
+	snapshot[len(snapshot)-1] ^= 0xff
+
+	if err := NewGeoIndexFS(index.fsys).ReadSnapshot(bytes.NewReader(snapshot)); !errors.Is(err, ErrSnapshotInvalid) {
+		t.Errorf("ReadSnapshot() corrupt payload error = %v, want ErrSnapshotInvalid", err)
+	}
+	if err := NewGeoIndexFS(index.fsys).ReadSnapshot(bytes.NewReader([]byte("not a snapshot at all"))); !errors.Is(err, ErrSnapshotInvalid) {
+		t.Errorf("ReadSnapshot() garbage error = %v, want ErrSnapshotInvalid", err)
+	}
+}
+
+func TestLoadAllWithSnapshot(t *testing.T) {
+	dataDir := writeTestData(t, testDataFiles())
+	path := filepath.Join(t.TempDir(), "geoindex.snapshot")
+
+	built := NewGeoIndex(dataDir)
+	if err := built.LoadAllWithSnapshot(path); err != nil {
+		t.Fatalf("LoadAllWithSnapshot() build error: %v", err)
+	}
+	if stats := built.GetStats(); stats.BoothsLoaded != 3 || stats.BoundariesLoaded != 3 {
+		t.Errorf("GetStats() after build = %+v, want all booths and boundaries", stats)
+	}
+
+	loaded := NewGeoIndex(dataDir)
+	if err := loaded.LoadSnapshot(path); err != nil {
+		t.Fatalf("LoadSnapshot() error: %v", err)
+	}
+	if got, want := loadedStats(loaded), loadedStats(built); got != want {
+		t.Errorf("GetStats() from snapshot = %+v, want %+v", got, want)
+	}
+
+	// Touching a source file makes the snapshot stale
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dataDir, PartiesFile), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewGeoIndex(dataDir).LoadSnapshot(path); !errors.Is(err, ErrSnapshotStale) {
+		t.Errorf("LoadSnapshot() after source change error = %v, want ErrSnapshotStale", err)
+	}
+
+	rebuilt := NewGeoIndex(dataDir)
+	if err := rebuilt.LoadAllWithSnapshot(path); err != nil {
+		t.Fatalf("LoadAllWithSnapshot() rebuild error: %v", err)
+	}
+	if err := NewGeoIndex(dataDir).LoadSnapshot(path); err != nil {
+		t.Errorf("LoadSnapshot() after rebuild error: %v", err)
+	}
+}
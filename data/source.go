@@ -0,0 +1,53 @@
+package data
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+)
+
+// OpenZipFS opens a zip archive of the data directory as a file system for
+// NewGeoIndexFS. The data may sit at the archive root or inside a single
+// top-level directory. Close the returned io.Closer when done with the index.
+func OpenZipFS(archivePath string) (fs.FS, io.Closer, error) {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fsys, err := dataRoot(reader)
+	if err != nil {
+		reader.Close()
+		return nil, nil, fmt.Errorf("%s: %w", archivePath, err)
+	}
+
+	return fsys, reader, nil
+}
+
+// dataRoot returns the directory of fsys that holds states.json: the root
+// itself or one of its top-level directories
+func dataRoot(fsys fs.FS) (fs.FS, error) {
+	if _, err := fs.Stat(fsys, StatesFile); err == nil {
+		return fsys, nil
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := fs.Stat(fsys, path.Join(entry.Name(), StatesFile)); err == nil {
+			return fs.Sub(fsys, entry.Name())
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("%w: no %s found", ErrDataDirNotFound, StatesFile)
+}
@@ -0,0 +1,83 @@
+package data
+
+import (
+	"archive/zip"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+// testDataFS returns the test data directory as an in-memory file system
+func testDataFS() fstest.MapFS {
+	fsys := make(fstest.MapFS)
+	for name, content := range testDataFiles() {
+		fsys[filepath.ToSlash(name)] = &fstest.MapFile{Data: []byte(content)}
+	}
+	return fsys
+}
+
+func TestGeoIndexFS(t *testing.T) {
+	index := NewGeoIndexFS(testDataFS())
+	if err := index.LoadAll(); err != nil {
+		t.Fatalf("LoadAll() error: %v", err)
+	}
+
+	boundary, err := index.FindACAtPoint("testland", 12.5, 79.5)
+	if err != nil || boundary.ConsName != "Gamma" {
+		t.Errorf("FindACAtPoint() = %v, %v, want Gamma", boundary, err)
+	}
+
+	booths, err := index.GetBoothsForState("testland")
+	if err != nil || len(booths) != 3 {
+		t.Errorf("GetBoothsForState() = %d booths, %v, want 3", len(booths), err)
+	}
+
+	if states, err := ListAvailableStatesFS(testDataFS()); err != nil || len(states) != 1 {
+		t.Errorf("ListAvailableStatesFS() = %v, %v, want [testland]", states, err)
+	}
+}
+
+func TestOpenZipFS(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "data.zip")
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Archive the data under a top-level directory, as `zip -r data.zip data` does
+	w := zip.NewWriter(f)
+	for name, content := range testDataFiles() {
+		entry, err := w.Create(path.Join("data", filepath.ToSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys, closer, err := OpenZipFS(archivePath)
+	if err != nil {
+		t.Fatalf("OpenZipFS() error: %v", err)
+	}
+	defer closer.Close()
+
+	index := NewGeoIndexFS(fsys)
+	if err := index.LoadAll(); err != nil {
+		t.Fatalf("LoadAll() from zip error: %v", err)
+	}
+	if booth, err := index.GetBooth("testland", 1, 1); err != nil || booth.ACName != "Alpha" {
+		t.Errorf("GetBooth() from zip = %v, %v, want Alpha booth", booth, err)
+	}
+	if _, err := index.GetBoundaryForAC("testland", 2); err != nil {
+		t.Errorf("GetBoundaryForAC() from zip error: %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package data
+
+import (
+	"math"
+	"sort"
+)
+
+// rtreeNodeCapacity is the maximum number of entries per R-tree node
+const rtreeNodeCapacity = 16
+
+// spatialIndex is a static R-tree over bounding boxes, bulk loaded with
+// Sort-Tile-Recursive packing. Items are identified by their position in
+// the slice of boxes the index was built from.
+type spatialIndex struct {
+	root  *rtreeNode
+	boxes [][4]float64
+}
+
+// rtreeNode is an R-tree node. Leaves hold item indices, inner nodes hold children.
+type rtreeNode struct {
+	bbox     [4]float64 // [minLng, minLat, maxLng, maxLat]
+	children []*rtreeNode
+	items    []int
+}
+
+// rtreeEntry is an item or node being packed into the next tree level
+type rtreeEntry struct {
+	bbox [4]float64
+	item int
+	node *rtreeNode
+}
+
+// newSpatialIndex builds an R-tree over boxes given as [minLng, minLat, maxLng, maxLat]
+func newSpatialIndex(boxes [][4]float64) *spatialIndex {
+	index := &spatialIndex{boxes: boxes}
+	if len(boxes) == 0 {
+		return index
+	}
+
+	entries := make([]rtreeEntry, len(boxes))
+	for i, bbox := range boxes {
+		entries[i] = rtreeEntry{bbox: bbox, item: i}
+	}
+
+	leaf := true
+	for {
+		nodes := packRTreeLevel(entries, leaf)
+		if len(nodes) == 1 {
+			index.root = nodes[0]
+			return index
+		}
+
+		entries = make([]rtreeEntry, len(nodes))
+		for i, node := range nodes {
+			entries[i] = rtreeEntry{bbox: node.bbox, node: node}
+		}
+		leaf = false
+	}
+}
+
+// packRTreeLevel groups entries into nodes using Sort-Tile-Recursive tiling
+func packRTreeLevel(entries []rtreeEntry, leaf bool) []*rtreeNode {
+	nodeCount := (len(entries) + rtreeNodeCapacity - 1) / rtreeNodeCapacity
+	sliceCount := int(math.Ceil(math.Sqrt(float64(nodeCount))))
+	sliceSize := sliceCount * rtreeNodeCapacity
+
+	// Sort by longitude centre, then tile each vertical slice by latitude centre
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].bbox[0]+entries[i].bbox[2] < entries[j].bbox[0]+entries[j].bbox[2]
+	})
+
+	nodes := make([]*rtreeNode, 0, nodeCount)
+	for start := 0; start < len(entries); start += sliceSize {
+		end := min(start+sliceSize, len(entries))
+		slice := entries[start:end]
+		sort.SliceStable(slice, func(i, j int) bool {
+			return slice[i].bbox[1]+slice[i].bbox[3] < slice[j].bbox[1]+slice[j].bbox[3]
+		})
+
+		for nodeStart := 0; nodeStart < len(slice); nodeStart += rtreeNodeCapacity {
+			nodeEnd := min(nodeStart+rtreeNodeCapacity, len(slice))
+			node := &rtreeNode{bbox: slice[nodeStart].bbox}
+			for _, entry := range slice[nodeStart:nodeEnd] {
+				node.bbox = unionBBox(node.bbox, entry.bbox)
+				if leaf {
+					node.items = append(node.items, entry.item)
+				} else {
+					node.children = append(node.children, entry.node)
+				}
+			}
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes
+}
+
+// Bounds returns the bounding box of everything in the index
+func (s *spatialIndex) Bounds() ([4]float64, bool) {
+	if s == nil || s.root == nil {
+		return [4]float64{}, false
+	}
+	return s.root.bbox, true
+}
+
+// SearchPoint returns, in ascending order, the items whose box contains the point
+func (s *spatialIndex) SearchPoint(lat, lng float64) []int {
+	return s.SearchBBox([4]float64{lng, lat, lng, lat})
+}
+
+// SearchBBox returns, in ascending order, the items whose box intersects bbox
+func (s *spatialIndex) SearchBBox(bbox [4]float64) []int {
+	if s == nil || s.root == nil {
+		return nil
+	}
+
+	var result []int
+	stack := []*rtreeNode{s.root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if !bboxIntersects(node.bbox, bbox) {
+			continue
+		}
+		if node.items != nil {
+			for _, item := range node.items {
+				if bboxIntersects(s.boxes[item], bbox) {
+					result = append(result, item)
+				}
+			}
+			continue
+		}
+		stack = append(stack, node.children...)
+	}
+
+	// Return items in their original order so callers keep first-match semantics
+	sort.Ints(result)
+	return result
+}
+
+// unionBBox returns the smallest box containing both boxes
+func unionBBox(a, b [4]float64) [4]float64 {
+	return [4]float64{
+		math.Min(a[0], b[0]),
+		math.Min(a[1], b[1]),
+		math.Max(a[2], b[2]),
+		math.Max(a[3], b[3]),
+	}
+}
+
+// bboxIntersects checks if two [minLng, minLat, maxLng, maxLat] boxes overlap
+func bboxIntersects(a, b [4]float64) bool {
+	return a[0] <= b[2] && b[0] <= a[2] && a[1] <= b[3] && b[1] <= a[3]
+}
+
+// bboxContainsPoint checks if a point lies inside a [minLng, minLat, maxLng, maxLat] box
+func bboxContainsPoint(bbox [4]float64, lat, lng float64) bool {
+	return lng >= bbox[0] && lng <= bbox[2] && lat >= bbox[1] && lat <= bbox[3]
+}
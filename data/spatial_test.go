@@ -0,0 +1,91 @@
+package data
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSpatialIndexMatchesLinearScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+
+	boxes := make([][4]float64, 500)
+	for i := range boxes {
+		lng := 68 + rng.Float64()*29
+		lat := 8 + rng.Float64()*29
+		boxes[i] = [4]float64{lng, lat, lng + rng.Float64(), lat + rng.Float64()}
+	}
+	index := newSpatialIndex(boxes)
+
+	for n := 0; n < 1000; n++ {
+		lat := 8 + rng.Float64()*30
+		lng := 68 + rng.Float64()*30
+
+		var expected []int
+		for i, bbox := range boxes {
+			if bboxContainsPoint(bbox, lat, lng) {
+				expected = append(expected, i)
+			}
+		}
+
+		got := index.SearchPoint(lat, lng)
+		if len(got) != len(expected) {
+			t.Fatalf("SearchPoint(%.4f, %.4f) len = %d, want %d", lat, lng, len(got), len(expected))
+		}
+		for i := range got {
+			if got[i] != expected[i] {
+				t.Fatalf("SearchPoint(%.4f, %.4f) = %v, want %v", lat, lng, got, expected)
+			}
+		}
+	}
+}
+
+func TestSpatialIndexEmpty(t *testing.T) {
+	index := newSpatialIndex(nil)
+	if got := index.SearchPoint(12.0, 77.0); len(got) != 0 {
+		t.Errorf("SearchPoint() on empty index = %v, want empty", got)
+	}
+	if _, ok := index.Bounds(); ok {
+		t.Error("Bounds() on empty index ok = true, want false")
+	}
+}
+
+func TestFindACAtPointMatchesRayCasting(t *testing.T) {
+	index := NewGeoIndex(".")
+	boundaries, err := LoadBoundariesForState(".", "goa")
+	if err != nil {
+		t.Skipf("goa boundaries not available: %v", err)
+	}
+
+	rng := rand.New(rand.NewSource(7))
+	bbox := boundaries[0].BoundingBox()
+	for _, b := range boundaries[1:] {
+		bbox = unionBBox(bbox, b.BoundingBox())
+	}
+
+	for n := 0; n < 200; n++ {
+		lng := bbox[0] + rng.Float64()*(bbox[2]-bbox[0])
+		lat := bbox[1] + rng.Float64()*(bbox[3]-bbox[1])
+
+		var expected *ACBoundary
+		for i := range boundaries {
+			if boundaries[i].ContainsPoint(lat, lng) {
+				expected = &boundaries[i]
+				break
+			}
+		}
+
+		got, err := index.FindACAtPoint("goa", lat, lng)
+		if expected == nil {
+			if err == nil {
+				t.Errorf("FindACAtPoint(%.5f, %.5f) = %d, want not found", lat, lng, got.ConsCode)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("FindACAtPoint(%.5f, %.5f) error: %v", lat, lng, err)
+		}
+		if got.ConsCode != expected.ConsCode {
+			t.Errorf("FindACAtPoint(%.5f, %.5f) = %d, want %d", lat, lng, got.ConsCode, expected.ConsCode)
+		}
+	}
+}
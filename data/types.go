@@ -4,6 +4,7 @@ package data
 
 import (
 	"fmt"
+	"math"
 	"strings"
 )
 
@@ -60,6 +61,7 @@ type AssemblyConstituency struct {
 	StateName string `json:"-"`
 	StateSlug string `json:"-"`
 	ACNumber  int    `json:"-"` // Parsed from ID
+	Edition   string `json:"-"` // Name of the edition the AC belongs to, see Edition
 }
 
 // IsReserved returns true if the constituency is reserved (SC/ST)
@@ -84,6 +86,32 @@ type StateConstituencies struct {
 	Constituencies []AssemblyConstituency `json:"constituencies"`
 }
 
+// ParliamentaryConstituency represents a Lok Sabha Parliamentary Constituency (PC).
+// Each PC is made up of Assembly Constituency segments within one state.
+type ParliamentaryConstituency struct {
+	ID        string `json:"id"`        // "pc_1", "pc_2"
+	Name      string `json:"name"`      // "Srikakulam"
+	Reserved  string `json:"reserved"`  // "None", "SC", "ST"
+	ACNumbers []int  `json:"acNumbers"` // Assembly segments, numbered as in booth and boundary files
+
+	// Derived fields (populated during loading)
+	StateName string `json:"-"`
+	StateSlug string `json:"-"`
+	PCNumber  int    `json:"-"` // Parsed from ID
+}
+
+// IsReserved returns true if the constituency is reserved (SC/ST)
+func (pc ParliamentaryConstituency) IsReserved() bool {
+	return pc.Reserved != "" && pc.Reserved != "None"
+}
+
+// StateParliamentaryConstituencies represents parliamentary constituencies for a state
+type StateParliamentaryConstituencies struct {
+	Name           string                      `json:"name"`
+	TotalSeats     int                         `json:"totalSeats"`
+	Constituencies []ParliamentaryConstituency `json:"constituencies"`
+}
+
 // PollingBooth represents a polling station/booth
 type PollingBooth struct {
 	PartID       int      `json:"partId"`
@@ -97,6 +125,9 @@ type PollingBooth struct {
 	PartName     string   `json:"partName"`     // "Govt Higher Primary School, Gunjuru"
 	Lat          *float64 `json:"lat,omitempty"`
 	Lon          *float64 `json:"lon,omitempty"`
+
+	// Derived fields (populated during loading)
+	Edition string `json:"-"` // Name of the edition the booth belongs to, see Edition
 }
 
 // FullName returns the full booth name including part number
@@ -104,17 +135,39 @@ func (b PollingBooth) FullName() string {
 	return fmt.Sprintf("%d - %s", b.PartNumber, b.PartName)
 }
 
-// ACBoundary represents a GeoJSON polygon for an Assembly Constituency
+// ACBoundary represents a GeoJSON polygon for an Assembly Constituency.
+// Constituencies with islands or exclaves are stored as several polygons;
+// Polygon always holds the first part and Polygons holds every part.
 type ACBoundary struct {
-	ObjectID int           `json:"objectid"`
-	UID      string        `json:"uid"`
-	StateUT  string        `json:"state_ut"`
-	ConsCode int           `json:"cons_code"`
-	ConsName string        `json:"cons_name"`
-	Polygon  [][][]float64 `json:"-"` // [ring][point][lng,lat]
+	ObjectID int             `json:"objectid"`
+	UID      string          `json:"uid"`
+	StateUT  string          `json:"state_ut"`
+	ConsCode int             `json:"cons_code"`
+	ConsName string          `json:"cons_name"`
+	Polygon  [][][]float64   `json:"-"` // [ring][point][lng,lat]
+	Polygons [][][][]float64 `json:"-"` // [polygon][ring][point][lng,lat]
+	Edition  string          `json:"-"` // Name of the edition the boundary belongs to, see Edition
+
+	coarse *coarseBoundary // Simplified copy for ContainsPoint, set when indexed
+}
+
+// GetPolygons returns every polygon of the boundary, each with its holes
+func (b ACBoundary) GetPolygons() [][][][]float64 {
+	if len(b.Polygons) > 0 {
+		return b.Polygons
+	}
+	if len(b.Polygon) == 0 {
+		return nil
+	}
+	return [][][][]float64{b.Polygon}
+}
+
+// IsMultiPolygon returns true if the boundary has more than one part
+func (b ACBoundary) IsMultiPolygon() bool {
+	return len(b.GetPolygons()) > 1
 }
 
-// GetExteriorRing returns the exterior ring of the polygon
+// GetExteriorRing returns the exterior ring of the first polygon
 func (b ACBoundary) GetExteriorRing() [][]float64 {
 	if len(b.Polygon) == 0 {
 		return nil
@@ -122,7 +175,7 @@ func (b ACBoundary) GetExteriorRing() [][]float64 {
 	return b.Polygon[0]
 }
 
-// GetHoles returns the holes (interior rings) of the polygon
+// GetHoles returns the holes (interior rings) of the first polygon
 func (b ACBoundary) GetHoles() [][][]float64 {
 	if len(b.Polygon) <= 1 {
 		return nil
@@ -130,58 +183,142 @@ func (b ACBoundary) GetHoles() [][][]float64 {
 	return b.Polygon[1:]
 }
 
-// BoundingBox returns [minLng, minLat, maxLng, maxLat]
-func (b ACBoundary) BoundingBox() [4]float64 {
-	ring := b.GetExteriorRing()
-	if len(ring) == 0 {
-		return [4]float64{}
-	}
-
-	minLng, minLat := ring[0][0], ring[0][1]
-	maxLng, maxLat := ring[0][0], ring[0][1]
-
-	for _, pt := range ring {
-		if pt[0] < minLng {
-			minLng = pt[0]
-		}
-		if pt[0] > maxLng {
-			maxLng = pt[0]
-		}
-		if pt[1] < minLat {
-			minLat = pt[1]
-		}
-		if pt[1] > maxLat {
-			maxLat = pt[1]
+// GetExteriorRings returns the exterior ring of every polygon
+func (b ACBoundary) GetExteriorRings() [][][]float64 {
+	polygons := b.GetPolygons()
+	rings := make([][][]float64, 0, len(polygons))
+	for _, polygon := range polygons {
+		if len(polygon) > 0 {
+			rings = append(rings, polygon[0])
 		}
 	}
+	return rings
+}
 
-	return [4]float64{minLng, minLat, maxLng, maxLat}
+// BoundingBox returns [minLng, minLat, maxLng, maxLat] across all polygons
+func (b ACBoundary) BoundingBox() [4]float64 {
+	return multiPolygonBoundingBox(b.GetPolygons())
 }
 
-// ContainsPoint checks if a point is inside the boundary using ray casting
+// ContainsPoint checks if a point is inside any polygon of the boundary using ray casting
 func (b ACBoundary) ContainsPoint(lat, lng float64) bool {
-	ring := b.GetExteriorRing()
-	if len(ring) == 0 {
+	polygons := b.GetPolygons()
+	if len(polygons) == 0 {
 		return false
 	}
 
+	// Indexed boundaries decide points away from the border from a coarse copy
+	if b.coarse != nil {
+		if inside, ok := b.coarse.contains(lat, lng); ok {
+			return inside
+		}
+		return multiPolygonContains(polygons, lat, lng)
+	}
+
 	// Check bounding box first (fast rejection)
 	bbox := b.BoundingBox()
 	if lng < bbox[0] || lng > bbox[2] || lat < bbox[1] || lat > bbox[3] {
 		return false
 	}
 
-	// Ray casting algorithm
-	inside := pointInRing(lat, lng, ring)
+	return multiPolygonContains(polygons, lat, lng)
+}
+
+// DistanceToBoundary returns the distance in metres from a point to the
+// nearest edge of the boundary, whether the point is inside or outside it
+func (b ACBoundary) DistanceToBoundary(lat, lng float64) float64 {
+	return multiPolygonEdgeDistance(b.GetPolygons(), lat, lng)
+}
+
+// multiPolygonEdgeDistance returns the distance in metres from a point to the
+// nearest ring edge, or +Inf if there are no edges. Edges are projected onto a
+// plane around the point, which is accurate to well under a metre for edges
+// within a few kilometres.
+func multiPolygonEdgeDistance(polygons [][][][]float64, lat, lng float64) float64 {
+	const metresPerDegree = 111320.0
+	kx := metresPerDegree * math.Cos(lat*math.Pi/180)
+
+	best := math.Inf(1)
+	for _, polygon := range polygons {
+		for _, ring := range polygon {
+			for i := 1; i < len(ring); i++ {
+				ax, ay := (ring[i-1][0]-lng)*kx, (ring[i-1][1]-lat)*metresPerDegree
+				bx, by := (ring[i][0]-lng)*kx, (ring[i][1]-lat)*metresPerDegree
+				best = math.Min(best, originSegmentDistance(ax, ay, bx, by))
+			}
+		}
+	}
+
+	return best
+}
+
+// originSegmentDistance returns the distance from the origin to segment ab
+func originSegmentDistance(ax, ay, bx, by float64) float64 {
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if lengthSq := dx*dx + dy*dy; lengthSq > 0 {
+		t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/lengthSq))
+	}
+	return math.Hypot(ax+t*dx, ay+t*dy)
+}
+
+// multiPolygonBoundingBox returns [minLng, minLat, maxLng, maxLat] of all exterior rings
+func multiPolygonBoundingBox(polygons [][][][]float64) [4]float64 {
+	var bbox [4]float64
+	found := false
 
-	// Check holes - if point is in a hole, it's outside the polygon
-	for _, hole := range b.GetHoles() {
+	for _, polygon := range polygons {
+		if len(polygon) == 0 {
+			continue
+		}
+		for _, pt := range polygon[0] {
+			if !found {
+				bbox = [4]float64{pt[0], pt[1], pt[0], pt[1]}
+				found = true
+				continue
+			}
+			if pt[0] < bbox[0] {
+				bbox[0] = pt[0]
+			}
+			if pt[0] > bbox[2] {
+				bbox[2] = pt[0]
+			}
+			if pt[1] < bbox[1] {
+				bbox[1] = pt[1]
+			}
+			if pt[1] > bbox[3] {
+				bbox[3] = pt[1]
+			}
+		}
+	}
+
+	return bbox
+}
+
+// multiPolygonContains checks if a point is inside any of the polygons
+func multiPolygonContains(polygons [][][][]float64, lat, lng float64) bool {
+	for _, polygon := range polygons {
+		if polygonContains(polygon, lat, lng) {
+			return true
+		}
+	}
+	return false
+}
+
+// polygonContains checks if a point is inside a polygon's exterior ring and outside its holes
+func polygonContains(polygon [][][]float64, lat, lng float64) bool {
+	if len(polygon) == 0 || !pointInRing(lat, lng, polygon[0]) {
+		return false
+	}
+
+	// If point is in a hole, it's outside the polygon
+	for _, hole := range polygon[1:] {
 		if pointInRing(lat, lng, hole) {
-			inside = !inside
+			return false
 		}
 	}
 
-	return inside
+	return true
 }
 
 // pointInRing uses ray casting to check if point is inside ring
@@ -204,6 +341,38 @@ func pointInRing(lat, lng float64, ring [][]float64) bool {
 	return inside
 }
 
+// DistrictBoundary represents a GeoJSON polygon for a district
+type DistrictBoundary struct {
+	DistCode int             `json:"dist_code"`
+	DistName string          `json:"dist_name"`
+	StateUT  string          `json:"state_ut"`
+	Polygons [][][][]float64 `json:"-"` // [polygon][ring][point][lng,lat]
+}
+
+// Slug returns the URL-friendly slug for the district name
+func (b DistrictBoundary) Slug() string {
+	return ToSlug(b.DistName)
+}
+
+// BoundingBox returns [minLng, minLat, maxLng, maxLat] across all polygons
+func (b DistrictBoundary) BoundingBox() [4]float64 {
+	return multiPolygonBoundingBox(b.Polygons)
+}
+
+// ContainsPoint checks if a point is inside any polygon of the district
+func (b DistrictBoundary) ContainsPoint(lat, lng float64) bool {
+	if len(b.Polygons) == 0 {
+		return false
+	}
+
+	bbox := b.BoundingBox()
+	if lng < bbox[0] || lng > bbox[2] || lat < bbox[1] || lat > bbox[3] {
+		return false
+	}
+
+	return multiPolygonContains(b.Polygons, lat, lng)
+}
+
 // Party represents a political party
 type Party struct {
 	ID           int      `json:"id"`
@@ -217,13 +386,19 @@ type Party struct {
 	Website      string   `json:"website,omitempty"`
 }
 
-// ConstituencyBoundaryLookup represents a lookup entry for coordinate-to-AC mapping
+// ConstituencyBoundaryLookup links an AC in assembly_constituency.json to its
+// feature in the state's boundary GeoJSON
 type ConstituencyBoundaryLookup struct {
-	StateName string  `json:"state_name"`
-	ACCode    int     `json:"ac_code"`
-	ACName    string  `json:"ac_name"`
-	CenterLat float64 `json:"center_lat"`
-	CenterLng float64 `json:"center_lng"`
+	ACID         string `json:"-"`                   // "ac_106" - key in the lookup file
+	StateFile    string `json:"state_file"`          // "andhra_pradesh.geojson"
+	FeatureIndex int    `json:"state_feature_index"` // Position of the feature in StateFile
+	ACCode       int    `json:"cons_code"`           // cons_code of the boundary feature
+	ACName       string `json:"cons_name"`           // cons_name of the boundary feature
+	UID          string `json:"uid"`
+	MatchType    string `json:"match_type"` // "exact", "normalized", "fuzzy", "manual", ...
+
+	// Derived fields (populated during loading)
+	StateSlug string `json:"-"`
 }
 
 // ToSlug converts a name to a URL-friendly slug
@@ -264,13 +439,28 @@ func NormalizeBoothDirToStateSlug(dirName string) string {
 	return dirName
 }
 
+// boothDirForStateSlug converts a state slug to the booth directory name that
+// holds its booths. It is the inverse of NormalizeBoothDirToStateSlug.
+func boothDirForStateSlug(stateSlug string) string {
+	for dirName, slug := range boothDirToStateSlug {
+		if slug == stateSlug {
+			return dirName
+		}
+	}
+	return FromSlug(stateSlug)
+}
+
 // File names
 const (
 	StatesFile                     = "states.json"
 	DistrictsFile                  = "districts.json"
 	AssemblyConstituenciesFile     = "assembly_constituency.json"
+	ParliamentaryConstituencyFile  = "parliamentary_constituency.json"
 	PartiesFile                    = "parties.json"
+	ACNameAliasesFile              = "ac_name_aliases.json"
 	ConstituencyBoundaryLookupFile = "constituency_boundary_lookup.json"
+	EditionsFile                   = "editions.json"
 	BoothsDir                      = "booths"
 	BoundariesDir                  = "boundaries"
+	DistrictBoundariesDir          = "districts" // inside BoundariesDir
 )
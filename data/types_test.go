@@ -1,7 +1,10 @@
 package data
 
 import (
+	"math"
 	"testing"
+
+	h3utils "github.com/politic-in/core/h3-utils"
 )
 
 func TestToSlug(t *testing.T) {
@@ -244,6 +247,41 @@ func TestACBoundaryContainsPointWithHole(t *testing.T) {
 	}
 }
 
+func TestACBoundaryDistanceToBoundary(t *testing.T) {
+	// Square 77-78 longitude, 12-13 latitude with a hole 77.3-77.7, 12.3-12.7
+	boundary := ACBoundary{
+		Polygon: [][][]float64{
+			{{77.0, 12.0}, {78.0, 12.0}, {78.0, 13.0}, {77.0, 13.0}, {77.0, 12.0}},
+			{{77.3, 12.3}, {77.7, 12.3}, {77.7, 12.7}, {77.3, 12.7}, {77.3, 12.3}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		lat, lng float64
+		want     float64 // Haversine distance to the nearest edge point
+	}{
+		{"inside_near_east_edge", 12.5, 77.999, h3utils.HaversineDistance(12.5, 77.999, 12.5, 78.0)},
+		{"outside_east", 12.5, 78.001, h3utils.HaversineDistance(12.5, 78.001, 12.5, 78.0)},
+		{"beyond_corner", 11.99, 76.99, h3utils.HaversineDistance(11.99, 76.99, 12.0, 77.0)},
+		{"in_hole", 12.5, 77.5, h3utils.HaversineDistance(12.5, 77.5, 12.5, 77.3)},
+		{"on_edge", 12.0, 77.5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := boundary.DistanceToBoundary(tt.lat, tt.lng)
+			if math.Abs(got-tt.want) > 0.005*tt.want+0.01 {
+				t.Errorf("DistanceToBoundary(%.3f, %.3f) = %.2f, want %.2f", tt.lat, tt.lng, got, tt.want)
+			}
+		})
+	}
+
+	if got := (ACBoundary{}).DistanceToBoundary(12.5, 77.5); !math.IsInf(got, 1) {
+		t.Errorf("DistanceToBoundary() for empty = %v, want +Inf", got)
+	}
+}
+
 func TestPointInRing(t *testing.T) {
 	// Triangle
 	ring := [][]float64{
@@ -269,3 +307,68 @@ func TestPointInRing(t *testing.T) {
 		}
 	}
 }
+
+func TestACBoundaryMultiPolygon(t *testing.T) {
+	// Mainland square plus an island to the east with a lagoon (hole)
+	mainland := [][][]float64{
+		{{77.0, 12.0}, {78.0, 12.0}, {78.0, 13.0}, {77.0, 13.0}, {77.0, 12.0}},
+	}
+	island := [][][]float64{
+		{{79.0, 12.0}, {79.5, 12.0}, {79.5, 12.5}, {79.0, 12.5}, {79.0, 12.0}},
+		{{79.2, 12.2}, {79.3, 12.2}, {79.3, 12.3}, {79.2, 12.3}, {79.2, 12.2}},
+	}
+
+	boundary := ACBoundary{
+		Polygon:  mainland,
+		Polygons: [][][][]float64{mainland, island},
+	}
+
+	if !boundary.IsMultiPolygon() {
+		t.Error("IsMultiPolygon() = false, want true")
+	}
+
+	if rings := boundary.GetExteriorRings(); len(rings) != 2 {
+		t.Errorf("GetExteriorRings() len = %d, want %d", len(rings), 2)
+	}
+
+	bbox := boundary.BoundingBox()
+	expected := [4]float64{77.0, 12.0, 79.5, 13.0}
+	if bbox != expected {
+		t.Errorf("BoundingBox() = %v, want %v", bbox, expected)
+	}
+
+	tests := []struct {
+		name     string
+		lat, lng float64
+		inside   bool
+	}{
+		{"mainland", 12.5, 77.5, true},
+		{"island", 12.1, 79.1, true},
+		{"island_lagoon", 12.25, 79.25, false},
+		{"sea_between", 12.5, 78.5, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := boundary.ContainsPoint(tt.lat, tt.lng)
+			if result != tt.inside {
+				t.Errorf("ContainsPoint(%.2f, %.2f) = %v, want %v", tt.lat, tt.lng, result, tt.inside)
+			}
+		})
+	}
+}
+
+func TestACBoundaryGetPolygonsFallback(t *testing.T) {
+	ring := [][]float64{{77.0, 12.0}, {78.0, 12.0}, {78.0, 13.0}, {77.0, 12.0}}
+	boundary := ACBoundary{Polygon: [][][]float64{ring}}
+
+	if polygons := boundary.GetPolygons(); len(polygons) != 1 {
+		t.Errorf("GetPolygons() len = %d, want %d", len(polygons), 1)
+	}
+	if boundary.IsMultiPolygon() {
+		t.Error("IsMultiPolygon() = true, want false")
+	}
+	if polygons := (ACBoundary{}).GetPolygons(); polygons != nil {
+		t.Errorf("GetPolygons() for empty = %v, want nil", polygons)
+	}
+}
@@ -0,0 +1,558 @@
+package h3utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/uber/h3-go/v4"
+)
+
+// Geofence errors
+var (
+	ErrInvalidGeofence   = errors.New("invalid geofence")
+	ErrDuplicateGeofence = errors.New("duplicate geofence ID")
+)
+
+// Geofence is an area such as a campaign area, ward or poll target zone,
+// stored as a compacted set of H3 cells that may mix resolutions
+type Geofence struct {
+	ID         string
+	Name       string
+	Properties map[string]any
+
+	cells       map[h3.Cell]bool
+	resolutions []int // Resolutions present in cells, finest first
+}
+
+// NewGeofence creates a geofence from cells at any resolutions. Cells are
+// compacted: complete sets of children are replaced by their parent, and
+// cells inside another cell are dropped.
+func NewGeofence(id, name string, cellIDs []string) (*Geofence, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: missing ID", ErrInvalidGeofence)
+	}
+
+	cells := make([]h3.Cell, len(cellIDs))
+	for i, cellID := range cellIDs {
+		cell, err := cellFromString(cellID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCellID, cellID)
+		}
+		cells[i] = cell
+	}
+
+	f := &Geofence{ID: id, Name: name, cells: compactMixed(cells)}
+	seen := make(map[int]bool)
+	for cell := range f.cells {
+		if res := cell.Resolution(); !seen[res] {
+			seen[res] = true
+			f.resolutions = append(f.resolutions, res)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(f.resolutions)))
+	return f, nil
+}
+
+// compactMixed compacts cells of any resolutions, which h3.CompactCells does not accept
+func compactMixed(cells []h3.Cell) map[h3.Cell]bool {
+	set := make(map[h3.Cell]bool, len(cells))
+	for _, cell := range cells {
+		set[cell] = true
+	}
+
+	// Drop cells already covered by an ancestor
+	for cell := range set {
+		for res := cell.Resolution() - 1; res >= MinResolution; res-- {
+			if set[cell.Parent(res)] {
+				delete(set, cell)
+				break
+			}
+		}
+	}
+
+	// Replace complete sets of children with their parent, finest first, so
+	// parents can complete sets of their own
+	for res := MaxResolution; res > MinResolution; res-- {
+		children := make(map[h3.Cell]int)
+		for cell := range set {
+			if cell.Resolution() == res {
+				children[cell.Parent(res-1)]++
+			}
+		}
+		for parent, count := range children {
+			complete := 7
+			if parent.IsPentagon() {
+				complete = 6
+			}
+			if count < complete {
+				continue
+			}
+			for _, child := range parent.Children(res) {
+				delete(set, child)
+			}
+			set[parent] = true
+		}
+	}
+	return set
+}
+
+// Len returns the number of compacted cells
+func (f *Geofence) Len() int {
+	return len(f.cells)
+}
+
+// Cells returns the compacted cells, sorted
+func (f *Geofence) Cells() []string {
+	return sortedCellStrings(f.cells)
+}
+
+// FinestResolution returns the resolution of the geofence's smallest cells,
+// or -1 for an empty geofence
+func (f *Geofence) FinestResolution() int {
+	if len(f.resolutions) == 0 {
+		return -1
+	}
+	return f.resolutions[0]
+}
+
+// UncompactCells returns the geofence's cells at a resolution no coarser than its finest
+func (f *Geofence) UncompactCells(resolution int) ([]string, error) {
+	if resolution < f.FinestResolution() || resolution > MaxResolution {
+		return nil, ErrInvalidResolution
+	}
+	var result []string
+	for cell := range f.cells {
+		for _, child := range cell.Children(resolution) {
+			result = append(result, child.String())
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// Contains reports whether a point is in the geofence: whether its cell at
+// the geofence's finest resolution is one of the cells or lies within one
+func (f *Geofence) Contains(lat, lng float64) bool {
+	if len(f.resolutions) == 0 {
+		return false
+	}
+	return f.containsCell(h3.LatLngToCell(h3.NewLatLng(lat, lng), f.resolutions[0]))
+}
+
+// ContainsCell reports whether a cell at any resolution lies entirely in the
+// geofence. A cell coarser than the geofence's cells is contained only when
+// all of its children are.
+func (f *Geofence) ContainsCell(cellID string) bool {
+	cell, err := cellFromString(cellID)
+	if err != nil {
+		return false
+	}
+	return f.containsCell(cell)
+}
+
+// containsCell reports whether a cell or one of its ancestors is a geofence cell.
+// Compaction means a cell covered by finer geofence cells is one itself.
+func (f *Geofence) containsCell(cell h3.Cell) bool {
+	res := cell.Resolution()
+	for _, r := range f.resolutions {
+		switch {
+		case r > res:
+			continue
+		case r == res:
+			if f.cells[cell] {
+				return true
+			}
+		default:
+			if f.cells[cell.Parent(r)] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// geofenceJSON is the JSON form of a Geofence
+type geofenceJSON struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name,omitempty"`
+	Properties map[string]any `json:"properties,omitempty"`
+	Cells      []string       `json:"cells"`
+}
+
+// MarshalJSON encodes the geofence with its compacted cells
+func (f *Geofence) MarshalJSON() ([]byte, error) {
+	return json.Marshal(geofenceJSON{ID: f.ID, Name: f.Name, Properties: f.Properties, Cells: f.Cells()})
+}
+
+// UnmarshalJSON decodes a geofence written by MarshalJSON. The cells need
+// not be compacted.
+func (f *Geofence) UnmarshalJSON(data []byte) error {
+	var raw geofenceJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	decoded, err := NewGeofence(raw.ID, raw.Name, raw.Cells)
+	if err != nil {
+		return err
+	}
+	decoded.Properties = raw.Properties
+	*f = *decoded
+	return nil
+}
+
+// geofenceFeature is a GeoJSON Feature holding a geofence
+type geofenceFeature struct {
+	Type       string         `json:"type"`
+	ID         string         `json:"id,omitempty"`
+	Properties map[string]any `json:"properties"`
+	Geometry   struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	} `json:"geometry"`
+}
+
+// maxOutlineCells caps the cells MarshalGeoJSON outlines, so a fence mixing
+// district-sized and booth-sized cells is not outlined as millions of cells
+const maxOutlineCells = 100_000
+
+// MarshalGeoJSON encodes the geofence as a GeoJSON Feature with a
+// MultiPolygon outline of its cells uncompacted to the finest resolution, so
+// a point is inside the outline exactly when Contains reports it. If that
+// takes more than maxOutlineCells cells, the finest coarser resolution that
+// fits is outlined instead: finer cells are replaced by their ancestor at
+// that resolution, so the outline then reaches up to one cell of that
+// resolution beyond the geofence, and disagrees with Contains only within
+// that distance of the outline. The compacted cells are kept in the
+// "h3_cells" property, so ParseGeofenceGeoJSON restores the geofence exactly.
+func (f *Geofence) MarshalGeoJSON() ([]byte, error) {
+	var cells []h3.Cell
+	if len(f.resolutions) > 0 {
+		res := f.outlineResolution()
+		seen := make(map[h3.Cell]bool)
+		for cell := range f.cells {
+			if cell.Resolution() > res {
+				if parent := cell.Parent(res); !seen[parent] {
+					seen[parent] = true
+					cells = append(cells, parent)
+				}
+				continue
+			}
+			cells = append(cells, cell.Children(res)...)
+		}
+		sort.Slice(cells, func(i, j int) bool { return cells[i] < cells[j] })
+	}
+
+	// [polygon][ring][point][lng, lat], rings closed as GeoJSON requires
+	outline := [][][][2]float64{}
+	for _, polygon := range h3.CellsToMultiPolygon(cells) {
+		rings := [][][2]float64{geoJSONRing(polygon.GeoLoop)}
+		for _, hole := range polygon.Holes {
+			rings = append(rings, geoJSONRing(hole))
+		}
+		outline = append(outline, rings)
+	}
+	coordinates, err := json.Marshal(outline)
+	if err != nil {
+		return nil, err
+	}
+
+	feature := geofenceFeature{Type: "Feature", ID: f.ID, Properties: make(map[string]any, len(f.Properties)+3)}
+	for k, v := range f.Properties {
+		feature.Properties[k] = v
+	}
+	feature.Properties["id"] = f.ID
+	if f.Name != "" {
+		feature.Properties["name"] = f.Name
+	}
+	feature.Properties["h3_cells"] = f.Cells()
+	feature.Geometry.Type = "MultiPolygon"
+	feature.Geometry.Coordinates = coordinates
+	return json.Marshal(feature)
+}
+
+// outlineResolution returns the finest resolution at which the geofence's
+// cells, uncompacted or replaced by their ancestors, number at most
+// maxOutlineCells
+func (f *Geofence) outlineResolution() int {
+	counts := make(map[int]float64, len(f.resolutions))
+	for cell := range f.cells {
+		counts[cell.Resolution()]++
+	}
+
+	for res := f.resolutions[0]; res > 0; res-- {
+		var total float64
+		for cellRes, count := range counts {
+			if cellRes > res {
+				total += count // at most one ancestor each
+			} else {
+				total += count * math.Pow(7, float64(res-cellRes))
+			}
+		}
+		if total <= maxOutlineCells {
+			return res
+		}
+	}
+	return 0
+}
+
+// geoJSONRing converts an H3 loop to a closed ring of [lng, lat] points
+func geoJSONRing(loop h3.GeoLoop) [][2]float64 {
+	ring := make([][2]float64, 0, len(loop)+1)
+	for _, ll := range loop {
+		ring = append(ring, [2]float64{ll.Lng, ll.Lat})
+	}
+	if len(ring) > 0 {
+		ring = append(ring, ring[0])
+	}
+	return ring
+}
+
+// ParseGeofenceGeoJSON decodes a GeoJSON Feature as a geofence. Features
+// written by MarshalGeoJSON keep their cells; any other Polygon or
+// MultiPolygon feature is filled with cells at the resolution, keeping cells
+// whose centre is inside. The ID comes from the "id" property or the feature
+// ID, and the name from the "name" property.
+func ParseGeofenceGeoJSON(data []byte, resolution int) (*Geofence, error) {
+	var feature geofenceFeature
+	if err := json.Unmarshal(data, &feature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGeofence, err)
+	}
+	if feature.Type != "Feature" {
+		return nil, fmt.Errorf("%w: GeoJSON type %q, want Feature", ErrInvalidGeofence, feature.Type)
+	}
+
+	id := feature.ID
+	if s, ok := feature.Properties["id"].(string); ok && s != "" {
+		id = s
+	}
+	name, _ := feature.Properties["name"].(string)
+
+	properties := make(map[string]any)
+	for k, v := range feature.Properties {
+		if k != "id" && k != "name" && k != "h3_cells" {
+			properties[k] = v
+		}
+	}
+	if len(properties) == 0 {
+		properties = nil
+	}
+
+	var cellIDs []string
+	if stored, ok := feature.Properties["h3_cells"].([]any); ok {
+		for _, v := range stored {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: h3_cells holds %v", ErrInvalidGeofence, v)
+			}
+			cellIDs = append(cellIDs, s)
+		}
+	} else {
+		polygons, err := geoJSONPolygons(feature.Geometry.Type, feature.Geometry.Coordinates)
+		if err != nil {
+			return nil, err
+		}
+		if cellIDs, err = PolygonsToCells(polygons, resolution, ContainmentCentroid); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := NewGeofence(id, name, cellIDs)
+	if err != nil {
+		return nil, err
+	}
+	f.Properties = properties
+	return f, nil
+}
+
+// geoJSONPolygons converts Polygon or MultiPolygon coordinates to polygons of [lat, lng] points
+func geoJSONPolygons(geometryType string, coordinates json.RawMessage) ([]Polygon, error) {
+	var multi [][][][2]float64
+	switch geometryType {
+	case "Polygon":
+		var rings [][][2]float64
+		if err := json.Unmarshal(coordinates, &rings); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidGeofence, err)
+		}
+		multi = [][][][2]float64{rings}
+	case "MultiPolygon":
+		if err := json.Unmarshal(coordinates, &multi); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidGeofence, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: geometry type %q", ErrInvalidGeofence, geometryType)
+	}
+
+	polygons := make([]Polygon, 0, len(multi))
+	for _, rings := range multi {
+		if len(rings) == 0 {
+			continue
+		}
+		polygon := Polygon{Outer: latLngRing(rings[0])}
+		for _, hole := range rings[1:] {
+			polygon.Holes = append(polygon.Holes, latLngRing(hole))
+		}
+		polygons = append(polygons, polygon)
+	}
+	return polygons, nil
+}
+
+// latLngRing swaps GeoJSON [lng, lat] points to [lat, lng]
+func latLngRing(ring [][2]float64) [][2]float64 {
+	result := make([][2]float64, len(ring))
+	for i, pt := range ring {
+		result[i] = [2]float64{pt[1], pt[0]}
+	}
+	return result
+}
+
+// GeofenceSet finds the geofences containing a point or cell. Each cell of
+// every geofence is indexed, so a lookup costs a few map reads per
+// resolution in use rather than a test against each geofence.
+type GeofenceSet struct {
+	fences map[string]*Geofence
+	index  map[h3.Cell][]*Geofence
+	finest map[int]int // Geofences by finest resolution
+
+	mu sync.RWMutex
+}
+
+// NewGeofenceSet creates a set holding the given geofences
+func NewGeofenceSet(fences ...*Geofence) (*GeofenceSet, error) {
+	s := &GeofenceSet{
+		fences: make(map[string]*Geofence),
+		index:  make(map[h3.Cell][]*Geofence),
+		finest: make(map[int]int),
+	}
+	for _, f := range fences {
+		if err := s.Add(f); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Add adds a geofence, which must not be changed while in the set
+func (s *GeofenceSet) Add(f *Geofence) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.fences[f.ID]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateGeofence, f.ID)
+	}
+	s.fences[f.ID] = f
+	for cell := range f.cells {
+		s.index[cell] = append(s.index[cell], f)
+	}
+	if res := f.FinestResolution(); res >= 0 {
+		s.finest[res]++
+	}
+	return nil
+}
+
+// Remove removes a geofence, reporting whether it was in the set
+func (s *GeofenceSet) Remove(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.fences[id]
+	if !ok {
+		return false
+	}
+	delete(s.fences, id)
+	for cell := range f.cells {
+		indexed := s.index[cell]
+		for i, other := range indexed {
+			if other == f {
+				indexed = append(indexed[:i], indexed[i+1:]...)
+				break
+			}
+		}
+		if len(indexed) == 0 {
+			delete(s.index, cell)
+		} else {
+			s.index[cell] = indexed
+		}
+	}
+	if res := f.FinestResolution(); res >= 0 {
+		if s.finest[res]--; s.finest[res] == 0 {
+			delete(s.finest, res)
+		}
+	}
+	return true
+}
+
+// Get returns a geofence by ID
+func (s *GeofenceSet) Get(id string) (*Geofence, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f, ok := s.fences[id]
+	return f, ok
+}
+
+// Len returns the number of geofences
+func (s *GeofenceSet) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.fences)
+}
+
+// Match returns every geofence containing a point, as Geofence.Contains
+// decides, sorted by ID
+func (s *GeofenceSet) Match(lat, lng float64) []*Geofence {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ll := h3.NewLatLng(lat, lng)
+	found := make(map[*Geofence]bool)
+	for finest := range s.finest {
+		cell := h3.LatLngToCell(ll, finest)
+		s.collectLocked(cell, found, func(f *Geofence) bool { return f.FinestResolution() == finest })
+	}
+	return sortedGeofences(found)
+}
+
+// MatchCell returns every geofence entirely containing a cell, as
+// Geofence.ContainsCell decides, sorted by ID
+func (s *GeofenceSet) MatchCell(cellID string) []*Geofence {
+	cell, err := cellFromString(cellID)
+	if err != nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	found := make(map[*Geofence]bool)
+	s.collectLocked(cell, found, func(*Geofence) bool { return true })
+	return sortedGeofences(found)
+}
+
+// collectLocked adds the geofences accepted by keep that hold a cell or one
+// of its ancestors (must hold lock)
+func (s *GeofenceSet) collectLocked(cell h3.Cell, found map[*Geofence]bool, keep func(*Geofence) bool) {
+	for res := cell.Resolution(); res >= MinResolution; res-- {
+		ancestor := cell
+		if res < cell.Resolution() {
+			ancestor = cell.Parent(res)
+		}
+		for _, f := range s.index[ancestor] {
+			if keep(f) {
+				found[f] = true
+			}
+		}
+	}
+}
+
+// sortedGeofences returns a set of geofences sorted by ID
+func sortedGeofences(found map[*Geofence]bool) []*Geofence {
+	result := make([]*Geofence, 0, len(found))
+	for f := range found {
+		result = append(result, f)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
@@ -0,0 +1,318 @@
+package h3utils
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testGeofence returns a geofence of a resolution 7 cell around Delhi given
+// as its resolution 9 grandchildren, plus one resolution 9 cell outside it
+func testGeofence(t *testing.T) (fence *Geofence, parent, extra string) {
+	t.Helper()
+	parent = LatLngToCellAtResolution(testLat, testLng, 7)
+	children, _ := GetChildren(parent, 9)
+	extra = LatLngToCellAtResolution(testLat+0.2, testLng, 9)
+
+	fence, err := NewGeofence("ward-1", "Ward 1", append(children, extra, children[0]))
+	if err != nil {
+		t.Fatalf("NewGeofence() error: %v", err)
+	}
+	return fence, parent, extra
+}
+
+func TestNewGeofenceCompacts(t *testing.T) {
+	fence, parent, extra := testGeofence(t)
+
+	if want := SortCells([]string{parent, extra}); !reflect.DeepEqual(fence.Cells(), want) {
+		t.Errorf("Cells() = %v, want %v", fence.Cells(), want)
+	}
+	if fence.FinestResolution() != 9 {
+		t.Errorf("FinestResolution() = %d, want 9", fence.FinestResolution())
+	}
+	if cells, _ := fence.UncompactCells(9); len(cells) != 50 {
+		t.Errorf("UncompactCells(9) returned %d cells, want 49 + 1", len(cells))
+	}
+
+	// A cell inside another is dropped
+	nested, _ := NewGeofence("nested", "", []string{parent, extra, LatLngToCellAtResolution(testLat, testLng, 8)})
+	if nested.Len() != 2 {
+		t.Errorf("Len() with a nested cell = %d, want 2", nested.Len())
+	}
+
+	if _, err := NewGeofence("", "", []string{parent}); !errors.Is(err, ErrInvalidGeofence) {
+		t.Errorf("missing ID error = %v, want ErrInvalidGeofence", err)
+	}
+	if _, err := NewGeofence("bad", "", []string{"invalid"}); !errors.Is(err, ErrInvalidCellID) {
+		t.Errorf("invalid cell error = %v, want ErrInvalidCellID", err)
+	}
+}
+
+func TestGeofenceContains(t *testing.T) {
+	fence, parent, extra := testGeofence(t)
+
+	if !fence.Contains(testLat, testLng) {
+		t.Error("Contains() = false inside the fence")
+	}
+	if fence.Contains(13.0827, 80.2707) {
+		t.Error("Contains() = true far outside the fence")
+	}
+
+	grandparent, _ := GetParent(parent, 6)
+	extraParent, _ := GetParent(extra, 8)
+	child, _ := GetChildren(parent, 12)
+	tests := []struct {
+		cellID string
+		want   bool
+	}{
+		{parent, true},
+		{child[3], true},
+		{extra, true},
+		{grandparent, false}, // Only partly covered
+		{extraParent, false},
+		{"invalid", false},
+	}
+	for _, tt := range tests {
+		if got := fence.ContainsCell(tt.cellID); got != tt.want {
+			t.Errorf("ContainsCell(%s) = %v, want %v", tt.cellID, got, tt.want)
+		}
+	}
+}
+
+func TestGeofenceJSON(t *testing.T) {
+	fence, _, _ := testGeofence(t)
+	fence.Properties = map[string]any{"campaign": "clean streets"}
+
+	encoded, err := json.Marshal(fence)
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	var decoded Geofence
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, fence) {
+		t.Errorf("decoded geofence = %+v, want %+v", decoded, fence)
+	}
+}
+
+func TestGeofenceGeoJSON(t *testing.T) {
+	fence, _, _ := testGeofence(t)
+	fence.Properties = map[string]any{"campaign": "clean streets"}
+
+	encoded, err := fence.MarshalGeoJSON()
+	if err != nil {
+		t.Fatalf("MarshalGeoJSON() error: %v", err)
+	}
+	decoded, err := ParseGeofenceGeoJSON(encoded, 9)
+	if err != nil {
+		t.Fatalf("ParseGeofenceGeoJSON() error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, fence) {
+		t.Errorf("decoded geofence = %+v, want %+v", decoded, fence)
+	}
+
+	// Two separate areas, each outline a closed ring
+	var feature struct {
+		Geometry struct {
+			Type        string
+			Coordinates [][][][2]float64
+		}
+	}
+	if err := json.Unmarshal(encoded, &feature); err != nil {
+		t.Fatal(err)
+	}
+	if feature.Geometry.Type != "MultiPolygon" || len(feature.Geometry.Coordinates) != 2 {
+		t.Fatalf("geometry = %s with %d polygons, want MultiPolygon with 2", feature.Geometry.Type, len(feature.Geometry.Coordinates))
+	}
+	for _, polygon := range feature.Geometry.Coordinates {
+		if ring := polygon[0]; ring[0] != ring[len(ring)-1] {
+			t.Error("outline ring is not closed")
+		}
+	}
+
+	// A plain polygon feature is filled at the given resolution
+	plain := `{"type":"Feature","id":"zone-7","properties":{"name":"Zone 7"},"geometry":{"type":"Polygon",
+		"coordinates":[[[77.20,28.60],[77.22,28.60],[77.22,28.62],[77.20,28.62],[77.20,28.60]]]}}`
+	zone, err := ParseGeofenceGeoJSON([]byte(plain), 9)
+	if err != nil {
+		t.Fatalf("ParseGeofenceGeoJSON() of a polygon error: %v", err)
+	}
+	if zone.ID != "zone-7" || zone.Name != "Zone 7" || !zone.Contains(28.61, 77.21) || zone.Contains(28.65, 77.21) {
+		t.Errorf("parsed polygon = %s %q, contains centre %v", zone.ID, zone.Name, zone.Contains(28.61, 77.21))
+	}
+
+	if _, err := ParseGeofenceGeoJSON([]byte(`{"type":"Feature","id":"p","geometry":{"type":"Point","coordinates":[77.2,28.6]}}`), 9); !errors.Is(err, ErrInvalidGeofence) {
+		t.Errorf("point geometry error = %v, want ErrInvalidGeofence", err)
+	}
+}
+
+// geoJSONOutline decodes the MultiPolygon coordinates written by MarshalGeoJSON
+func geoJSONOutline(t *testing.T, encoded []byte) [][][][2]float64 {
+	t.Helper()
+	var feature struct {
+		Geometry struct {
+			Coordinates [][][][2]float64
+		}
+	}
+	if err := json.Unmarshal(encoded, &feature); err != nil {
+		t.Fatal(err)
+	}
+	return feature.Geometry.Coordinates
+}
+
+// outlineContains reports whether a point is inside any polygon of an outline
+func outlineContains(outline [][][][2]float64, lat, lng float64) bool {
+	for _, rings := range outline {
+		if ringsContain(rings, [2]float64{lng, lat}) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGeofenceGeoJSONMixedResolutions(t *testing.T) {
+	// A resolution 7 cell next to some resolution 8 and 9 cells of its
+	// neighbour, which leave a gap along the shared edge
+	cell := LatLngToCellAtResolution(testLat, testLng, 7)
+	neighbours, _ := GetNeighbors(cell)
+	children, _ := GetChildren(neighbours[0], 9)
+	fence, err := NewGeofence("mixed", "", append([]string{cell}, children[:40]...))
+	if err != nil {
+		t.Fatalf("NewGeofence() error: %v", err)
+	}
+	if len(fence.resolutions) != 3 {
+		t.Fatalf("geofence resolutions = %v, want 7, 8 and 9", fence.resolutions)
+	}
+
+	encoded, err := fence.MarshalGeoJSON()
+	if err != nil {
+		t.Fatalf("MarshalGeoJSON() error: %v", err)
+	}
+	outline := geoJSONOutline(t, encoded)
+
+	lat, lng, _ := CellToLatLng(cell)
+	var inside int
+	for i := -60; i <= 60; i++ {
+		for j := -60; j <= 60; j++ {
+			pLat, pLng := lat+float64(i)*0.0005, lng+float64(j)*0.0005
+			want := fence.Contains(pLat, pLng)
+			if got := outlineContains(outline, pLat, pLng); got != want {
+				t.Fatalf("outline contains (%f, %f) = %v, Contains() = %v", pLat, pLng, got, want)
+			}
+			if want {
+				inside++
+			}
+		}
+	}
+	if inside == 0 {
+		t.Fatal("no sample point is inside the geofence")
+	}
+
+	decoded, err := ParseGeofenceGeoJSON(encoded, 9)
+	if err != nil || !reflect.DeepEqual(decoded, fence) {
+		t.Errorf("decoded geofence = %+v, %v, want %+v", decoded, err, fence)
+	}
+}
+
+func TestGeofenceGeoJSONCapsOutlineCells(t *testing.T) {
+	// A district-sized cell and a booth-sized one are outlined at resolution
+	// 10, not as 823,543 booth-sized cells
+	district := LatLngToCellAtResolution(testLat, testLng, 5)
+	booth := LatLngToCellAtResolution(13.0827, 80.2707, 12)
+	fence, err := NewGeofence("mixed", "", []string{district, booth})
+	if err != nil {
+		t.Fatalf("NewGeofence() error: %v", err)
+	}
+	if res := fence.outlineResolution(); res != 10 {
+		t.Errorf("outlineResolution() = %d, want 10", res)
+	}
+
+	encoded, err := fence.MarshalGeoJSON()
+	if err != nil {
+		t.Fatalf("MarshalGeoJSON() error: %v", err)
+	}
+	outline := geoJSONOutline(t, encoded)
+	if len(outline) != 2 {
+		t.Fatalf("outline has %d polygons, want one per cell", len(outline))
+	}
+
+	// The booth cell is outlined as its resolution 10 ancestor
+	for _, cellID := range []string{district, booth} {
+		lat, lng, _ := CellToLatLng(cellID)
+		if !outlineContains(outline, lat, lng) {
+			t.Errorf("outline does not contain the centre of %s", cellID)
+		}
+	}
+	ancestor, _ := GetParent(booth, 10)
+	vertices, _ := GetCellBoundary(ancestor)
+	var found bool
+	for _, polygon := range outline {
+		found = found || len(polygon[0]) == len(vertices)+1
+	}
+	if !found {
+		t.Errorf("no outline polygon is the resolution 10 hexagon around the booth cell")
+	}
+
+	decoded, err := ParseGeofenceGeoJSON(encoded, 9)
+	if err != nil || !reflect.DeepEqual(decoded, fence) {
+		t.Errorf("decoded geofence = %+v, %v, want %+v", decoded, err, fence)
+	}
+}
+
+func TestGeofenceSet(t *testing.T) {
+	ward, parent, _ := testGeofence(t)
+	district, _ := GetParent(parent, 5)
+	coarse, _ := NewGeofence("district", "", []string{district})
+	fine, _ := NewGeofence("booth", "", []string{LatLngToCellAtResolution(testLat, testLng, 11)})
+
+	set, err := NewGeofenceSet(ward, coarse, fine)
+	if err != nil {
+		t.Fatalf("NewGeofenceSet() error: %v", err)
+	}
+	if err := set.Add(ward); !errors.Is(err, ErrDuplicateGeofence) {
+		t.Errorf("Add() of a duplicate error = %v, want ErrDuplicateGeofence", err)
+	}
+
+	ids := func(fences []*Geofence) []string {
+		result := []string{}
+		for _, f := range fences {
+			result = append(result, f.ID)
+		}
+		return result
+	}
+
+	if got := ids(set.Match(testLat, testLng)); !reflect.DeepEqual(got, []string{"booth", "district", "ward-1"}) {
+		t.Errorf("Match() = %v", got)
+	}
+	if got := ids(set.Match(13.0827, 80.2707)); len(got) != 0 {
+		t.Errorf("Match() far away = %v, want none", got)
+	}
+	if got := ids(set.MatchCell(parent)); !reflect.DeepEqual(got, []string{"district", "ward-1"}) {
+		t.Errorf("MatchCell() = %v", got)
+	}
+
+	// Every fence Match returns contains the point on its own
+	for _, pt := range [][2]float64{{testLat, testLng}, {testLat + 0.01, testLng - 0.02}, {testLat + 0.2, testLng}} {
+		for _, f := range []*Geofence{ward, coarse, fine} {
+			matched := false
+			for _, m := range set.Match(pt[0], pt[1]) {
+				matched = matched || m == f
+			}
+			if matched != f.Contains(pt[0], pt[1]) {
+				t.Errorf("Match(%v) includes %s = %v, Contains() disagrees", pt, f.ID, matched)
+			}
+		}
+	}
+
+	if !set.Remove("district") || set.Remove("district") {
+		t.Error("Remove() should succeed once")
+	}
+	if got := ids(set.Match(testLat, testLng)); !reflect.DeepEqual(got, []string{"booth", "ward-1"}) {
+		t.Errorf("Match() after Remove() = %v", got)
+	}
+	if _, ok := set.Get("ward-1"); !ok || set.Len() != 2 {
+		t.Errorf("Get() = %v, Len() = %d, want ward-1 and 2", ok, set.Len())
+	}
+}
@@ -369,6 +369,7 @@ func GetHexagonInfo(cellID string) (*HexagonInfo, error) {
 
 // PolygonToCells fills a polygon with H3 cells
 // Polygon is defined as a slice of lat/lng pairs
+// Use PolygonsToCells for holes, multipolygons and other containment modes
 func PolygonToCells(polygon [][2]float64, resolution int) ([]string, error) {
 	if len(polygon) < 3 {
 		return nil, ErrInvalidPolygon
@@ -411,16 +412,50 @@ func CellsToMultiPolygon(cellIDs []string) ([][][]float64, error) {
 
 	result := make([][][]float64, len(multiPoly))
 	for i, poly := range multiPoly {
-		coords := make([][]float64, len(poly.GeoLoop))
-		for j, ll := range poly.GeoLoop {
-			coords[j] = []float64{ll.Lng, ll.Lat} // GeoJSON is [lng, lat]
+		result[i] = lngLatLoop(poly.GeoLoop)
+	}
+
+	return result, nil
+}
+
+// CellsToPolygons converts a set of cells to GeoJSON MultiPolygon coordinates:
+// one polygon per connected area, its outer ring followed by its holes.
+// Unlike CellsToMultiPolygon it keeps the holes, such as a lake left out of
+// a constituency's cells.
+func CellsToPolygons(cellIDs []string) ([][][][]float64, error) {
+	cells := make([]h3.Cell, len(cellIDs))
+	for i, id := range cellIDs {
+		cell, err := cellFromString(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCellID, id)
 		}
-		result[i] = coords
+		cells[i] = cell
+	}
+
+	multiPoly := h3.CellsToMultiPolygon(cells)
+
+	result := make([][][][]float64, len(multiPoly))
+	for i, poly := range multiPoly {
+		rings := make([][][]float64, 0, 1+len(poly.Holes))
+		rings = append(rings, lngLatLoop(poly.GeoLoop))
+		for _, hole := range poly.Holes {
+			rings = append(rings, lngLatLoop(hole))
+		}
+		result[i] = rings
 	}
 
 	return result, nil
 }
 
+// lngLatLoop converts an H3 loop to [lng, lat] points, the GeoJSON order
+func lngLatLoop(loop h3.GeoLoop) [][]float64 {
+	coords := make([][]float64, len(loop))
+	for i, ll := range loop {
+		coords[i] = []float64{ll.Lng, ll.Lat}
+	}
+	return coords
+}
+
 // GetCellBoundary returns the boundary vertices of a cell
 func GetCellBoundary(cellID string) ([]LatLng, error) {
 	cell, err := cellFromString(cellID)
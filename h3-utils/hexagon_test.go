@@ -422,6 +422,25 @@ func TestCellsToMultiPolygon(t *testing.T) {
 	}
 }
 
+func TestCellsToPolygonsKeepsHoles(t *testing.T) {
+	// A ring of cells around an empty centre cell
+	cell := LatLngToCell(testLat, testLng)
+	cells, _ := GetRing(cell, 1)
+
+	polygons, err := CellsToPolygons(cells)
+	if err != nil {
+		t.Fatalf("CellsToPolygons() error: %v", err)
+	}
+	if len(polygons) != 1 || len(polygons[0]) != 2 {
+		t.Fatalf("CellsToPolygons() = %d polygons, want one with an outer ring and a hole", len(polygons))
+	}
+
+	boundary, _ := GetCellBoundary(cell)
+	if len(polygons[0][1]) != len(boundary) {
+		t.Errorf("hole has %d points, want the %d vertices of the centre cell", len(polygons[0][1]), len(boundary))
+	}
+}
+
 func TestGetCellBoundary(t *testing.T) {
 	cell := LatLngToCell(testLat, testLng)
 
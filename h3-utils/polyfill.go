@@ -0,0 +1,358 @@
+package h3utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/uber/h3-go/v4"
+)
+
+// ErrInvalidContainmentMode is returned for an unknown ContainmentMode
+var ErrInvalidContainmentMode = errors.New("invalid containment mode")
+
+// ContainmentMode decides which cells along a polygon's border are part of it
+type ContainmentMode int
+
+const (
+	// ContainmentCentroid keeps cells whose centre is inside the polygon, as
+	// H3's polyfill does. Cells neither overhang nor miss much area overall.
+	ContainmentCentroid ContainmentMode = iota
+
+	// ContainmentFull keeps only cells entirely inside the polygon, for
+	// precision: no cell reaches outside it
+	ContainmentFull
+
+	// ContainmentOverlapping keeps every cell that intersects the polygon,
+	// for coverage: no part of the polygon is left out
+	ContainmentOverlapping
+)
+
+// String returns the mode's name
+func (m ContainmentMode) String() string {
+	switch m {
+	case ContainmentCentroid:
+		return "centroid"
+	case ContainmentFull:
+		return "full"
+	case ContainmentOverlapping:
+		return "overlapping"
+	default:
+		return fmt.Sprintf("ContainmentMode(%d)", int(m))
+	}
+}
+
+// Polygon is an outer ring with optional holes. Points are [lat, lng] pairs,
+// as in PolygonToCells, and rings may be open or closed.
+type Polygon struct {
+	Outer [][2]float64
+	Holes [][][2]float64
+}
+
+// PolygonsToCells fills a set of polygons with holes, such as the parts of a
+// multipolygon, with H3 cells. The mode decides which cells along the borders
+// are kept; with ContainmentFull a cell must lie entirely inside one polygon.
+// Cells are returned once each, sorted.
+func PolygonsToCells(polygons []Polygon, resolution int, mode ContainmentMode) ([]string, error) {
+	if mode < ContainmentCentroid || mode > ContainmentOverlapping {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidContainmentMode, int(mode))
+	}
+	if err := validatePolygons(polygons, resolution); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[h3.Cell]bool)
+	for _, polygon := range polygons {
+		if mode == ContainmentCentroid {
+			for _, cell := range h3.PolygonToCells(polygon.geoPolygon(), resolution) {
+				seen[cell] = true
+			}
+			continue
+		}
+
+		inside, border := polygonCover(polygon, resolution)
+		for _, cell := range inside {
+			seen[cell] = true
+		}
+		if mode == ContainmentOverlapping {
+			for _, cell := range border {
+				seen[cell] = true
+			}
+		}
+	}
+
+	return sortedCellStrings(seen), nil
+}
+
+// PolygonsToCellCover splits the cells overlapping a set of polygons into
+// those entirely inside one polygon and the border cells that straddle an
+// edge. inside is the ContainmentFull fill; inside and border together are
+// the ContainmentOverlapping fill. Both are sorted.
+func PolygonsToCellCover(polygons []Polygon, resolution int) (inside, border []string, err error) {
+	if err := validatePolygons(polygons, resolution); err != nil {
+		return nil, nil, err
+	}
+
+	insideSet := make(map[h3.Cell]bool)
+	borderSet := make(map[h3.Cell]bool)
+	for _, polygon := range polygons {
+		polygonInside, polygonBorder := polygonCover(polygon, resolution)
+		for _, cell := range polygonInside {
+			insideSet[cell] = true
+		}
+		for _, cell := range polygonBorder {
+			borderSet[cell] = true
+		}
+	}
+
+	// A cell inside one part is not on the border of the whole
+	for cell := range insideSet {
+		delete(borderSet, cell)
+	}
+	return sortedCellStrings(insideSet), sortedCellStrings(borderSet), nil
+}
+
+// validatePolygons checks a resolution and that every ring can enclose an area
+func validatePolygons(polygons []Polygon, resolution int) error {
+	if resolution < MinResolution || resolution > MaxResolution {
+		return ErrInvalidResolution
+	}
+	for _, polygon := range polygons {
+		if len(polygon.Outer) < 3 {
+			return ErrInvalidPolygon
+		}
+		for _, hole := range polygon.Holes {
+			if len(hole) < 3 {
+				return fmt.Errorf("%w: hole with %d points", ErrInvalidPolygon, len(hole))
+			}
+		}
+	}
+	return nil
+}
+
+// sortedCellStrings returns a set of cells as sorted strings
+func sortedCellStrings(cells map[h3.Cell]bool) []string {
+	result := make([]string, 0, len(cells))
+	for cell := range cells {
+		result = append(result, cell.String())
+	}
+	sort.Strings(result)
+	return result
+}
+
+// geoPolygon converts the polygon for H3
+func (p Polygon) geoPolygon() h3.GeoPolygon {
+	geoPolygon := h3.GeoPolygon{GeoLoop: toGeoLoop(p.Outer)}
+	for _, hole := range p.Holes {
+		geoPolygon.Holes = append(geoPolygon.Holes, toGeoLoop(hole))
+	}
+	return geoPolygon
+}
+
+// polygonCover returns the cells entirely inside one polygon and the cells
+// its border touches. Cells near the border are tested exactly against the
+// edges close to them. No edge crosses the other cells, so each connected
+// group of them is entirely inside or outside; groups found inside from one
+// point-in-polygon test are flood filled. Holes entirely outside the outer
+// ring remove nothing and are skipped, so their edges make no border cells.
+func polygonCover(polygon Polygon, resolution int) (inside, border []h3.Cell) {
+	rings := [][][2]float64{polygon.Outer}
+	for _, hole := range polygon.Holes {
+		if !ringOutside(hole, polygon.Outer) {
+			rings = append(rings, hole)
+		}
+	}
+	edgesNear := borderCells(rings, resolution)
+
+	for cell, edges := range edgesNear {
+		switch {
+		case cellTouchesRings(cell, rings, edges):
+			border = append(border, cell)
+		case ringsContain(rings, cellCentre(cell)):
+			inside = append(inside, cell)
+		}
+	}
+
+	visited := make(map[h3.Cell]bool)
+	var queue []h3.Cell
+	for cell := range edgesNear {
+		for _, seed := range cell.GridDisk(1) {
+			if _, near := edgesNear[seed]; near || visited[seed] {
+				continue
+			}
+			visited[seed] = true
+			if !ringsContain(rings, cellCentre(seed)) {
+				continue
+			}
+
+			queue = append(queue[:0], seed)
+			for len(queue) > 0 {
+				current := queue[len(queue)-1]
+				queue = queue[:len(queue)-1]
+				inside = append(inside, current)
+				for _, next := range current.GridDisk(1) {
+					if _, near := edgesNear[next]; near || visited[next] {
+						continue
+					}
+					visited[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+	}
+	return inside, border
+}
+
+// ringEdge identifies the edge from rings[ring][index] to the next point
+type ringEdge struct {
+	ring, index int
+}
+
+// borderCells returns the cells close enough to the border of a set of rings
+// to be crossed by it, each with the edges that may cross it. Edges are
+// sampled at a third of the cell edge length, so every cell an edge crosses
+// contains a sample or neighbours a cell that does.
+func borderCells(rings [][][2]float64, resolution int) map[h3.Cell][]ringEdge {
+	spacingM := h3.HexagonEdgeLengthAvgM(resolution) / 3
+	edgesNear := make(map[h3.Cell][]ringEdge)
+	add := func(cell h3.Cell, edge ringEdge) {
+		if edges := edgesNear[cell]; len(edges) == 0 || edges[len(edges)-1] != edge {
+			edgesNear[cell] = append(edges, edge)
+		}
+	}
+
+	for r, ring := range rings {
+		for i := range ring {
+			a, b := ring[i], ring[(i+1)%len(ring)]
+			edge := ringEdge{ring: r, index: i}
+			steps := int(math.Ceil(HaversineDistance(a[0], a[1], b[0], b[1]) / spacingM))
+			last := h3.Cell(0)
+			for step := 0; step <= steps; step++ {
+				t := 0.0
+				if steps > 0 {
+					t = float64(step) / float64(steps)
+				}
+				cell := h3.LatLngToCell(h3.NewLatLng(a[0]+t*(b[0]-a[0]), a[1]+t*(b[1]-a[1])), resolution)
+				if cell == last {
+					continue
+				}
+				last = cell
+				for _, near := range cell.GridDisk(1) {
+					add(near, edge)
+				}
+			}
+		}
+	}
+	return edgesNear
+}
+
+// cellTouchesRings reports whether an edge of the rings crosses or touches
+// a cell's boundary, or a ring lies within the cell
+func cellTouchesRings(cell h3.Cell, rings [][][2]float64, edges []ringEdge) bool {
+	loop := cellLoop(cell)
+	for _, edge := range edges {
+		a, b := edgePoints(rings, edge)
+		if ringContains(loop, a) {
+			return true
+		}
+		for i := range loop {
+			if segmentsIntersect(a, b, loop[i], loop[(i+1)%len(loop)]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ringsContain reports whether a point is inside the outer ring and outside
+// every hole, as H3 decides for cell centres. A hole lying outside the outer
+// ring therefore adds nothing.
+func ringsContain(rings [][][2]float64, pt [2]float64) bool {
+	if !ringContains(rings[0], pt) {
+		return false
+	}
+	for _, hole := range rings[1:] {
+		if ringContains(hole, pt) {
+			return false
+		}
+	}
+	return true
+}
+
+// ringOutside reports whether no point of a ring lies inside another ring
+func ringOutside(ring, other [][2]float64) bool {
+	for _, pt := range ring {
+		if ringContains(other, pt) {
+			return false
+		}
+	}
+	return true
+}
+
+// cellCentre returns a cell's centre as a [lat, lng] point
+func cellCentre(cell h3.Cell) [2]float64 {
+	ll := cell.LatLng()
+	return [2]float64{ll.Lat, ll.Lng}
+}
+
+// cellLoop returns a cell's boundary as [lat, lng] points
+func cellLoop(cell h3.Cell) [][2]float64 {
+	boundary := cell.Boundary()
+	loop := make([][2]float64, len(boundary))
+	for i, ll := range boundary {
+		loop[i] = [2]float64{ll.Lat, ll.Lng}
+	}
+	return loop
+}
+
+// edgePoints returns the ends of a ring edge
+func edgePoints(rings [][][2]float64, edge ringEdge) (a, b [2]float64) {
+	ring := rings[edge.ring]
+	return ring[edge.index], ring[(edge.index+1)%len(ring)]
+}
+
+// toGeoLoop converts [lat, lng] pairs to an H3 loop
+func toGeoLoop(ring [][2]float64) h3.GeoLoop {
+	loop := make(h3.GeoLoop, len(ring))
+	for i, pt := range ring {
+		loop[i] = h3.NewLatLng(pt[0], pt[1])
+	}
+	return loop
+}
+
+// ringContains reports whether a point is inside a ring, by ray casting
+func ringContains(ring [][2]float64, pt [2]float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a[0] > pt[0]) != (b[0] > pt[0]) && pt[1] < (b[1]-a[1])*(pt[0]-a[0])/(b[0]-a[0])+a[1] {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// segmentsIntersect reports whether segments ab and cd cross or touch
+func segmentsIntersect(a, b, c, d [2]float64) bool {
+	d1, d2 := orientation(c, d, a), orientation(c, d, b)
+	d3, d4 := orientation(a, b, c), orientation(a, b, d)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+
+	// An end lying on the other segment
+	return (d1 == 0 && onSegment(c, d, a)) || (d2 == 0 && onSegment(c, d, b)) ||
+		(d3 == 0 && onSegment(a, b, c)) || (d4 == 0 && onSegment(a, b, d))
+}
+
+// onSegment reports whether p, collinear with ab, lies between a and b
+func onSegment(a, b, p [2]float64) bool {
+	return math.Min(a[0], b[0]) <= p[0] && p[0] <= math.Max(a[0], b[0]) &&
+		math.Min(a[1], b[1]) <= p[1] && p[1] <= math.Max(a[1], b[1])
+}
+
+// orientation returns the sign of the turn from a to b to c
+func orientation(a, b, c [2]float64) float64 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
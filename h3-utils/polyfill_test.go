@@ -0,0 +1,187 @@
+package h3utils
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testSquare returns a closed [lat, lng] square ring
+func testSquare(lat, lng, size float64) [][2]float64 {
+	return [][2]float64{{lat, lng}, {lat, lng + size}, {lat + size, lng + size}, {lat + size, lng}, {lat, lng}}
+}
+
+func TestPolygonsToCellsHoles(t *testing.T) {
+	hole := testSquare(28.605, 77.205, 0.01)
+	polygon := Polygon{Outer: testSquare(28.60, 77.20, 0.02), Holes: [][][2]float64{hole}}
+
+	modes := []ContainmentMode{ContainmentCentroid, ContainmentFull, ContainmentOverlapping}
+	cells := make(map[ContainmentMode]map[string]bool)
+	for _, mode := range modes {
+		list, err := PolygonsToCells([]Polygon{polygon}, 9, mode)
+		if err != nil {
+			t.Fatalf("PolygonsToCells(%s) error: %v", mode, err)
+		}
+		if len(list) == 0 {
+			t.Fatalf("PolygonsToCells(%s) returned no cells", mode)
+		}
+		cells[mode] = make(map[string]bool, len(list))
+		for _, cellID := range list {
+			cells[mode][cellID] = true
+		}
+	}
+
+	// Nothing in the middle of the hole, whatever the mode
+	middle := LatLngToCellAtResolution(28.61, 77.21, 9)
+	for _, mode := range modes {
+		if cells[mode][middle] {
+			t.Errorf("%s cells include the middle of the hole", mode)
+		}
+	}
+
+	// Full cells have every vertex inside the outer ring and outside the hole
+	for cellID := range cells[ContainmentFull] {
+		if !cells[ContainmentCentroid][cellID] {
+			t.Errorf("full cell %s is not a centroid cell", cellID)
+		}
+		boundary, _ := GetCellBoundary(cellID)
+		for _, v := range boundary {
+			pt := [2]float64{v.Lat, v.Lng}
+			if !ringContains(polygon.Outer, pt) || ringContains(hole, pt) {
+				t.Errorf("full cell %s reaches outside the polygon at %v", cellID, pt)
+				break
+			}
+		}
+	}
+
+	// Overlapping cells cover every point of the polygon
+	for cellID := range cells[ContainmentCentroid] {
+		if !cells[ContainmentOverlapping][cellID] {
+			t.Errorf("centroid cell %s is not an overlapping cell", cellID)
+		}
+	}
+	for _, ring := range [][][2]float64{polygon.Outer, hole} {
+		for _, pt := range ring {
+			if cellID := LatLngToCellAtResolution(pt[0], pt[1], 9); !cells[ContainmentOverlapping][cellID] {
+				t.Errorf("corner %v is in %s, not an overlapping cell", pt, cellID)
+			}
+		}
+	}
+
+	if !(len(cells[ContainmentFull]) < len(cells[ContainmentCentroid]) &&
+		len(cells[ContainmentCentroid]) < len(cells[ContainmentOverlapping])) {
+		t.Errorf("cell counts full %d, centroid %d, overlapping %d, want increasing",
+			len(cells[ContainmentFull]), len(cells[ContainmentCentroid]), len(cells[ContainmentOverlapping]))
+	}
+}
+
+func TestPolygonsToCellsMultiPolygon(t *testing.T) {
+	west := Polygon{Outer: testSquare(28.60, 77.20, 0.01)}
+	east := Polygon{Outer: testSquare(28.60, 77.25, 0.01)}
+
+	both, err := PolygonsToCells([]Polygon{west, east}, 9, ContainmentCentroid)
+	if err != nil {
+		t.Fatalf("PolygonsToCells() error: %v", err)
+	}
+	westCells, _ := PolygonsToCells([]Polygon{west}, 9, ContainmentCentroid)
+	eastCells, _ := PolygonsToCells([]Polygon{east}, 9, ContainmentCentroid)
+	if len(both) != len(westCells)+len(eastCells) {
+		t.Errorf("got %d cells, want %d + %d", len(both), len(westCells), len(eastCells))
+	}
+	for i := 1; i < len(both); i++ {
+		if both[i-1] >= both[i] {
+			t.Fatalf("cells not sorted and unique at %d", i)
+		}
+	}
+}
+
+func TestPolygonsToCellsHoleOutsideOuterRing(t *testing.T) {
+	outer := testSquare(28.60, 77.20, 0.01)
+	stray := []Polygon{{Outer: outer, Holes: [][][2]float64{testSquare(28.62, 77.20, 0.02)}}}
+	plain := []Polygon{{Outer: outer}}
+
+	// A hole outside the outer ring adds nothing, as with H3's own fill
+	for _, mode := range []ContainmentMode{ContainmentCentroid, ContainmentFull, ContainmentOverlapping} {
+		got, err := PolygonsToCells(stray, 9, mode)
+		if err != nil {
+			t.Fatalf("PolygonsToCells(mode %d) error: %v", mode, err)
+		}
+		want, _ := PolygonsToCells(plain, 9, mode)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("mode %d: got %d cells, want the %d of the outer ring alone", mode, len(got), len(want))
+		}
+	}
+}
+
+func TestPolygonsToCellsSmallPolygon(t *testing.T) {
+	// Far smaller than a resolution 5 cell (~250 km²)
+	small := []Polygon{{Outer: testSquare(28.6139, 77.2090, 0.001)}}
+
+	if cells, _ := PolygonsToCells(small, 5, ContainmentFull); len(cells) != 0 {
+		t.Errorf("ContainmentFull = %v, want none", cells)
+	}
+	cells, err := PolygonsToCells(small, 5, ContainmentOverlapping)
+	if err != nil {
+		t.Fatalf("PolygonsToCells() error: %v", err)
+	}
+	if want := LatLngToCellAtResolution(28.6139, 77.2090, 5); len(cells) != 1 || cells[0] != want {
+		t.Errorf("ContainmentOverlapping = %v, want [%s]", cells, want)
+	}
+}
+
+func TestPolygonsToCellCover(t *testing.T) {
+	polygons := []Polygon{{Outer: testSquare(28.60, 77.20, 0.02), Holes: [][][2]float64{testSquare(28.605, 77.205, 0.01)}}}
+
+	inside, border, err := PolygonsToCellCover(polygons, 9)
+	if err != nil {
+		t.Fatalf("PolygonsToCellCover() error: %v", err)
+	}
+	full, _ := PolygonsToCells(polygons, 9, ContainmentFull)
+	overlapping, _ := PolygonsToCells(polygons, 9, ContainmentOverlapping)
+	if len(inside) != len(full) || len(inside)+len(border) != len(overlapping) {
+		t.Errorf("got %d inside and %d border cells, want %d full of %d overlapping",
+			len(inside), len(border), len(full), len(overlapping))
+	}
+
+	insideSet := make(map[string]bool, len(inside))
+	for _, cellID := range inside {
+		insideSet[cellID] = true
+	}
+	for _, cellID := range border {
+		if insideSet[cellID] {
+			t.Errorf("cell %s is both inside and on the border", cellID)
+		}
+	}
+}
+
+func TestPolygonsToCellsErrors(t *testing.T) {
+	square := []Polygon{{Outer: testSquare(28.60, 77.20, 0.01)}}
+
+	if _, err := PolygonsToCells(square, 20, ContainmentCentroid); !errors.Is(err, ErrInvalidResolution) {
+		t.Errorf("invalid resolution error = %v, want ErrInvalidResolution", err)
+	}
+	if _, err := PolygonsToCells(square, 9, ContainmentMode(7)); !errors.Is(err, ErrInvalidContainmentMode) {
+		t.Errorf("invalid mode error = %v, want ErrInvalidContainmentMode", err)
+	}
+	badHole := []Polygon{{Outer: square[0].Outer, Holes: [][][2]float64{{{28.605, 77.205}, {28.606, 77.206}}}}}
+	if _, err := PolygonsToCells(badHole, 9, ContainmentCentroid); !errors.Is(err, ErrInvalidPolygon) {
+		t.Errorf("degenerate hole error = %v, want ErrInvalidPolygon", err)
+	}
+}
+
+func TestSegmentsIntersect(t *testing.T) {
+	tests := []struct {
+		a, b, c, d [2]float64
+		want       bool
+	}{
+		{[2]float64{0, 0}, [2]float64{2, 2}, [2]float64{0, 2}, [2]float64{2, 0}, true},
+		{[2]float64{0, 0}, [2]float64{1, 0}, [2]float64{1, 0}, [2]float64{1, 1}, true},    // Touching ends
+		{[2]float64{0, 0}, [2]float64{1, 0}, [2]float64{2, 0}, [2]float64{0.5, 1}, false}, // Collinear end beyond the segment
+		{[2]float64{0, 0}, [2]float64{1, 1}, [2]float64{0, 1}, [2]float64{0.4, 0.6}, false},
+	}
+	for _, tt := range tests {
+		if got := segmentsIntersect(tt.a, tt.b, tt.c, tt.d); got != tt.want {
+			t.Errorf("segmentsIntersect(%v, %v, %v, %v) = %v, want %v", tt.a, tt.b, tt.c, tt.d, got, tt.want)
+		}
+	}
+}
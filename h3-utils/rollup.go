@@ -0,0 +1,161 @@
+package h3utils
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/uber/h3-go/v4"
+)
+
+// Metric is a value measured in one cell, with the weight and number of
+// observations behind it. For a count, Value and Count are the same.
+type Metric struct {
+	Value  float64 `json:"value"`
+	Weight float64 `json:"weight"`
+	Count  int     `json:"count"`
+}
+
+// Combiner merges the metrics of the source cells under one parent into the
+// parent's metric. Cells are passed sorted by ID, so results are repeatable.
+// Returning false leaves the parent out of the rollup.
+type Combiner func(children []Metric) (Metric, bool)
+
+// Sum adds values, weights and counts
+func Sum(children []Metric) (Metric, bool) {
+	var result Metric
+	for _, m := range children {
+		result.Value += m.Value
+		result.Weight += m.Weight
+		result.Count += m.Count
+	}
+	return result, true
+}
+
+// Mean averages the values, each cell counting once. Weights and counts are added.
+func Mean(children []Metric) (Metric, bool) {
+	result, _ := Sum(children)
+	if len(children) == 0 {
+		return result, false
+	}
+	result.Value /= float64(len(children))
+	return result, true
+}
+
+// WeightedMean averages the values by weight, such as the mean of per-cell
+// ratings weighted by the number of responses. Parents whose cells carry no
+// weight are left out.
+func WeightedMean(children []Metric) (Metric, bool) {
+	var result Metric
+	var weighted float64
+	for _, m := range children {
+		weighted += m.Value * m.Weight
+		result.Weight += m.Weight
+		result.Count += m.Count
+	}
+	if result.Weight == 0 {
+		return result, false
+	}
+	result.Value = weighted / result.Weight
+	return result, true
+}
+
+// MinCount wraps a combiner to leave out parents with fewer than minCount
+// observations, so sparse areas are not published
+func MinCount(minCount int, combine Combiner) Combiner {
+	return func(children []Metric) (Metric, bool) {
+		result, ok := combine(children)
+		if !ok || result.Count < minCount {
+			return Metric{}, false
+		}
+		return result, true
+	}
+}
+
+// CountCells counts how often each cell appears, such as the cells of
+// reported issues, as metrics with one observation of weight 1 per occurrence
+func CountCells(cellIDs []string) map[string]Metric {
+	metrics := make(map[string]Metric)
+	for _, id := range cellIDs {
+		m := metrics[id]
+		m.Value++
+		m.Weight++
+		m.Count++
+		metrics[id] = m
+	}
+	return metrics
+}
+
+// RollupResolutions are the levels RollupHierarchy aggregates to
+var RollupResolutions = []int{ACResolution, DistrictResolution, StateResolution}
+
+// Rollup aggregates metrics keyed by cell to their parents at a coarser
+// resolution. Cells already at that resolution are their own parent.
+func Rollup(metrics map[string]Metric, resolution int, combine Combiner) (map[string]Metric, error) {
+	levels, err := RollupLevels(metrics, []int{resolution}, combine)
+	if err != nil {
+		return nil, err
+	}
+	return levels[resolution], nil
+}
+
+// RollupHierarchy aggregates metrics, typically at resolution 9, to the AC,
+// district and state resolutions
+func RollupHierarchy(metrics map[string]Metric, combine Combiner) (map[int]map[string]Metric, error) {
+	return RollupLevels(metrics, RollupResolutions, combine)
+}
+
+// RollupLevels aggregates metrics to each of several resolutions. Every
+// level is combined from the source cells rather than the level below, so a
+// mean is the mean of the source values and not a mean of means.
+func RollupLevels(metrics map[string]Metric, resolutions []int, combine Combiner) (map[int]map[string]Metric, error) {
+	finest := MinResolution
+	for _, res := range resolutions {
+		if res < MinResolution || res > MaxResolution {
+			return nil, ErrInvalidResolution
+		}
+		finest = max(finest, res)
+	}
+
+	ids := make([]string, 0, len(metrics))
+	for id := range metrics {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	cells := make([]h3.Cell, len(ids))
+	for i, id := range ids {
+		cell, err := cellFromString(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCellID, id)
+		}
+		if cell.Resolution() < finest {
+			return nil, fmt.Errorf("%w: cell %s is coarser than resolution %d", ErrInvalidResolution, id, finest)
+		}
+		cells[i] = cell
+	}
+
+	levels := make(map[int]map[string]Metric, len(resolutions))
+	for _, res := range resolutions {
+		if _, done := levels[res]; done {
+			continue
+		}
+
+		groups := make(map[h3.Cell][]Metric)
+		for i, cell := range cells {
+			parent := cell
+			if cell.Resolution() > res {
+				parent = cell.Parent(res)
+			}
+			groups[parent] = append(groups[parent], metrics[ids[i]])
+		}
+
+		level := make(map[string]Metric, len(groups))
+		for parent, children := range groups {
+			if m, ok := combine(children); ok {
+				level[parent.String()] = m
+			}
+		}
+		levels[res] = level
+	}
+	return levels, nil
+}
@@ -0,0 +1,166 @@
+package h3utils
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// testRollupMetrics returns two resolution 9 cells under one AC-level parent
+// and one under another, all under one state-level parent
+func testRollupMetrics(t *testing.T) (metrics map[string]Metric, parentA, parentB string) {
+	t.Helper()
+	cellA := LatLngToCellAtResolution(testLat, testLng, 9)
+	neighbours, _ := GetNeighbors(cellA)
+	parentA, _ = GetParent(cellA, ACResolution)
+
+	var cellB string
+	for _, n := range neighbours {
+		if p, _ := GetParent(n, ACResolution); p == parentA {
+			cellB = n
+			break
+		}
+	}
+	cellC := otherCellInState(t, cellA)
+	parentB, _ = GetParent(cellC, ACResolution)
+	if cellB == "" || parentB == parentA {
+		t.Fatal("test cells are not laid out as expected")
+	}
+
+	return map[string]Metric{
+		cellA: {Value: 4, Weight: 1, Count: 1},
+		cellB: {Value: 10, Weight: 3, Count: 3},
+		cellC: {Value: 2, Weight: 2, Count: 2},
+	}, parentA, parentB
+}
+
+// otherCellInState returns a resolution 9 cell in another AC-level parent
+// of the same state-level parent as cellID
+func otherCellInState(t *testing.T, cellID string) string {
+	t.Helper()
+	state, _ := GetParent(cellID, StateResolution)
+	ac, _ := GetParent(cellID, ACResolution)
+	acs, _ := GetChildren(state, ACResolution)
+	for _, other := range acs {
+		if other != ac {
+			children, _ := GetChildren(other, 9)
+			return children[0]
+		}
+	}
+	t.Fatal("no other AC-level cell")
+	return ""
+}
+
+func TestRollupCombiners(t *testing.T) {
+	metrics, parentA, parentB := testRollupMetrics(t)
+
+	tests := []struct {
+		name    string
+		combine Combiner
+		want    map[string]Metric
+	}{
+		{"sum", Sum, map[string]Metric{
+			parentA: {Value: 14, Weight: 4, Count: 4},
+			parentB: {Value: 2, Weight: 2, Count: 2},
+		}},
+		{"mean", Mean, map[string]Metric{
+			parentA: {Value: 7, Weight: 4, Count: 4},
+			parentB: {Value: 2, Weight: 2, Count: 2},
+		}},
+		{"weighted mean", WeightedMean, map[string]Metric{
+			parentA: {Value: 8.5, Weight: 4, Count: 4},
+			parentB: {Value: 2, Weight: 2, Count: 2},
+		}},
+		{"min count", MinCount(3, Sum), map[string]Metric{
+			parentA: {Value: 14, Weight: 4, Count: 4},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Rollup(metrics, ACResolution, tt.combine)
+			if err != nil {
+				t.Fatalf("Rollup() error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Rollup() = %v, want %v", got, tt.want)
+			}
+			for cellID, want := range tt.want {
+				if m := got[cellID]; math.Abs(m.Value-want.Value) > 1e-9 || m.Weight != want.Weight || m.Count != want.Count {
+					t.Errorf("Rollup()[%s] = %+v, want %+v", cellID, m, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRollupHierarchy(t *testing.T) {
+	metrics, _, _ := testRollupMetrics(t)
+
+	levels, err := RollupHierarchy(metrics, Mean)
+	if err != nil {
+		t.Fatalf("RollupHierarchy() error: %v", err)
+	}
+	for _, res := range RollupResolutions {
+		if len(levels[res]) == 0 {
+			t.Fatalf("no cells at resolution %d", res)
+		}
+		for cellID := range levels[res] {
+			if got, _ := GetResolution(cellID); got != res {
+				t.Errorf("cell %s at resolution %d, want %d", cellID, got, res)
+			}
+		}
+	}
+
+	// All three cells share a state-level parent, and its mean is over the
+	// source cells rather than the AC-level means
+	state := levels[StateResolution]
+	if len(state) != 1 {
+		t.Fatalf("got %d state-level cells, want 1", len(state))
+	}
+	for _, m := range state {
+		if math.Abs(m.Value-16.0/3) > 1e-9 || m.Count != 6 {
+			t.Errorf("state-level metric = %+v, want mean 16/3 over 6 observations", m)
+		}
+	}
+}
+
+func TestCountCells(t *testing.T) {
+	cellA := LatLngToCellAtResolution(testLat, testLng, 9)
+	cellB := otherCellInState(t, cellA)
+
+	counts := CountCells([]string{cellA, cellB, cellA})
+	if m := counts[cellA]; m.Value != 2 || m.Count != 2 || m.Weight != 2 {
+		t.Errorf("CountCells()[%s] = %+v, want 2 observations", cellA, m)
+	}
+
+	parents, err := Rollup(counts, StateResolution, Sum)
+	if err != nil {
+		t.Fatalf("Rollup() error: %v", err)
+	}
+	if len(parents) != 1 {
+		t.Fatalf("got %d state-level cells, want 1", len(parents))
+	}
+	for _, m := range parents {
+		if m.Count != 3 {
+			t.Errorf("state-level count = %d, want 3", m.Count)
+		}
+	}
+}
+
+func TestRollupErrors(t *testing.T) {
+	metrics, _, _ := testRollupMetrics(t)
+
+	if _, err := Rollup(metrics, 16, Sum); !errors.Is(err, ErrInvalidResolution) {
+		t.Errorf("invalid resolution error = %v, want ErrInvalidResolution", err)
+	}
+	if _, err := Rollup(metrics, 10, Sum); !errors.Is(err, ErrInvalidResolution) {
+		t.Errorf("finer target resolution error = %v, want ErrInvalidResolution", err)
+	}
+	if _, err := Rollup(map[string]Metric{"invalid": {}}, ACResolution, Sum); !errors.Is(err, ErrInvalidCellID) {
+		t.Errorf("invalid cell error = %v, want ErrInvalidCellID", err)
+	}
+	if got, _ := Rollup(map[string]Metric{}, ACResolution, Sum); len(got) != 0 {
+		t.Errorf("Rollup() of no metrics = %v, want none", got)
+	}
+}